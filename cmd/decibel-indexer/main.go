@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	decibelindexer "github.com/cresendoo/decidash-backend/internal/application/decibel-indexer"
@@ -12,7 +15,27 @@ import (
 	"github.com/phsym/console-slog"
 )
 
+const usage = `usage:
+  decibel-indexer [run]
+        index new transactions into every enabled processor
+  decibel-indexer backfill --processor NAME --from VERSION --to VERSION
+        replay versions [from, to] into one processor, leaving its checkpoint untouched
+  decibel-indexer reset --processor NAME --to VERSION
+        rewind a processor's checkpoint so the next run replays from VERSION
+`
+
 func main() {
+	command, args := "run", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	switch command {
+	case "run", "backfill", "reset":
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 	defer cancel()
 
@@ -39,16 +62,69 @@ func main() {
 		return
 	}
 
+	switch command {
+	case "backfill":
+		err = backfill(app, args)
+	case "reset":
+		err = reset(app, args)
+	default:
+		err = run(ctx, app)
+	}
+
+	if closeErr := app.Close(); closeErr != nil {
+		slog.Error("failed to close application", "error", closeErr)
+		if err == nil {
+			err = closeErr
+		}
+	}
+	slog.Info("application closed")
+	if err != nil {
+		slog.Error("command failed", "command", command, "error", err)
+		os.Exit(1)
+	}
+}
+
+// run indexes until a signal arrives or the pipeline stops on its own, in
+// which case its error makes the process exit non-zero.
+func run(ctx context.Context, app *decibelindexer.Application) error {
 	if err := app.Start(); err != nil {
-		slog.Error("failed to start application", "error", err)
-		return
+		return fmt.Errorf("failed to start application: %w", err)
 	}
 	slog.Info("application started")
 
-	<-ctx.Done()
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-app.Done():
+		return app.Err()
+	}
+}
 
-	if err := app.Close(); err != nil {
-		slog.Error("failed to close application", "error", err)
+func backfill(app *decibelindexer.Application, args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	processor := fs.String("processor", "", "processor to backfill, one of "+strings.Join(app.Processors(), ", "))
+	from := fs.Uint64("from", 0, "first version to replay")
+	to := fs.Uint64("to", 0, "last version to replay")
+	if err := fs.Parse(args); err != nil {
+		return err
 	}
-	slog.Info("application closed")
+	if *processor == "" || *to == 0 {
+		fs.Usage()
+		return fmt.Errorf("--processor and --to are required")
+	}
+	return app.Backfill(*processor, *from, *to)
+}
+
+func reset(app *decibelindexer.Application, args []string) error {
+	fs := flag.NewFlagSet("reset", flag.ContinueOnError)
+	processor := fs.String("processor", "", "processor to rewind, one of "+strings.Join(app.Processors(), ", "))
+	to := fs.Uint64("to", 0, "version the next run replays from")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *processor == "" {
+		fs.Usage()
+		return fmt.Errorf("--processor is required")
+	}
+	return app.ResetProcessor(*processor, *to)
 }
@@ -3,36 +3,92 @@ package apiserver
 import (
 	"context"
 	"log/slog"
+	"math/big"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/aptos-labs/aptos-go-sdk"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
 	"github.com/cresendoo/decidash-backend/internal/xaptos"
 	"github.com/cresendoo/decidash-backend/pkg/errorx"
+	"github.com/cresendoo/decidash-backend/pkg/market"
+	"github.com/cresendoo/decidash-backend/pkg/market/binance"
+	"github.com/cresendoo/decidash-backend/pkg/metrics"
 	"github.com/cresendoo/decidash-backend/pkg/xredis"
+	"github.com/cresendoo/decidash-backend/pkg/xtrace"
 	"github.com/mediocregopher/radix/v3"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
 )
 
+// subscribedChannels redis channels the indexer publishes to
+var subscribedChannels = []string{
+	models.PriceTickChannel,
+	models.LiquidationChannel,
+	models.CandleChannel,
+}
+
 type Application struct {
 	ctx context.Context
+	wg  sync.WaitGroup
 
-	pool *radix.Pool
+	pool   *radix.Pool
+	pubSub *xredis.RedisPubSub
+	db     *gorm.DB
 
 	httpServer *http.Server
 	logger     *slog.Logger
 
 	aptos   *aptos.Client
 	sponsor *aptos.Account
+
+	binance  *binance.Client
+	markets  *market.Registry
+	valuator *valuator
+	// converts `size * funding index delta` into collateral units
+	fundingScale *big.Int
+	hub          *wsHub
+	messages     chan radix.PubSubMessage
+
+	shutdownTracing func(context.Context) error
 }
 
 func NewApplication(ctx context.Context, logger *slog.Logger, cfg *Config) (*Application, error) {
 	app := Application{ctx: ctx, logger: logger}
 	var err error
 
+	app.shutdownTracing, err = xtrace.Init(ctx, "api-server", cfg.Trace)
+	if err != nil {
+		return nil, err
+	}
+
 	app.pool, err = xredis.NewRedisPool(cfg.Redis.Addr, cfg.Redis.Pool, cfg.Redis.DB, "")
 	if err != nil {
 		return nil, err
 	}
+	app.pubSub = xredis.NewRedisPubSub("tcp", cfg.Redis.Addr, "", cfg.Redis.DB)
+	app.db, err = gorm.Open(postgres.Open(cfg.DB), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	if err := metrics.InstrumentDB(app.db); err != nil {
+		return nil, err
+	}
+	if err := xtrace.InstrumentDB(app.db); err != nil {
+		return nil, err
+	}
+	app.hub = newWsHub(logger)
+	app.fundingScale = cfg.FundingIndexScale()
+	app.valuator = newValuator(app.db, app.pool, logger, cfg.Binance.Symbols, app.fundingScale)
+	app.binance, err = binance.NewClient(ctx, binance.WithOnOrderbookUpdate(app.valuator.OnOrderbookUpdate))
+	if err != nil {
+		return nil, err
+	}
+	app.markets = market.NewRegistry()
+	if err := app.markets.Register(app.binance, cfg.Binance.Symbols); err != nil {
+		return nil, err
+	}
 	app.sponsor, err = xaptos.AccountFromEd25519PrivateKey(cfg.AptosAccounts.FeePayer)
 	if err != nil {
 		return nil, err
@@ -55,6 +111,38 @@ func NewApplication(ctx context.Context, logger *slog.Logger, cfg *Config) (*App
 }
 
 func (a *Application) Start() error {
+	if err := a.markets.Start(); err != nil {
+		return err
+	}
+
+	a.messages = make(chan radix.PubSubMessage, 1024)
+	if err := a.pubSub.Subscribe(a.messages, subscribedChannels...); err != nil {
+		return err
+	}
+	a.wg.Add(2)
+	go func() {
+		defer a.wg.Done()
+		a.valuator.Run(a.ctx)
+	}()
+	go func() {
+		defer a.wg.Done()
+		for {
+			select {
+			case <-a.ctx.Done():
+				return
+			case msg := <-a.messages:
+				switch msg.Channel {
+				case models.PriceTickChannel:
+					a.valuator.OnPriceTick(string(msg.Message))
+				case models.LiquidationChannel:
+					a.onLiquidation(msg.Message)
+				case models.CandleChannel:
+					a.onCandle(msg.Message)
+				}
+			}
+		}
+	}()
+
 	go func() {
 		slog.Info("listen http server", "port", a.httpServer.Addr)
 		if err := a.httpServer.ListenAndServe(); !errorx.Is(err, http.ErrServerClosed) {
@@ -84,5 +172,20 @@ func (a *Application) Close() error {
 			}
 		}
 	}
+
+	if err := a.pubSub.Unsubscribe(a.messages, subscribedChannels...); err != nil {
+		slog.Warn("failed to unsubscribe redis channels", "error", err)
+	}
+	if err := a.pubSub.Close(); err != nil {
+		slog.Warn("failed to close redis pubsub", "error", err)
+	}
+	if err := a.markets.Stop(); err != nil {
+		return errorx.Wrap(err)
+	}
+	a.hub.Close()
+	a.wg.Wait()
+	if err := a.shutdownTracing(ctx); err != nil {
+		slog.Warn("failed to flush traces", "error", err)
+	}
 	return nil
 }
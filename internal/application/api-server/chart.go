@@ -1 +1,176 @@
 package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/gin-gonic/gin"
+)
+
+// maxCandles 한 번에 조회 가능한 최대 캔들 수
+const maxCandles = 5000
+
+// udfResolutions TradingView UDF 해상도별 캔들 해상도
+var udfResolutions = map[string]string{
+	"1":   "1m",
+	"5":   "5m",
+	"15":  "15m",
+	"60":  "1h",
+	"240": "4h",
+	"D":   "1d",
+	"1D":  "1d",
+}
+
+// candleResolution UDF 해상도(e.g. 60) 또는 캔들 해상도(e.g. 1h) 조회
+func candleResolution(resolution string) (models.CandleResolution, bool) {
+	if name, ok := udfResolutions[resolution]; ok {
+		resolution = name
+	}
+	return models.GetCandleResolution(resolution)
+}
+
+// getMarketCandles 마켓 캔들 조회 (TradingView UDF history 형식)
+func (app *Application) getMarketCandles(c *gin.Context) {
+	var req CandlesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, UDFBars{Status: "error", ErrMsg: err.Error()})
+		return
+	}
+	resolution, ok := candleResolution(req.Resolution)
+	if !ok {
+		c.JSON(http.StatusBadRequest, UDFBars{Status: "error", ErrMsg: "unsupported resolution " + req.Resolution})
+		return
+	}
+
+	market, ok, err := app.findMarket(c, c.Param("market"))
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, UDFBars{Status: "error", ErrMsg: "Market not found"})
+		return
+	}
+
+	filter := models.CandleFilter{
+		Market:     market.Address,
+		Resolution: resolution.Name,
+		To:         time.Now().UTC(),
+		Limit:      maxCandles,
+	}
+	if req.To > 0 {
+		filter.To = time.Unix(req.To, 0).UTC()
+	}
+	switch {
+	case req.Countback > 0:
+		// countback 이 있으면 from 과 무관하게 to 이전 캔들을 countback 개 조회
+		filter.From = time.Unix(0, 0).UTC()
+		filter.Limit = min(req.Countback, maxCandles)
+	case req.From > 0:
+		filter.From = time.Unix(req.From, 0).UTC()
+	default:
+		filter.From = filter.To.Add(-maxCandles * resolution.Duration)
+	}
+
+	candles, err := models.GetCandles(app.db.WithContext(c), filter)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	if len(candles) == 0 {
+		bars := UDFBars{Status: "no_data"}
+		previous, ok, err := models.GetCandleBefore(app.db.WithContext(c), market.Address, resolution.Name, filter.From)
+		if err != nil {
+			ErrorWithCode(c, err, ErrDatabase)
+			return
+		}
+		if ok {
+			next := previous.OpenTime.Unix()
+			bars.NextTime = &next
+		}
+		c.JSON(http.StatusOK, bars)
+		return
+	}
+
+	c.JSON(http.StatusOK, toUDFBars(market, candles))
+}
+
+// toUDFBars 원시 단위의 캔들을 UDF 형식으로 변환
+func toUDFBars(market models.Market, candles []models.Candle) UDFBars {
+	bars := UDFBars{
+		Status: "ok",
+		Time:   make([]int64, len(candles)),
+		Open:   make([]float64, len(candles)),
+		High:   make([]float64, len(candles)),
+		Low:    make([]float64, len(candles)),
+		Close:  make([]float64, len(candles)),
+		Volume: make([]float64, len(candles)),
+	}
+	for i, candle := range candles {
+		bars.Time[i] = candle.OpenTime.Unix()
+		bars.Open[i] = market.Price(candle.Open)
+		bars.High[i] = market.Price(candle.High)
+		bars.Low[i] = market.Price(candle.Low)
+		bars.Close[i] = market.Price(candle.Close)
+		bars.Volume[i] = market.Size(candle.Volume)
+	}
+	return bars
+}
+
+// toCandle 원시 단위의 캔들을 마켓 단위로 변환
+func toCandle(market models.Market, row models.Candle) Candle {
+	return Candle{
+		Market:     row.Market,
+		MarketName: market.Name,
+		Resolution: row.Resolution,
+		Time:       row.OpenTime.Unix(),
+		Open:       market.Price(row.Open),
+		High:       market.Price(row.High),
+		Low:        market.Price(row.Low),
+		Close:      market.Price(row.Close),
+		Volume:     market.Size(row.Volume),
+		Trades:     row.Trades,
+	}
+}
+
+// candleChannel 마켓 캔들 웹소켓 채널, e.g. candles:BTC/USD:1m
+func candleChannel(market, resolution string) string {
+	return wsChannelCandles + market + ":" + resolution
+}
+
+// isCandleChannel 유효한 캔들 채널인지 확인
+func isCandleChannel(channel string) bool {
+	rest, ok := strings.CutPrefix(channel, wsChannelCandles)
+	if !ok {
+		return false
+	}
+	i := strings.LastIndex(rest, ":")
+	if i <= 0 {
+		return false
+	}
+	_, ok = models.GetCandleResolution(rest[i+1:])
+	return ok
+}
+
+// onCandle 인덱서가 발행한 캔들을 마켓 주소와 이름 채널 구독자에게 전달
+func (app *Application) onCandle(message []byte) {
+	var row models.Candle
+	if err := json.Unmarshal(message, &row); err != nil {
+		app.logger.Error("failed to unmarshal candle", "error", err)
+		return
+	}
+	markets, err := app.marketsByAddress(app.ctx)
+	if err != nil {
+		app.logger.Error("failed to load markets", "error", err)
+		return
+	}
+	market := markets[row.Market]
+	candle := toCandle(market, row)
+	app.hub.Broadcast(candleChannel(row.Market, row.Resolution), candle)
+	if market.Name != "" {
+		app.hub.Broadcast(candleChannel(market.Name, row.Resolution), candle)
+	}
+}
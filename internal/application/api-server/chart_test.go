@@ -0,0 +1,61 @@
+package apiserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+)
+
+func TestCandleResolution(t *testing.T) {
+	for input, want := range map[string]string{"1": "1m", "60": "1h", "240": "4h", "D": "1d", "1D": "1d", "15m": "15m"} {
+		resolution, ok := candleResolution(input)
+		if !ok || resolution.Name != want {
+			t.Errorf("candleResolution(%q) = %q/%v, want %q", input, resolution.Name, ok, want)
+		}
+	}
+	if _, ok := candleResolution("3"); ok {
+		t.Error("candleResolution(3) resolved an unsupported resolution")
+	}
+}
+
+func TestToUDFBars(t *testing.T) {
+	market := models.Market{Address: "0xm", Name: "BTC/USD", SizeDecimals: 8, PriceDecimals: 6}
+	open := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	bars := toUDFBars(market, []models.Candle{{
+		Market:     "0xm",
+		Resolution: "1m",
+		OpenTime:   open,
+		Open:       90_000_000_000, // 90000
+		High:       91_000_000_000, // 91000
+		Low:        89_500_000_000, // 89500
+		Close:      90_500_000_000, // 90500
+		Volume:     150_000_000,    // 1.5
+		Trades:     3,
+	}})
+	if bars.Status != "ok" || len(bars.Time) != 1 || bars.Time[0] != open.Unix() {
+		t.Fatalf("bars = %+v, want one ok bar at %d", bars, open.Unix())
+	}
+	if !almostEqual(bars.Open[0], 90000) || !almostEqual(bars.High[0], 91000) ||
+		!almostEqual(bars.Low[0], 89500) || !almostEqual(bars.Close[0], 90500) {
+		t.Errorf("ohlc = %v/%v/%v/%v", bars.Open[0], bars.High[0], bars.Low[0], bars.Close[0])
+	}
+	if !almostEqual(bars.Volume[0], 1.5) {
+		t.Errorf("volume = %v, want 1.5", bars.Volume[0])
+	}
+}
+
+func TestIsWsChannelCandles(t *testing.T) {
+	for channel, want := range map[string]bool{
+		"candles:BTC/USD:1m": true,
+		"candles:0xm:1d":     true,
+		"candles:BTC/USD:2m": false,
+		"candles::1m":        false,
+		"candles:BTC/USD":    false,
+		"liquidations":       true,
+	} {
+		if got := isWsChannel(channel); got != want {
+			t.Errorf("isWsChannel(%q) = %v, want %v", channel, got, want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package apiserver
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultCollateralRange = "7d"
+
+// collateralRange 조회 기간과 포인트 간격, span 이 0 이면 전체 기간
+type collateralRange struct {
+	span     time.Duration
+	interval time.Duration
+	name     string
+}
+
+var collateralRanges = map[string]collateralRange{
+	"7d":  {span: 7 * 24 * time.Hour, interval: time.Hour, name: "1h"},
+	"30d": {span: 30 * 24 * time.Hour, interval: 24 * time.Hour, name: "1d"},
+	"all": {interval: 24 * time.Hour, name: "1d"},
+}
+
+// getTraderCollateral 트레이더 담보 잔고 및 누적 실현 손익 추이 조회
+// 시간별 마크 가격 기록이 없어 포인트는 미실현 손익을 제외한 담보 잔고이며, 미실현 손익은 현재 값만 제공
+func (app *Application) getTraderCollateral(c *gin.Context) {
+	if c.Param("address") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Address parameter is required",
+		})
+		return
+	}
+	address := types.NormalizeAddress(c.Param("address"))
+	if address == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid account address",
+		})
+		return
+	}
+
+	var req TraderCollateralRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.Range == "" {
+		req.Range = defaultCollateralRange
+	}
+	r := collateralRanges[req.Range]
+
+	now := time.Now().UTC()
+	to := now.Truncate(time.Hour).Add(time.Hour)
+	var from time.Time
+	if r.span > 0 {
+		from = now.Add(-r.span).Truncate(r.interval)
+	}
+
+	balances, err := models.GetAccountBalances(app.db.WithContext(c), address, from, to)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	pnl, err := models.GetTraderPnl(app.db.WithContext(c), address, from, to)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	// 누적 손익은 조회 기간 이전 실현분부터 이어서 계산
+	var before int64
+	if !from.IsZero() {
+		if before, err = models.GetTraderPnlBefore(app.db.WithContext(c), address, from); err != nil {
+			ErrorWithCode(c, err, ErrDatabase)
+			return
+		}
+	}
+	valuation, ok, err := getTraderValuation(c, app.pool, address)
+	if err != nil {
+		ErrorWithCode(c, err, ErrInternalServer)
+		return
+	}
+
+	collateral := TraderCollateral{
+		Address:  address,
+		Range:    req.Range,
+		Interval: r.name,
+		Points:   collateralPoints(balances, pnl, before, from, to, r.interval),
+	}
+	if len(collateral.Points) > 0 {
+		collateral.Collateral = collateral.Points[len(collateral.Points)-1].Collateral
+	}
+	if ok {
+		collateral.UnrealizedPnL = valuation.UnrealizedPnL
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": collateral,
+	})
+}
+
+// collateralPoints folds the hourly sheet balances and PnL into one point per
+// interval in [from, to). Balances before from only seed the sheets and
+// before is the net PnL realized before from, which the cumulative PnL
+// starts at. The series starts at the first interval with any activity.
+func collateralPoints(balances []models.AccountBalance, pnl []models.TraderPnl, before int64, from, to time.Time, interval time.Duration) []CollateralPoint {
+	start := to
+	for _, b := range balances {
+		if !b.Hour.Before(from) && b.Hour.Before(start) {
+			start = b.Hour
+		}
+	}
+	for _, p := range pnl {
+		if p.Hour.Before(start) {
+			start = p.Hour
+		}
+	}
+	if len(balances) > 0 && balances[0].Hour.Before(from) {
+		// the sheets were funded before the range
+		start = from
+	}
+	start = start.Truncate(interval)
+
+	unit := math.Pow10(models.CollateralDecimals)
+	sheets := make(map[string]types.Uint64)
+	points := []CollateralPoint{}
+	cumulative := before
+	i, j := 0, 0
+	for t := start; t.Before(to); t = t.Add(interval) {
+		end := t.Add(interval)
+		for ; i < len(balances) && balances[i].Hour.Before(end); i++ {
+			sheets[balances[i].Market] = balances[i].Balance
+		}
+		for ; j < len(pnl) && pnl[j].Hour.Before(end); j++ {
+			cumulative += pnl[j].Net()
+		}
+		var total types.Uint64
+		for _, balance := range sheets {
+			total += balance
+		}
+		points = append(points, CollateralPoint{
+			Time:       t,
+			Collateral: models.Collateral(total),
+			PnL:        float64(cumulative) / unit,
+		})
+	}
+	return points
+}
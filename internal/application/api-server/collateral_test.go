@@ -0,0 +1,60 @@
+package apiserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+)
+
+func TestCollateralPoints(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(4 * time.Hour)
+	balances := []models.AccountBalance{
+		// funded before the range
+		{Market: "", Hour: from.Add(-time.Hour), Balance: 1_000_000_000}, // 1000
+		{Market: "0xm", Hour: from.Add(time.Hour), Balance: 200_000_000}, // 200 moved to an isolated position
+		{Market: "", Hour: from.Add(time.Hour), Balance: 800_000_000},
+		{Market: "0xm", Hour: from.Add(2 * time.Hour), Balance: 250_000_000}, // +50 realized
+	}
+	pnl := []models.TraderPnl{
+		{Hour: from.Add(2 * time.Hour), RealizedPnl: 52_000_000, Fees: 1_500_000, Funding: 500_000},
+	}
+
+	points := collateralPoints(balances, pnl, 0, from, to, time.Hour)
+	if len(points) != 4 || !points[0].Time.Equal(from) {
+		t.Fatalf("points = %+v, want 4 hourly points from %s", points, from)
+	}
+	wantCollateral := []float64{1000, 1000, 1050, 1050}
+	wantPnL := []float64{0, 0, 50, 50}
+	for i, p := range points {
+		if !almostEqual(p.Collateral, wantCollateral[i]) || !almostEqual(p.PnL, wantPnL[i]) {
+			t.Errorf("points[%d] = %v/%v, want %v/%v", i, p.Collateral, p.PnL, wantCollateral[i], wantPnL[i])
+		}
+	}
+
+	// PnL realized before the range carries over
+	seeded := collateralPoints(balances, pnl, 10_000_000, from, to, time.Hour)
+	if !almostEqual(seeded[0].PnL, 10) || !almostEqual(seeded[3].PnL, 60) {
+		t.Errorf("seeded pnl = %v..%v, want 10..60", seeded[0].PnL, seeded[3].PnL)
+	}
+
+	daily := collateralPoints(balances, pnl, 0, from, from.Add(48*time.Hour), 24*time.Hour)
+	if len(daily) != 2 || !almostEqual(daily[0].Collateral, 1050) || !almostEqual(daily[1].PnL, 50) {
+		t.Errorf("daily = %+v, want 2 points closing at 1050 with 50 pnl", daily)
+	}
+}
+
+func TestCollateralPointsStartAtFirstActivity(t *testing.T) {
+	to := time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)
+	first := to.Add(-3 * time.Hour)
+	balances := []models.AccountBalance{{Hour: first, Balance: 5_000_000}}
+
+	points := collateralPoints(balances, nil, 0, to.Add(-7*24*time.Hour), to, time.Hour)
+	if len(points) != 3 || !points[0].Time.Equal(first) || !almostEqual(points[0].Collateral, 5) {
+		t.Errorf("points = %+v, want 3 points from %s", points, first)
+	}
+	if points := collateralPoints(nil, nil, 0, time.Time{}, to, 24*time.Hour); len(points) != 0 {
+		t.Errorf("points without activity = %+v, want none", points)
+	}
+}
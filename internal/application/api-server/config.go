@@ -2,9 +2,12 @@ package apiserver
 
 import (
 	"log/slog"
+	"math/big"
 
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
 	"github.com/cresendoo/decidash-backend/pkg/config"
 	"github.com/cresendoo/decidash-backend/pkg/utils"
+	"github.com/cresendoo/decidash-backend/pkg/xtrace"
 )
 
 type Config struct {
@@ -17,6 +20,8 @@ type Config struct {
 	Port      string `yaml:"port"`
 	SentryDSN string `yaml:"sentry_dsn"`
 
+	Trace xtrace.Config `yaml:"trace"`
+
 	DB    string `yaml:"db"`
 	Redis struct {
 		Addr string `yaml:"addr"`
@@ -27,12 +32,28 @@ type Config struct {
 	AptosAccounts struct {
 		FeePayer string `yaml:"fee_payer"`
 	} `yaml:"aptos_accounts"`
+
+	Funding struct {
+		// decimals of the scale of the perp_positions funding index, 12 if unset
+		IndexDecimals int `yaml:"index_decimals"`
+	} `yaml:"funding"`
+
+	Binance struct {
+		// market name (e.g. BTC/USD) to binance symbol (e.g. btcusdt)
+		Symbols map[string]string `yaml:"symbols"`
+	} `yaml:"binance"`
 }
 
 func (c *Config) Load() error {
 	return config.LoadConfig(c)
 }
 
+// FundingIndexScale returns the scale that converts `size * funding index
+// delta` into collateral units.
+func (c *Config) FundingIndexScale() *big.Int {
+	return models.FundingIndexScale(c.Funding.IndexDecimals)
+}
+
 func (c *Config) FileName() string {
 	if utils.IsProductionPhase() {
 		return "config-api-server-prod.yaml"
@@ -1,13 +1,16 @@
 package apiserver
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/aptos-labs/aptos-go-sdk"
 	"github.com/aptos-labs/aptos-go-sdk/bcs"
 	"github.com/aptos-labs/aptos-go-sdk/crypto"
+	"github.com/cresendoo/decidash-backend/pkg/metrics"
+	"github.com/cresendoo/decidash-backend/pkg/xtrace"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (app *Application) postFeePayer(c *gin.Context) {
@@ -39,11 +42,16 @@ func (app *Application) postFeePayer(c *gin.Context) {
 		return
 	}
 
+	tracer := xtrace.Tracer("fee-payer")
+	_, span := tracer.Start(c, "aptos.BuildTransaction",
+		trace.WithAttributes(attribute.String("aptos.sender", requestTxn.Sender.String())),
+	)
 	rawTxn, err := app.aptos.BuildTransactionMultiAgent(
 		requestTxn.Sender,
 		requestTxn.Payload,
 		aptos.FeePayer(&app.sponsor.Address),
 	)
+	xtrace.End(span, err)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to build transaction",
@@ -74,9 +82,15 @@ func (app *Application) postFeePayer(c *gin.Context) {
 		return
 	}
 
+	_, span = tracer.Start(c, "aptos.SubmitTransaction")
 	submitResult, err := app.aptos.SubmitTransaction(signedFeePayerTxn)
+	if err == nil {
+		span.SetAttributes(attribute.String("aptos.hash", submitResult.Hash))
+	}
+	xtrace.End(span, err)
 	if err != nil {
-		fmt.Println(err)
+		metrics.FeePayerSubmissions.WithLabelValues("submit_failed").Inc()
+		Logger(c).Error("failed to submit transaction", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to submit transaction",
 			"details": err.Error(),
@@ -84,9 +98,14 @@ func (app *Application) postFeePayer(c *gin.Context) {
 		return
 	}
 
+	_, span = tracer.Start(c, "aptos.WaitForTransaction",
+		trace.WithAttributes(attribute.String("aptos.hash", submitResult.Hash)),
+	)
 	userTxn, err := app.aptos.WaitForTransaction(submitResult.Hash)
+	xtrace.End(span, err)
 	if err != nil {
-		fmt.Println(err)
+		metrics.FeePayerSubmissions.WithLabelValues("wait_failed").Inc()
+		Logger(c).Error("failed to wait for transaction", "hash", submitResult.Hash, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to wait for transaction",
 			"details": err.Error(),
@@ -94,5 +113,13 @@ func (app *Application) postFeePayer(c *gin.Context) {
 		return
 	}
 
+	if userTxn.Success {
+		metrics.FeePayerSubmissions.WithLabelValues("success").Inc()
+	} else {
+		metrics.FeePayerSubmissions.WithLabelValues("vm_failure").Inc()
+	}
+	metrics.FeePayerGasUsed.Add(float64(userTxn.GasUsed))
+	metrics.FeePayerGasSpent.Add(float64(userTxn.GasUsed * userTxn.GasUnitPrice))
+
 	c.JSON(http.StatusOK, gin.H{"transaction": userTxn})
 }
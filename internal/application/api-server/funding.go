@@ -0,0 +1,146 @@
+package apiserver
+
+import (
+	"math"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultFundingHistoryRange = 7 * 24 * time.Hour
+
+// getMarketFunding 마켓 시간별 펀딩 히스토리 조회
+func (app *Application) getMarketFunding(c *gin.Context) {
+	var req FundingHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	market, ok, err := app.findMarket(c, c.Param("market"))
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Market not found",
+		})
+		return
+	}
+
+	to := time.Now()
+	if req.To > 0 {
+		to = time.Unix(req.To, 0)
+	}
+	from := to.Add(-defaultFundingHistoryRange)
+	if req.From > 0 {
+		from = time.Unix(req.From, 0)
+	}
+
+	buckets, err := models.GetMarketFundings(app.db.WithContext(c), market.Address, from.UTC(), to.UTC())
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": FundingHistory{
+			Market:     market.Address,
+			MarketName: market.Name,
+			Rates:      fundingRates(market, buckets, app.fundingScale),
+		},
+	})
+}
+
+// getTraderFunding 트레이더 누적 펀딩 지불/수취 조회
+func (app *Application) getTraderFunding(c *gin.Context) {
+	address := types.NormalizeAddress(c.Param("address"))
+	if address == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid account address",
+		})
+		return
+	}
+
+	fundings, err := models.GetTraderFundings(app.db.WithContext(c), address)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	markets, err := app.marketsByAddress(c)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+
+	summary := TraderFundingSummary{
+		Address: address,
+		Markets: make([]TraderMarketFunding, 0, len(fundings)),
+	}
+	for _, f := range fundings {
+		paid := float64(f.Paid) / math.Pow10(models.CollateralDecimals)
+		received := float64(f.Received) / math.Pow10(models.CollateralDecimals)
+		summary.Markets = append(summary.Markets, TraderMarketFunding{
+			Market:     f.Market,
+			MarketName: markets[f.Market].Name,
+			Paid:       paid,
+			Received:   received,
+			Net:        paid - received,
+		})
+		summary.Paid += paid
+		summary.Received += received
+	}
+	summary.Net = summary.Paid - summary.Received
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": summary,
+	})
+}
+
+// fundingRates derives the hourly funding of each bucket from the close
+// index delta against the previous bucket. The first bucket only serves as
+// the reference point.
+func fundingRates(market models.Market, buckets []models.MarketFunding, fundingScale *big.Int) []FundingRate {
+	if len(buckets) < 2 {
+		return []FundingRate{}
+	}
+	unit := new(big.Float).SetFloat64(math.Pow10(market.SizeDecimals))
+	scale := new(big.Float).SetInt(fundingScale)
+	collateral := math.Pow10(models.CollateralDecimals)
+
+	rates := make([]FundingRate, 0, len(buckets)-1)
+	for i := 1; i < len(buckets); i++ {
+		prev, cur := buckets[i-1], buckets[i]
+		hours := cur.Hour.Sub(prev.Hour).Hours()
+		if hours <= 0 {
+			continue
+		}
+
+		delta := new(big.Int).Sub(cur.CloseIndex.BigInt(), prev.CloseIndex.BigInt())
+		perUnit, _ := new(big.Float).Quo(
+			new(big.Float).Mul(new(big.Float).SetInt(delta), unit),
+			scale,
+		).Float64()
+		perUnit = perUnit / collateral / hours
+
+		rate := FundingRate{
+			Time:           cur.Hour,
+			FundingIndex:   cur.CloseIndex.String(),
+			MarkPrice:      market.Price(cur.MarkPx),
+			FundingPerUnit: perUnit,
+		}
+		if rate.MarkPrice > 0 {
+			rate.FundingRate = perUnit / rate.MarkPrice
+		}
+		rates = append(rates, rate)
+	}
+	return rates
+}
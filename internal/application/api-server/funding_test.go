@@ -0,0 +1,57 @@
+package apiserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+)
+
+func newTestFunding(t *testing.T, hour time.Time, index string, markPx uint64) models.MarketFunding {
+	t.Helper()
+	f := models.MarketFunding{Market: "0xm", Hour: hour, MarkPx: types.Uint64(markPx)}
+	if err := f.CloseIndex.Scan(index); err != nil {
+		t.Fatalf("failed to set close index: %v", err)
+	}
+	return f
+}
+
+func TestFundingRates(t *testing.T) {
+	market := models.Market{Address: "0xm", SizeDecimals: 8, PriceDecimals: 6}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 1e12 * 1e6 / 1e8 = 1e10 per 1 USDC on one base unit
+	buckets := []models.MarketFunding{
+		newTestFunding(t, start, "170141183460469231731687303715884105728", 100_000_000),
+		newTestFunding(t, start.Add(time.Hour), "170141183460469231731687303725884105728", 100_000_000),
+		newTestFunding(t, start.Add(3*time.Hour), "170141183460469231731687303705884105728", 0),
+	}
+
+	rates := fundingRates(market, buckets, testFundingScale)
+	if len(rates) != 2 {
+		t.Fatalf("expected 2 rates, got %d", len(rates))
+	}
+
+	if !rates[0].Time.Equal(start.Add(time.Hour)) {
+		t.Errorf("unexpected time: %v", rates[0].Time)
+	}
+	if !almostEqual(rates[0].FundingPerUnit, 1) {
+		t.Errorf("FundingPerUnit = %v, want 1", rates[0].FundingPerUnit)
+	}
+	if !almostEqual(rates[0].FundingRate, 0.01) {
+		t.Errorf("FundingRate = %v, want 0.01", rates[0].FundingRate)
+	}
+
+	// a two hour gap is normalised to an hourly rate, and shorts pay
+	if !almostEqual(rates[1].FundingPerUnit, -1) {
+		t.Errorf("FundingPerUnit = %v, want -1", rates[1].FundingPerUnit)
+	}
+	if rates[1].FundingRate != 0 {
+		t.Errorf("FundingRate without price = %v, want 0", rates[1].FundingRate)
+	}
+
+	if got := fundingRates(market, buckets[:1], testFundingScale); len(got) != 0 {
+		t.Errorf("expected no rates for a single bucket, got %d", len(got))
+	}
+}
@@ -3,12 +3,18 @@ package apiserver
 import (
 	"net/http"
 
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
 	"github.com/gin-gonic/gin"
 )
 
 // getDashboardSummary 대시보드 요약 정보 조회
 func (app *Application) getDashboardSummary(c *gin.Context) {
 	summary := generateMockDashboardSummary()
+	if err := app.marketSummary(c, &summary); err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"data": summary,
@@ -38,9 +44,39 @@ func (app *Application) getTraders(c *gin.Context) {
 	if req.PerPage > 100 {
 		req.PerPage = 100
 	}
+	// 지원하지 않는 정렬 기준은 기본 정렬로 대체
+	if _, ok := models.TraderAnalyticsSortColumns[req.SortBy]; !ok {
+		req.SortBy = defaultTraderSort
+		req.SortDesc = true
+	}
+
+	analytics, total, err := models.ListTraderAnalytics(app.db.WithContext(c), models.TraderAnalyticsFilter{
+		Search:   req.Search,
+		SortBy:   req.SortBy,
+		SortDesc: req.SortDesc,
+		Offset:   (req.Page - 1) * req.PerPage,
+		Limit:    req.PerPage,
+	})
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	markets, err := app.marketsByAddress(c)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
 
-	// Mock 데이터 생성
-	response := getMockTradersWithPagination(req.Page, req.PerPage, req.Search)
+	response := TradersResponse{
+		Traders:    make([]Trader, 0, len(analytics)),
+		Total:      int(total),
+		Page:       req.Page,
+		PerPage:    req.PerPage,
+		TotalPages: (int(total) + req.PerPage - 1) / req.PerPage,
+	}
+	for _, a := range analytics {
+		response.Traders = append(response.Traders, toTrader(a, markets))
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"data": response,
@@ -49,34 +85,66 @@ func (app *Application) getTraders(c *gin.Context) {
 
 // getTraderDetail 특정 트레이더 상세 정보 조회
 func (app *Application) getTraderDetail(c *gin.Context) {
-	address := c.Param("address")
+	address := types.NormalizeAddress(c.Param("address"))
 	if address == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Address parameter is required",
+			"error": "Invalid account address",
 		})
 		return
 	}
 
-	// Mock 데이터에서 해당 주소의 트레이더 찾기
-	allTraders := generateMockTraders(1000)
-	var foundTrader *Trader
+	analytics, ok, err := models.GetTraderAnalytics(app.db.WithContext(c), address)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Trader not found",
+		})
+		return
+	}
+	markets, err := app.marketsByAddress(c)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
 
-	for _, trader := range allTraders {
-		if trader.Address == address {
-			foundTrader = &trader
-			break
-		}
+	c.JSON(http.StatusOK, gin.H{
+		"data": toTrader(analytics, markets),
+	})
+}
+
+// getTraderPositions 트레이더 포지션별 미실현 손익 조회
+func (app *Application) getTraderPositions(c *gin.Context) {
+	address := types.NormalizeAddress(c.Param("address"))
+	if address == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid account address",
+		})
+		return
 	}
 
-	if foundTrader == nil {
+	valuation, ok, err := getTraderValuation(c, app.pool, address)
+	if err != nil {
+		ErrorWithCode(c, err, ErrInternalServer)
+		return
+	}
+	if !ok {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Trader not found",
 		})
 		return
 	}
+	triggers, err := app.traderTriggers(c, address)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	attachTriggers(&valuation, triggers)
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": foundTrader,
+		"data": valuation,
 	})
 }
 
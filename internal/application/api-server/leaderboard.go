@@ -0,0 +1,120 @@
+package apiserver
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLeaderboardWindow = "7d"
+	defaultLeaderboardMetric = "pnl"
+	// leaderboardComparison 순위 변동 비교 기간
+	leaderboardComparison = 24 * time.Hour
+)
+
+// getLeaderboard 리더보드 스냅샷과 하루 전 대비 순위 변동 조회
+func (app *Application) getLeaderboard(c *gin.Context) {
+	var req LeaderboardRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.Window == "" {
+		req.Window = defaultLeaderboardWindow
+	}
+	if req.By == "" {
+		req.By = defaultLeaderboardMetric
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PerPage <= 0 {
+		req.PerPage = 50
+	}
+	at := time.Now().UTC()
+	if req.At > 0 {
+		at = time.Unix(req.At, 0).UTC()
+	}
+
+	db := app.db.WithContext(c)
+	snapshot, ok, err := models.GetLeaderboardTime(db, req.Window, req.By, at)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Leaderboard not found",
+		})
+		return
+	}
+	total, err := models.CountLeaderboard(db, req.Window, req.By, snapshot)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	entries, err := models.GetLeaderboard(db, req.Window, req.By, snapshot, (req.Page-1)*req.PerPage, req.PerPage)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+
+	leaderboard := Leaderboard{
+		Window:  req.Window,
+		By:      req.By,
+		At:      snapshot,
+		Total:   int(total),
+		Page:    req.Page,
+		PerPage: req.PerPage,
+	}
+	var previous map[string]int
+	compared, ok, err := models.GetLeaderboardTime(db, req.Window, req.By, snapshot.Add(-leaderboardComparison))
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	if ok && len(entries) > 0 {
+		accounts := make([]string, len(entries))
+		for i, e := range entries {
+			accounts[i] = e.Account
+		}
+		if previous, err = models.GetLeaderboardRanks(db, req.Window, req.By, compared, accounts); err != nil {
+			ErrorWithCode(c, err, ErrDatabase)
+			return
+		}
+		leaderboard.ComparedAt = &compared
+	}
+	leaderboard.Ranks = leaderboardRanks(entries, previous)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": leaderboard,
+	})
+}
+
+// leaderboardRanks 스냅샷 순위에 이전 순위 대비 변동 추가
+func leaderboardRanks(entries []models.LeaderboardEntry, previous map[string]int) []LeaderboardRank {
+	ranks := make([]LeaderboardRank, 0, len(entries))
+	for _, e := range entries {
+		rank := LeaderboardRank{
+			Rank:    e.Rank,
+			Address: e.Account,
+			Value:   e.Value,
+			PnL:     e.Pnl,
+			ROI:     e.Roi,
+			Equity:  e.Equity,
+		}
+		if prev, ok := previous[e.Account]; ok {
+			change := prev - e.Rank
+			rank.PreviousRank = &prev
+			rank.RankChange = &change
+		}
+		ranks = append(ranks, rank)
+	}
+	return ranks
+}
@@ -0,0 +1,32 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+)
+
+func TestLeaderboardRanks(t *testing.T) {
+	entries := []models.LeaderboardEntry{
+		{Account: "0xa", Rank: 1, Value: 500, Pnl: 500},
+		{Account: "0xb", Rank: 2, Value: 300, Pnl: 300},
+		{Account: "0xc", Rank: 3, Value: 100, Pnl: 100},
+	}
+	ranks := leaderboardRanks(entries, map[string]int{"0xa": 13, "0xb": 1})
+
+	if len(ranks) != 3 || ranks[0].Address != "0xa" || ranks[0].PnL != 500 {
+		t.Fatalf("ranks = %+v", ranks)
+	}
+	if ranks[0].RankChange == nil || *ranks[0].RankChange != 12 || *ranks[0].PreviousRank != 13 {
+		t.Errorf("0xa change = %v, want up 12 from 13", ranks[0].RankChange)
+	}
+	if ranks[1].RankChange == nil || *ranks[1].RankChange != -1 {
+		t.Errorf("0xb change = %v, want down 1", ranks[1].RankChange)
+	}
+	if ranks[2].RankChange != nil || ranks[2].PreviousRank != nil {
+		t.Errorf("0xc was not ranked before, got change %v", ranks[2].RankChange)
+	}
+	if ranks := leaderboardRanks(entries, nil); ranks[0].RankChange != nil {
+		t.Errorf("change without a previous snapshot = %v", ranks[0].RankChange)
+	}
+}
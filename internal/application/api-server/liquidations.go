@@ -0,0 +1,126 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"github.com/gin-gonic/gin"
+)
+
+// getLiquidations 청산 피드 조회
+func (app *Application) getLiquidations(c *gin.Context) {
+	var req LiquidationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PerPage <= 0 {
+		req.PerPage = 50
+	}
+
+	account := types.NormalizeAddress(req.Account)
+	if req.Account != "" && account == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid account address",
+		})
+		return
+	}
+
+	filter := models.LiquidationFilter{
+		Account: account,
+		Kind:    req.Kind,
+		Offset:  (req.Page - 1) * req.PerPage,
+		Limit:   req.PerPage,
+	}
+	if req.Market != "" {
+		market, ok, err := app.findMarket(c, req.Market)
+		if err != nil {
+			ErrorWithCode(c, err, ErrDatabase)
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Market not found",
+			})
+			return
+		}
+		filter.Market = market.Address
+	}
+	if req.From > 0 {
+		filter.From = time.Unix(req.From, 0).UTC()
+	}
+	if req.To > 0 {
+		filter.To = time.Unix(req.To, 0).UTC()
+	}
+
+	rows, total, err := models.GetLiquidations(app.db.WithContext(c), filter)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	markets, err := app.marketsByAddress(c)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+
+	liquidations := make([]Liquidation, 0, len(rows))
+	for _, row := range rows {
+		liquidations = append(liquidations, toLiquidation(markets[row.Market], row))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": LiquidationsResponse{
+			Liquidations: liquidations,
+			Total:        int(total),
+			Page:         req.Page,
+			PerPage:      req.PerPage,
+			TotalPages:   (int(total) + req.PerPage - 1) / req.PerPage,
+		},
+	})
+}
+
+// onLiquidation 인덱서가 발행한 청산을 웹소켓 구독자에게 전달
+func (app *Application) onLiquidation(message []byte) {
+	var row models.Liquidation
+	if err := json.Unmarshal(message, &row); err != nil {
+		app.logger.Error("failed to unmarshal liquidation", "error", err)
+		return
+	}
+	markets, err := app.marketsByAddress(app.ctx)
+	if err != nil {
+		app.logger.Error("failed to load markets", "error", err)
+		return
+	}
+	app.hub.Broadcast(wsChannelLiquidations, toLiquidation(markets[row.Market], row))
+}
+
+// toLiquidation 원시 단위의 청산을 마켓 단위로 변환
+func toLiquidation(market models.Market, row models.Liquidation) Liquidation {
+	l := Liquidation{
+		Version:     row.Version,
+		EventIndex:  row.EventIndex,
+		Timestamp:   row.VersionTimestamp,
+		Account:     row.Account,
+		Market:      row.Market,
+		MarketName:  market.Name,
+		Kind:        row.Kind,
+		IsLong:      row.IsLong,
+		Size:        market.Size(row.Size),
+		Price:       market.Price(row.Price),
+		Loss:        models.Collateral(row.Loss),
+		CoveredLoss: models.Collateral(row.CoveredLoss),
+		Margin:      models.Collateral(row.Margin),
+		Fee:         models.Collateral(row.Fee),
+	}
+	l.Notional = l.Size * l.Price
+	return l
+}
@@ -0,0 +1,44 @@
+package apiserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+)
+
+func TestToLiquidation(t *testing.T) {
+	market := models.Market{Address: "0xm", Name: "BTC/USD", SizeDecimals: 8, PriceDecimals: 6}
+	row := models.Liquidation{
+		Version:          10,
+		EventIndex:       2,
+		VersionTimestamp: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Account:          "0xa",
+		Market:           "0xm",
+		Kind:             models.LiquidationKindBackstop,
+		IsLong:           true,
+		Size:             50_000_000,     // 0.5
+		Price:            90_000_000_000, // 90000
+		Loss:             1_500_000_000,  // 1500
+		CoveredLoss:      500_000_000,    // 500
+		Margin:           4_000_000_000,  // 4000
+		Fee:              2_000_000,      // 2
+	}
+
+	l := toLiquidation(market, row)
+	if l.MarketName != "BTC/USD" || l.Kind != models.LiquidationKindBackstop {
+		t.Errorf("unexpected market/kind: %s/%s", l.MarketName, l.Kind)
+	}
+	if !almostEqual(l.Size, 0.5) || !almostEqual(l.Price, 90000) {
+		t.Errorf("size/price = %v/%v, want 0.5/90000", l.Size, l.Price)
+	}
+	if !almostEqual(l.Notional, 45000) {
+		t.Errorf("Notional = %v, want 45000", l.Notional)
+	}
+	if !almostEqual(l.Loss, 1500) || !almostEqual(l.Fee, 2) {
+		t.Errorf("loss/fee = %v/%v, want 1500/2", l.Loss, l.Fee)
+	}
+	if !almostEqual(l.CoveredLoss, 500) || !almostEqual(l.Margin, 4000) {
+		t.Errorf("covered loss/margin = %v/%v, want 500/4000", l.CoveredLoss, l.Margin)
+	}
+}
@@ -0,0 +1,76 @@
+package apiserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// findMarket 주소 또는 이름으로 마켓 조회
+func (app *Application) findMarket(ctx context.Context, market string) (models.Market, bool, error) {
+	var m models.Market
+	if err := app.db.WithContext(ctx).Where("address = ? OR name = ?", market, market).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Market{}, false, nil
+		}
+		return models.Market{}, false, err
+	}
+	return m, true, nil
+}
+
+// marketsByAddress 주소별 마켓 목록 조회
+func (app *Application) marketsByAddress(ctx context.Context) (map[string]models.Market, error) {
+	markets, err := models.GetMarkets(app.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	byAddress := make(map[string]models.Market, len(markets))
+	for _, m := range markets {
+		byAddress[m.Address] = m
+	}
+	return byAddress, nil
+}
+
+// getMarketVenues 마켓의 Decibel 가격과 외부 거래소 가격 비교
+func (app *Application) getMarketVenues(c *gin.Context) {
+	market, ok, err := app.findMarket(c, c.Param("market"))
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Market not found",
+		})
+		return
+	}
+
+	result := MarketVenues{
+		Market:     market.Address,
+		MarketName: market.Name,
+		Venues:     make([]VenueQuote, 0),
+	}
+	var price models.MarketPrice
+	err = app.db.WithContext(c).Where("market = ?", market.Address).First(&price).Error
+	switch {
+	case err == nil:
+		result.MarkPrice = market.Price(price.MarkPx)
+		result.OraclePrice = market.Price(price.OraclePx)
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	for _, quote := range app.markets.Quotes(market.Name) {
+		venue := VenueQuote{Quote: quote}
+		if quote.Mark > 0 && result.MarkPrice > 0 {
+			venue.Premium = (result.MarkPrice - quote.Mark) / quote.Mark * 100
+		}
+		result.Venues = append(result.Venues, venue)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
@@ -127,55 +127,3 @@ func generateAvatarURL(index int) string {
 	// 실제로는 랜덤한 아바타 서비스 URL을 사용할 수 있음
 	return "https://api.dicebear.com/7.x/avataaars/svg?seed=" + string(rune(65+index%26))
 }
-
-// getMockTradersWithPagination 페이지네이션을 적용한 트레이더 목록 반환
-func getMockTradersWithPagination(page, perPage int, search string) TradersResponse {
-	// 전체 트레이더 생성 (1000명)
-	allTraders := generateMockTraders(1000)
-
-	// 검색 필터 적용
-	var filteredTraders []Trader
-	if search != "" {
-		for _, trader := range allTraders {
-			if contains(trader.Address, search) {
-				filteredTraders = append(filteredTraders, trader)
-			}
-		}
-	} else {
-		filteredTraders = allTraders
-	}
-
-	total := len(filteredTraders)
-	totalPages := (total + perPage - 1) / perPage
-
-	// 페이지네이션 적용
-	start := (page - 1) * perPage
-	end := start + perPage
-
-	if start >= total {
-		return TradersResponse{
-			Traders:    []Trader{},
-			Total:      total,
-			Page:       page,
-			PerPage:    perPage,
-			TotalPages: totalPages,
-		}
-	}
-
-	if end > total {
-		end = total
-	}
-
-	return TradersResponse{
-		Traders:    filteredTraders[start:end],
-		Total:      total,
-		Page:       page,
-		PerPage:    perPage,
-		TotalPages: totalPages,
-	}
-}
-
-// contains 문자열 포함 검사
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && s[:len(substr)] == substr
-}
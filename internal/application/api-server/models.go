@@ -1,5 +1,11 @@
 package apiserver
 
+import (
+	"time"
+
+	"github.com/cresendoo/decidash-backend/pkg/market"
+)
+
 // MarketSentiment 시장 심리 데이터
 type MarketSentiment struct {
 	LongPercentage  float64 `json:"long_percentage"`
@@ -45,6 +51,24 @@ type Trader struct {
 	WeeklyPnL     PnLData       `json:"weekly_pnl"`
 	MonthlyPnL    PnLData       `json:"monthly_pnl"`
 	AllTimePnL    PnLData       `json:"all_time_pnl"`
+
+	Performance *TraderPerformance `json:"performance,omitempty"`
+}
+
+// TraderPerformance 트레이더 성과 지표
+type TraderPerformance struct {
+	Trades          int64     `json:"trades"`
+	ClosedPositions int64     `json:"closed_positions"`
+	WinRate         float64   `json:"win_rate"` // 수익으로 종료된 포지션 비율 (%)
+	AvgWin          float64   `json:"avg_win"`
+	AvgLoss         float64   `json:"avg_loss"`
+	ProfitFactor    float64   `json:"profit_factor"` // 총 수익 / 총 손실, 손실이 없으면 0
+	MaxDrawdown     float64   `json:"max_drawdown"`  // 일별 수익률 기준 최대 낙폭 (%)
+	Sharpe          float64   `json:"sharpe"`        // 연환산
+	Sortino         float64   `json:"sortino"`       // 연환산
+	AvgLeverage     float64   `json:"avg_leverage"`
+	AvgHoldingTime  float64   `json:"avg_holding_time"` // seconds
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
 // MainPosition 주요 포지션
@@ -88,11 +112,302 @@ type TradersRequest struct {
 	Page     int    `form:"page" binding:"omitempty,min=1"`
 	PerPage  int    `form:"per_page" binding:"omitempty,min=1,max=100"`
 	Search   string `form:"search"`
-	SortBy   string `form:"sort_by"`
+	SortBy   string `form:"sort_by"` // e.g. all_time_pnl, win_rate, sharpe, max_drawdown
 	SortDesc bool   `form:"sort_desc"`
 }
 
+// LeaderboardRequest 리더보드 조회 요청
+type LeaderboardRequest struct {
+	Window  string `form:"window" binding:"omitempty,oneof=1d 7d 30d all"` // 기본값 7d
+	By      string `form:"by" binding:"omitempty,oneof=pnl roi"`           // 기본값 pnl
+	At      int64  `form:"at" binding:"omitempty,min=0"`                   // unix seconds, 이 시각 이전 최신 스냅샷
+	Page    int    `form:"page" binding:"omitempty,min=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+}
+
+// LeaderboardRank 리더보드 순위
+type LeaderboardRank struct {
+	Rank         int     `json:"rank"`
+	Address      string  `json:"address"`
+	Value        float64 `json:"value"` // 순위 기준 값 (pnl 또는 roi)
+	PnL          float64 `json:"pnl"`
+	ROI          float64 `json:"roi"` // %
+	Equity       float64 `json:"equity"`
+	PreviousRank *int    `json:"previous_rank"` // 하루 전 순위, 순위권 밖이었으면 null
+	RankChange   *int    `json:"rank_change"`   // 양수면 상승
+}
+
+// Leaderboard 리더보드 스냅샷
+type Leaderboard struct {
+	Window     string            `json:"window"`
+	By         string            `json:"by"`
+	At         time.Time         `json:"at"`
+	ComparedAt *time.Time        `json:"compared_at"` // 순위 변동 비교 스냅샷 시각
+	Total      int               `json:"total"`
+	Page       int               `json:"page"`
+	PerPage    int               `json:"per_page"`
+	Ranks      []LeaderboardRank `json:"ranks"`
+}
+
 type FeePayerRequest struct {
 	Signature   []byte `json:"signature"`
 	Transaction []byte `json:"transaction"`
 }
+
+// PositionValuation 포지션 평가 정보
+type PositionValuation struct {
+	PositionAddress string  `json:"position_address"`
+	Market          string  `json:"market"`
+	MarketName      string  `json:"market_name"`
+	IsCrossed       bool    `json:"is_crossed"`
+	IsLong          bool    `json:"is_long"`
+	Size            float64 `json:"size"`
+	EntryPrice      float64 `json:"entry_price"`
+	MarkPrice       float64 `json:"mark_price"`
+	PriceSource     string  `json:"price_source"` // "decibel" or "binance"
+	Notional        float64 `json:"notional"`
+	Margin          float64 `json:"margin"`
+	UnrealizedPnL   float64 `json:"unrealized_pnl"`
+	PendingFunding  float64 `json:"pending_funding"` // 양수면 지불할 펀딩
+	ROI             float64 `json:"roi"`             // 마진 대비 미실현 손익 (%)
+
+	Triggers []PositionTrigger `json:"triggers,omitempty"`
+}
+
+// TraderValuation 트레이더 평가 정보
+type TraderValuation struct {
+	Address        string              `json:"address"`
+	Notional       float64             `json:"notional"`
+	Margin         float64             `json:"margin"`
+	UnrealizedPnL  float64             `json:"unrealized_pnl"`
+	PendingFunding float64             `json:"pending_funding"`
+	ROI            float64             `json:"roi"`
+	Positions      []PositionValuation `json:"positions"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+// FundingRate 시간별 펀딩 비율
+type FundingRate struct {
+	Time           time.Time `json:"time"`
+	FundingIndex   string    `json:"funding_index"`
+	MarkPrice      float64   `json:"mark_price"`
+	FundingPerUnit float64   `json:"funding_per_unit"` // 기초자산 1개당 펀딩 (USDC, 양수면 롱이 지불)
+	FundingRate    float64   `json:"funding_rate"`     // 시간당 펀딩 비율
+}
+
+// FundingHistory 마켓 펀딩 히스토리
+type FundingHistory struct {
+	Market     string        `json:"market"`
+	MarketName string        `json:"market_name"`
+	Rates      []FundingRate `json:"rates"`
+}
+
+// MarketVenues 거래소별 마켓 가격 비교
+type MarketVenues struct {
+	Market      string       `json:"market"`
+	MarketName  string       `json:"market_name"`
+	MarkPrice   float64      `json:"mark_price"`
+	OraclePrice float64      `json:"oracle_price"`
+	Venues      []VenueQuote `json:"venues"`
+}
+
+// VenueQuote 외부 거래소 가격
+type VenueQuote struct {
+	market.Quote
+	Premium float64 `json:"premium"` // 외부 마크 가격 대비 Decibel 마크 가격 프리미엄 (%)
+}
+
+// CandlesRequest 마켓 캔들 조회 요청 (TradingView UDF history 파라미터)
+type CandlesRequest struct {
+	Resolution string `form:"resolution" binding:"required"`       // e.g. 1, 60, 1D 또는 1m, 1h, 1d
+	From       int64  `form:"from" binding:"omitempty,min=0"`      // unix seconds
+	To         int64  `form:"to" binding:"omitempty,min=0"`        // unix seconds
+	Countback  int    `form:"countback" binding:"omitempty,min=0"` // to 이전 캔들 수, from 보다 우선
+}
+
+// UDFBars TradingView UDF history 응답
+type UDFBars struct {
+	Status   string    `json:"s"` // ok, no_data 또는 error
+	ErrMsg   string    `json:"errmsg,omitempty"`
+	NextTime *int64    `json:"nextTime,omitempty"` // no_data 일 때 이전 캔들 시각
+	Time     []int64   `json:"t,omitempty"`
+	Open     []float64 `json:"o,omitempty"`
+	High     []float64 `json:"h,omitempty"`
+	Low      []float64 `json:"l,omitempty"`
+	Close    []float64 `json:"c,omitempty"`
+	Volume   []float64 `json:"v,omitempty"`
+}
+
+// Candle 실시간 캔들
+type Candle struct {
+	Market     string  `json:"market"`
+	MarketName string  `json:"market_name"`
+	Resolution string  `json:"resolution"`
+	Time       int64   `json:"time"` // 캔들 시작 시각 (unix seconds)
+	Open       float64 `json:"open"`
+	High       float64 `json:"high"`
+	Low        float64 `json:"low"`
+	Close      float64 `json:"close"`
+	Volume     float64 `json:"volume"`
+	Trades     int64   `json:"trades"`
+}
+
+// OpenInterestRequest 마켓 미결제약정 추이 요청
+type OpenInterestRequest struct {
+	From     int64  `form:"from" binding:"omitempty,min=0"`                        // unix seconds, 기본값 7일 전
+	To       int64  `form:"to" binding:"omitempty,min=0"`                          // unix seconds
+	Interval string `form:"interval" binding:"omitempty,oneof=1m 5m 15m 1h 4h 1d"` // 기본값 1h
+}
+
+// OpenInterestPoint 미결제약정 포인트
+type OpenInterestPoint struct {
+	Time           time.Time `json:"time"`
+	LongSize       float64   `json:"long_size"`
+	ShortSize      float64   `json:"short_size"`
+	LongNotional   float64   `json:"long_notional"`
+	ShortNotional  float64   `json:"short_notional"`
+	OpenInterest   float64   `json:"open_interest"` // 롱 명목가치, 숏과 같음
+	LongTraders    int64     `json:"long_traders"`
+	ShortTraders   int64     `json:"short_traders"`
+	LongShortRatio float64   `json:"long_short_ratio"` // 롱 트레이더 수 / 숏 트레이더 수, 숏이 없으면 0
+	MarkPrice      float64   `json:"mark_price"`
+}
+
+// OpenInterestHistory 마켓 미결제약정 추이
+type OpenInterestHistory struct {
+	Market     string              `json:"market"`
+	MarketName string              `json:"market_name"`
+	Interval   string              `json:"interval"`
+	Points     []OpenInterestPoint `json:"points"`
+}
+
+// FundingHistoryRequest 마켓 펀딩 히스토리 요청
+type FundingHistoryRequest struct {
+	From int64 `form:"from" binding:"omitempty,min=0"` // unix seconds
+	To   int64 `form:"to" binding:"omitempty,min=0"`   // unix seconds
+}
+
+// TraderMarketFunding 마켓별 트레이더 누적 펀딩
+type TraderMarketFunding struct {
+	Market     string  `json:"market"`
+	MarketName string  `json:"market_name"`
+	Paid       float64 `json:"paid"`
+	Received   float64 `json:"received"`
+	Net        float64 `json:"net"` // 양수면 순지불
+}
+
+// TraderFundingSummary 트레이더 누적 펀딩 요약
+type TraderFundingSummary struct {
+	Address  string                `json:"address"`
+	Paid     float64               `json:"paid"`
+	Received float64               `json:"received"`
+	Net      float64               `json:"net"`
+	Markets  []TraderMarketFunding `json:"markets"`
+}
+
+// TraderCollateralRequest 트레이더 담보 잔고 추이 요청
+type TraderCollateralRequest struct {
+	Range string `form:"range" binding:"omitempty,oneof=7d 30d all"` // 기본값 7d
+}
+
+// CollateralPoint 담보 잔고 추이 포인트, 미실현 손익 제외
+type CollateralPoint struct {
+	Time       time.Time `json:"time"`       // 구간 시작 시각
+	Collateral float64   `json:"collateral"` // 구간 종료 시점 담보 잔고 (USDC)
+	PnL        float64   `json:"pnl"`        // 전체 기간 누적 실현 손익 (수수료, 펀딩 차감)
+}
+
+// TraderCollateral 트레이더 담보 잔고 및 누적 실현 손익 추이
+// 자산(equity)은 현재 시점만 collateral + unrealized_pnl 로 계산 가능
+type TraderCollateral struct {
+	Address       string            `json:"address"`
+	Range         string            `json:"range"`
+	Interval      string            `json:"interval"`       // 1h 또는 1d
+	Collateral    float64           `json:"collateral"`     // 현재 담보 잔고, 마지막 포인트와 동일
+	UnrealizedPnL float64           `json:"unrealized_pnl"` // 현재 미실현 손익
+	Points        []CollateralPoint `json:"points"`
+}
+
+// Liquidation 청산 이벤트
+type Liquidation struct {
+	Version     uint64    `json:"version"`
+	EventIndex  int       `json:"event_index"`
+	Timestamp   time.Time `json:"timestamp"`
+	Account     string    `json:"account"`
+	Market      string    `json:"market"`
+	MarketName  string    `json:"market_name"`
+	Kind        string    `json:"kind"` // market | backstop
+	IsLong      bool      `json:"is_long"`
+	Size        float64   `json:"size"`
+	Price       float64   `json:"price"`
+	Notional    float64   `json:"notional"`
+	Loss        float64   `json:"loss"`         // 청산 거래의 실현 손실
+	CoveredLoss float64   `json:"covered_loss"` // 백스탑 청산인이 보전한 손실
+	Margin      float64   `json:"margin"`       // 청산 시점의 계정 청산 증거금
+	Fee         float64   `json:"fee"`
+}
+
+// LiquidationsRequest 청산 목록 요청
+type LiquidationsRequest struct {
+	Page    int    `form:"page" binding:"omitempty,min=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+	Market  string `form:"market"`
+	Account string `form:"account"`
+	Kind    string `form:"kind" binding:"omitempty,oneof=market backstop"`
+	From    int64  `form:"from" binding:"omitempty,min=0"` // unix seconds
+	To      int64  `form:"to" binding:"omitempty,min=0"`   // unix seconds
+}
+
+// LiquidationsResponse 청산 목록 응답
+type LiquidationsResponse struct {
+	Liquidations []Liquidation `json:"liquidations"`
+	Total        int           `json:"total"`
+	Page         int           `json:"page"`
+	PerPage      int           `json:"per_page"`
+	TotalPages   int           `json:"total_pages"`
+}
+
+// RevenueRequest 수수료 수익 요청
+type RevenueRequest struct {
+	GroupBy string `form:"group_by" binding:"omitempty,oneof=day market builder"`
+	Market  string `form:"market"`
+	Builder string `form:"builder"`
+	From    int64  `form:"from" binding:"omitempty,min=0"` // unix seconds
+	To      int64  `form:"to" binding:"omitempty,min=0"`   // unix seconds
+}
+
+// RevenueBucket 그룹별 수수료 수익
+type RevenueBucket struct {
+	Day          *time.Time `json:"day,omitempty"`
+	Market       string     `json:"market,omitempty"`
+	MarketName   string     `json:"market_name,omitempty"`
+	Builder      *string    `json:"builder,omitempty"`
+	TraderFees   float64    `json:"trader_fees"`
+	TreasuryFees float64    `json:"treasury_fees"`
+	BackstopFees float64    `json:"backstop_fees"`
+	BuilderFees  float64    `json:"builder_fees"`
+	Trades       int64      `json:"trades"`
+}
+
+// RevenueResponse 수수료 수익 응답
+type RevenueResponse struct {
+	GroupBy string          `json:"group_by"`
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	Total   RevenueBucket   `json:"total"`
+	Buckets []RevenueBucket `json:"buckets"`
+}
+
+// PositionTrigger 포지션의 TP/SL 및 reduce-only 주문
+type PositionTrigger struct {
+	PositionAddress string   `json:"position_address"`
+	Market          string   `json:"market"`
+	MarketName      string   `json:"market_name"`
+	IsCrossed       bool     `json:"is_crossed"`
+	OrderID         string   `json:"order_id"`
+	Kind            string   `json:"kind"` // tp | sl | reduce_only
+	IsFullSize      bool     `json:"is_full_size"`
+	TriggerPrice    float64  `json:"trigger_price,omitempty"`
+	LimitPrice      *float64 `json:"limit_price"` // null 이면 시장가
+	Size            *float64 `json:"size"`        // null 이면 전체 포지션 또는 알 수 없음
+}
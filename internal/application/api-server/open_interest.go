@@ -0,0 +1,135 @@
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultOpenInterestRange    = 7 * 24 * time.Hour
+	defaultOpenInterestInterval = "1h"
+)
+
+// getMarketOpenInterest 마켓 미결제약정 및 롱/숏 트레이더 비율 추이 조회
+func (app *Application) getMarketOpenInterest(c *gin.Context) {
+	var req OpenInterestRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.Interval == "" {
+		req.Interval = defaultOpenInterestInterval
+	}
+	interval, _ := models.GetCandleResolution(req.Interval)
+
+	market, ok, err := app.findMarket(c, c.Param("market"))
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Market not found",
+		})
+		return
+	}
+
+	to := time.Now()
+	if req.To > 0 {
+		to = time.Unix(req.To, 0)
+	}
+	from := to.Add(-defaultOpenInterestRange)
+	if req.From > 0 {
+		from = time.Unix(req.From, 0)
+	}
+
+	rows, err := models.GetOpenInterest(app.db.WithContext(c), market.Address, from.UTC(), to.UTC(), interval.Duration)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	history := OpenInterestHistory{
+		Market:     market.Address,
+		MarketName: market.Name,
+		Interval:   req.Interval,
+		Points:     make([]OpenInterestPoint, 0, len(rows)),
+	}
+	for _, row := range rows {
+		history.Points = append(history.Points, toOpenInterestPoint(market, row))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": history,
+	})
+}
+
+// toOpenInterestPoint 원시 단위의 미결제약정을 마켓 단위로 변환
+func toOpenInterestPoint(market models.Market, row models.OpenInterest) OpenInterestPoint {
+	price := market.Price(row.MarkPx)
+	p := OpenInterestPoint{
+		Time:         row.Time,
+		LongSize:     market.Size(row.LongSize),
+		ShortSize:    market.Size(row.ShortSize),
+		LongTraders:  row.LongTraders,
+		ShortTraders: row.ShortTraders,
+		MarkPrice:    price,
+	}
+	p.LongNotional = p.LongSize * price
+	p.ShortNotional = p.ShortSize * price
+	p.OpenInterest = p.LongNotional
+	if row.ShortTraders > 0 {
+		p.LongShortRatio = float64(row.LongTraders) / float64(row.ShortTraders)
+	}
+	return p
+}
+
+// marketSummary 마켓별 최신 미결제약정으로 시장 심리와 자산 집중도 계산
+func (app *Application) marketSummary(ctx context.Context, summary *DashboardSummary) error {
+	rows, err := models.GetLatestOpenInterest(app.db.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	markets, err := app.marketsByAddress(ctx)
+	if err != nil {
+		return err
+	}
+	sentiment, concentration := summarizeOpenInterest(rows, markets)
+	summary.MarketSentiment = sentiment
+	summary.AssetConcentration = concentration
+	return nil
+}
+
+// summarizeOpenInterest 전체 마켓의 롱/숏 트레이더 비율과 미결제약정이 가장 큰 자산, 트레이더가 가장 많은 자산
+func summarizeOpenInterest(rows []models.OpenInterest, markets map[string]models.Market) (MarketSentiment, AssetConcentration) {
+	var sentiment MarketSentiment
+	var concentration AssetConcentration
+	var long, short int64
+	for _, row := range rows {
+		market := markets[row.Market]
+		p := toOpenInterestPoint(market, row)
+		long += row.LongTraders
+		short += row.ShortTraders
+		concentration.TotalMonitored += p.OpenInterest
+
+		if p.OpenInterest > concentration.HighestOI.Amount {
+			concentration.HighestOI.Asset = marketAsset(market)
+			concentration.HighestOI.Amount = p.OpenInterest
+		}
+		if traders := int(row.LongTraders + row.ShortTraders); traders > concentration.MostTraded.Traders {
+			concentration.MostTraded.Asset = marketAsset(market)
+			concentration.MostTraded.Traders = traders
+		}
+	}
+	if total := long + short; total > 0 {
+		sentiment.LongPercentage = float64(long) / float64(total) * 100
+		sentiment.ShortPercentage = float64(short) / float64(total) * 100
+	}
+	return sentiment, concentration
+}
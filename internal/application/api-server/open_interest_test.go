@@ -0,0 +1,49 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+)
+
+func TestToOpenInterestPoint(t *testing.T) {
+	market := models.Market{Address: "0xm", Name: "BTC/USD", SizeDecimals: 8, PriceDecimals: 6}
+	row := models.OpenInterest{Market: "0xm", LongSize: 150_000_000, ShortSize: 150_000_000, LongTraders: 3, ShortTraders: 2, MarkPx: 100_000_000}
+
+	p := toOpenInterestPoint(market, row)
+	if p.LongSize != 1.5 || p.MarkPrice != 100 || p.LongNotional != 150 || p.OpenInterest != 150 {
+		t.Errorf("point = %+v, want 1.5 long at 100", p)
+	}
+	if p.LongShortRatio != 1.5 {
+		t.Errorf("ratio = %v, want 1.5", p.LongShortRatio)
+	}
+	row.ShortTraders = 0
+	if p := toOpenInterestPoint(market, row); p.LongShortRatio != 0 {
+		t.Errorf("ratio without shorts = %v, want 0", p.LongShortRatio)
+	}
+}
+
+func TestSummarizeOpenInterest(t *testing.T) {
+	markets := map[string]models.Market{
+		"0xbtc": {Address: "0xbtc", Name: "BTC/USD", SizeDecimals: 8, PriceDecimals: 6},
+		"0xeth": {Address: "0xeth", Name: "ETH/USD", SizeDecimals: 8, PriceDecimals: 6},
+	}
+	rows := []models.OpenInterest{
+		{Market: "0xbtc", LongSize: 200_000_000, ShortSize: 200_000_000, LongTraders: 1, ShortTraders: 1, MarkPx: 100_000_000},
+		{Market: "0xeth", LongSize: 100_000_000, ShortSize: 100_000_000, LongTraders: 5, ShortTraders: 1, MarkPx: 50_000_000},
+	}
+
+	sentiment, concentration := summarizeOpenInterest(rows, markets)
+	if sentiment.LongPercentage != 75 || sentiment.ShortPercentage != 25 {
+		t.Errorf("sentiment = %+v, want 75/25", sentiment)
+	}
+	if concentration.HighestOI.Asset != "BTC" || concentration.HighestOI.Amount != 200 {
+		t.Errorf("highest OI = %+v, want BTC 200", concentration.HighestOI)
+	}
+	if concentration.MostTraded.Asset != "ETH" || concentration.MostTraded.Traders != 6 {
+		t.Errorf("most traded = %+v, want ETH 6", concentration.MostTraded)
+	}
+	if concentration.TotalMonitored != 250 {
+		t.Errorf("total = %v, want 250", concentration.TotalMonitored)
+	}
+}
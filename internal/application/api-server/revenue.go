@@ -0,0 +1,154 @@
+package apiserver
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultRevenueRange = 30 * 24 * time.Hour
+
+	revenueGroupByDay     = "day"
+	revenueGroupByMarket  = "market"
+	revenueGroupByBuilder = "builder"
+)
+
+// getRevenue 일별/마켓별/빌더별 수수료 수익 조회
+func (app *Application) getRevenue(c *gin.Context) {
+	var req RevenueRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+	app.respondRevenue(c, req)
+}
+
+// getBuilderRevenue 빌더/레퍼러 주소가 받은 수수료 조회
+func (app *Application) getBuilderRevenue(c *gin.Context) {
+	var req RevenueRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+	req.Builder = types.NormalizeAddress(c.Param("address"))
+	if req.Builder == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid account address",
+		})
+		return
+	}
+	app.respondRevenue(c, req)
+}
+
+func (app *Application) respondRevenue(c *gin.Context, req RevenueRequest) {
+	if req.GroupBy == "" {
+		req.GroupBy = revenueGroupByDay
+	}
+	filter := models.FeeRevenueFilter{Builder: req.Builder}
+	if req.Market != "" {
+		market, ok, err := app.findMarket(c, req.Market)
+		if err != nil {
+			ErrorWithCode(c, err, ErrDatabase)
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Market not found",
+			})
+			return
+		}
+		filter.Market = market.Address
+	}
+	filter.To = time.Now().UTC()
+	if req.To > 0 {
+		filter.To = time.Unix(req.To, 0).UTC()
+	}
+	filter.From = filter.To.Add(-defaultRevenueRange).Truncate(24 * time.Hour)
+	if req.From > 0 {
+		filter.From = time.Unix(req.From, 0).UTC()
+	}
+
+	rows, err := models.GetFeeRevenue(app.db.WithContext(c), filter)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	markets, err := app.marketsByAddress(c)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+
+	buckets := aggregateRevenue(rows, req.GroupBy, markets)
+	var total RevenueBucket
+	for _, b := range buckets {
+		total.TraderFees += b.TraderFees
+		total.TreasuryFees += b.TreasuryFees
+		total.BackstopFees += b.BackstopFees
+		total.BuilderFees += b.BuilderFees
+		total.Trades += b.Trades
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": RevenueResponse{
+			GroupBy: req.GroupBy,
+			From:    filter.From,
+			To:      filter.To,
+			Total:   total,
+			Buckets: buckets,
+		},
+	})
+}
+
+// aggregateRevenue 일별 수익 행을 그룹 기준으로 합산, 입력 순서 유지
+func aggregateRevenue(rows []models.FeeRevenue, groupBy string, markets map[string]models.Market) []RevenueBucket {
+	collateral := math.Pow10(models.CollateralDecimals)
+	index := make(map[string]int)
+	buckets := make([]RevenueBucket, 0)
+	for _, row := range rows {
+		var key string
+		switch groupBy {
+		case revenueGroupByMarket:
+			key = row.Market
+		case revenueGroupByBuilder:
+			key = row.Builder
+		default:
+			key = row.Day.Format(time.DateOnly)
+		}
+
+		i, ok := index[key]
+		if !ok {
+			var b RevenueBucket
+			switch groupBy {
+			case revenueGroupByMarket:
+				b.Market = row.Market
+				b.MarketName = markets[row.Market].Name
+			case revenueGroupByBuilder:
+				builder := row.Builder
+				b.Builder = &builder
+			default:
+				day := row.Day
+				b.Day = &day
+			}
+			i = len(buckets)
+			index[key] = i
+			buckets = append(buckets, b)
+		}
+		buckets[i].TraderFees += float64(row.TraderFees) / collateral
+		buckets[i].TreasuryFees += float64(row.TreasuryFees) / collateral
+		buckets[i].BackstopFees += float64(row.BackstopFees) / collateral
+		buckets[i].BuilderFees += float64(row.BuilderFees) / collateral
+		buckets[i].Trades += row.Trades
+	}
+	return buckets
+}
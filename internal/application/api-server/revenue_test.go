@@ -0,0 +1,45 @@
+package apiserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+)
+
+func TestAggregateRevenue(t *testing.T) {
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	markets := map[string]models.Market{
+		"0xbtc": {Address: "0xbtc", Name: "BTC/USD"},
+		"0xeth": {Address: "0xeth", Name: "ETH/USD"},
+	}
+	rows := []models.FeeRevenue{
+		{Day: day, Market: "0xbtc", Builder: "", TraderFees: 3_000_000, TreasuryFees: 2_500_000, BackstopFees: 500_000, Trades: 2},
+		{Day: day, Market: "0xbtc", Builder: "0xb", TraderFees: 2_000_000, TreasuryFees: 1_000_000, BuilderFees: 1_000_000, Trades: 1},
+		{Day: day.AddDate(0, 0, 1), Market: "0xeth", Builder: "0xb", TraderFees: 1_000_000, TreasuryFees: -500_000, BuilderFees: 1_500_000, Trades: 1},
+	}
+
+	byDay := aggregateRevenue(rows, revenueGroupByDay, markets)
+	if len(byDay) != 2 {
+		t.Fatalf("expected 2 day buckets, got %d", len(byDay))
+	}
+	if !byDay[0].Day.Equal(day) || !almostEqual(byDay[0].TraderFees, 5) || byDay[0].Trades != 3 {
+		t.Errorf("unexpected first day bucket: %+v", byDay[0])
+	}
+	if !almostEqual(byDay[1].TreasuryFees, -0.5) {
+		t.Errorf("TreasuryFees = %v, want -0.5", byDay[1].TreasuryFees)
+	}
+
+	byMarket := aggregateRevenue(rows, revenueGroupByMarket, markets)
+	if len(byMarket) != 2 || byMarket[0].MarketName != "BTC/USD" || byMarket[0].Day != nil {
+		t.Fatalf("unexpected market buckets: %+v", byMarket)
+	}
+
+	byBuilder := aggregateRevenue(rows, revenueGroupByBuilder, markets)
+	if len(byBuilder) != 2 {
+		t.Fatalf("expected 2 builder buckets, got %d", len(byBuilder))
+	}
+	if *byBuilder[1].Builder != "0xb" || !almostEqual(byBuilder[1].BuilderFees, 2.5) {
+		t.Errorf("unexpected builder bucket: %+v", byBuilder[1])
+	}
+}
@@ -4,21 +4,29 @@ import (
 	"net/http"
 
 	"github.com/cresendoo/decidash-backend/internal/application/api-server/middleware"
+	"github.com/cresendoo/decidash-backend/pkg/metrics"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func (app *Application) setRouter() http.Handler {
 	handler := gin.New()
+	// lets handlers pass *gin.Context where the request context is expected,
+	// e.g. to carry the trace span into db queries
+	handler.ContextWithFallback = true
 
-	handler.Use(middleware.CORS())
+	handler.Use(middleware.CORS(), middleware.Metrics)
 
 	handler.GET("health_check", middleware.HealthCheck())
+	handler.GET("metrics", gin.WrapH(metrics.Handler()))
+	handler.GET("ws", app.websocketHandler)
 
 	api := handler.Group("/api")
 	apiV1 := api.Group("/v1")
 
 	// set middleware
 	apiV1.Use(
+		otelgin.Middleware("api-server"),
 		middleware.SetRequestID,
 		middleware.SetRequsetLogger(app.logger),
 		middleware.GinRecovery(),
@@ -32,10 +40,35 @@ func (app *Application) setRouter() http.Handler {
 		traders.GET("/dashboard", app.getDashboardSummary)
 		traders.GET("", app.getTraders)
 		traders.GET("/:address", app.getTraderDetail)
+		traders.GET("/:address/positions", app.getTraderPositions)
+		traders.GET("/:address/triggers", app.getTraderTriggers)
+		traders.GET("/:address/funding", app.getTraderFunding)
+		traders.GET("/:address/collateral", app.getTraderCollateral)
 		traders.GET("/stats", app.getTraderStats)
 		traders.GET("/assets/stats", app.getAssetStats)
 	}
 
+	markets := apiV1.Group("/markets")
+	{
+		markets.GET("/:market/funding", app.getMarketFunding)
+		markets.GET("/:market/venues", app.getMarketVenues)
+		markets.GET("/:market/candles", app.getMarketCandles)
+		markets.GET("/:market/open-interest", app.getMarketOpenInterest)
+	}
+
+	liquidations := apiV1.Group("/liquidations")
+	{
+		liquidations.GET("", app.getLiquidations)
+	}
+
+	apiV1.GET("/leaderboard", app.getLeaderboard)
+
+	apiV1.GET("/revenue", app.getRevenue)
+	builders := apiV1.Group("/builders")
+	{
+		builders.GET("/:address/revenue", app.getBuilderRevenue)
+	}
+
 	transactions := apiV1.Group("/transactions")
 	{
 		transactions.POST("", app.postFeePayer)
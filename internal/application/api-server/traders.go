@@ -0,0 +1,59 @@
+package apiserver
+
+import (
+	"strings"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+)
+
+const defaultTraderSort = "all_time_pnl"
+
+// toTrader 분석 작업이 계산한 트레이더 지표를 목록 항목으로 변환
+func toTrader(a models.TraderAnalytics, markets map[string]models.Market) Trader {
+	trader := Trader{
+		Address:    a.Account,
+		Avatar:     "https://api.dicebear.com/7.x/avataaars/svg?seed=" + a.Account,
+		PerpEquity: a.Equity,
+		DailyPnL:   PnLData{Amount: a.DailyPnl, Percentage: a.DailyRoi},
+		WeeklyPnL:  PnLData{Amount: a.WeeklyPnl, Percentage: a.WeeklyRoi},
+		MonthlyPnL: PnLData{Amount: a.MonthlyPnl, Percentage: a.MonthlyRoi},
+		AllTimePnL: PnLData{Amount: a.AllTimePnl, Percentage: a.AllTimeRoi},
+		Performance: &TraderPerformance{
+			Trades:          a.Trades,
+			ClosedPositions: a.ClosedPositions,
+			WinRate:         a.WinRate,
+			AvgWin:          a.AvgWin,
+			AvgLoss:         a.AvgLoss,
+			ProfitFactor:    a.ProfitFactor,
+			MaxDrawdown:     a.MaxDrawdown,
+			Sharpe:          a.Sharpe,
+			Sortino:         a.Sortino,
+			AvgLeverage:     a.AvgLeverage,
+			AvgHoldingTime:  a.AvgHoldingSeconds,
+			UpdatedAt:       a.UpdatedAt,
+		},
+	}
+	if a.MainMarket != "" {
+		position := &MainPosition{Type: "SHORT", Asset: marketAsset(markets[a.MainMarket]), Amount: a.MainNotional}
+		if a.MainIsLong {
+			position.Type = "LONG"
+		}
+		trader.MainPosition = position
+	}
+	if total := a.LongNotional + a.ShortNotional; total > 0 {
+		trader.DirectionBias = DirectionBias{
+			LongPercentage:  a.LongNotional / total * 100,
+			ShortPercentage: a.ShortNotional / total * 100,
+		}
+	}
+	return trader
+}
+
+// marketAsset 마켓의 기초자산, e.g. BTC/USD 는 BTC
+func marketAsset(market models.Market) string {
+	asset, _, _ := strings.Cut(market.Name, "/")
+	if asset == "" {
+		return market.Address
+	}
+	return asset
+}
@@ -0,0 +1,42 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+)
+
+func TestToTrader(t *testing.T) {
+	markets := map[string]models.Market{"0xm": {Address: "0xm", Name: "BTC/USD"}}
+	trader := toTrader(models.TraderAnalytics{
+		Account:       "0xa",
+		Equity:        1500,
+		WeeklyPnl:     120,
+		WeeklyRoi:     8.5,
+		MainMarket:    "0xm",
+		MainNotional:  3000,
+		LongNotional:  1000,
+		ShortNotional: 3000,
+		WinRate:       60,
+	}, markets)
+
+	if trader.Address != "0xa" || trader.PerpEquity != 1500 {
+		t.Errorf("trader = %+v", trader)
+	}
+	if trader.WeeklyPnL != (PnLData{Amount: 120, Percentage: 8.5}) {
+		t.Errorf("weekly pnl = %+v", trader.WeeklyPnL)
+	}
+	if p := trader.MainPosition; p == nil || p.Type != "SHORT" || p.Asset != "BTC" || p.Amount != 3000 {
+		t.Errorf("main position = %+v, want a 3000 BTC short", p)
+	}
+	if !almostEqual(trader.DirectionBias.LongPercentage, 25) || !almostEqual(trader.DirectionBias.ShortPercentage, 75) {
+		t.Errorf("direction bias = %+v, want 25/75", trader.DirectionBias)
+	}
+	if trader.Performance == nil || trader.Performance.WinRate != 60 {
+		t.Errorf("performance = %+v", trader.Performance)
+	}
+
+	if flat := toTrader(models.TraderAnalytics{Account: "0xb"}, markets); flat.MainPosition != nil || flat.DirectionBias != (DirectionBias{}) {
+		t.Errorf("trader without positions = %+v", flat)
+	}
+}
@@ -0,0 +1,81 @@
+package apiserver
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/gin-gonic/gin"
+)
+
+// getTraderTriggers 트레이더 포지션의 TP/SL 및 reduce-only 주문 조회
+func (app *Application) getTraderTriggers(c *gin.Context) {
+	address := c.Param("address")
+	if address == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Address parameter is required",
+		})
+		return
+	}
+
+	triggers, err := app.traderTriggers(c, address)
+	if err != nil {
+		ErrorWithCode(c, err, ErrDatabase)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": triggers,
+	})
+}
+
+// traderTriggers 트레이더의 트리거를 마켓 단위로 변환하여 조회
+func (app *Application) traderTriggers(ctx context.Context, address string) ([]PositionTrigger, error) {
+	rows, err := models.GetPositionTriggers(app.db.WithContext(ctx), address)
+	if err != nil {
+		return nil, err
+	}
+	markets, err := app.marketsByAddress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	triggers := make([]PositionTrigger, 0, len(rows))
+	for _, row := range rows {
+		triggers = append(triggers, toPositionTrigger(markets[row.Market], row))
+	}
+	return triggers, nil
+}
+
+// attachTriggers 평가된 포지션에 해당 포지션의 트리거를 연결
+func attachTriggers(valuation *TraderValuation, triggers []PositionTrigger) {
+	for i := range valuation.Positions {
+		p := &valuation.Positions[i]
+		p.Triggers = nil
+		for _, t := range triggers {
+			if t.PositionAddress == p.PositionAddress && t.Market == p.Market && t.IsCrossed == p.IsCrossed {
+				p.Triggers = append(p.Triggers, t)
+			}
+		}
+	}
+}
+
+func toPositionTrigger(market models.Market, row models.PositionTrigger) PositionTrigger {
+	t := PositionTrigger{
+		PositionAddress: row.PositionAddress,
+		Market:          row.Market,
+		MarketName:      market.Name,
+		IsCrossed:       row.IsCrossed,
+		OrderID:         row.OrderID.String(),
+		Kind:            row.Kind,
+		IsFullSize:      row.IsFullSize,
+		TriggerPrice:    market.Price(row.TriggerPrice),
+	}
+	if row.LimitPrice > 0 {
+		limit := market.Price(row.LimitPrice)
+		t.LimitPrice = &limit
+	}
+	if row.Size > 0 {
+		size := market.Size(row.Size)
+		t.Size = &size
+	}
+	return t
+}
@@ -0,0 +1,46 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+)
+
+func TestToPositionTrigger(t *testing.T) {
+	market := models.Market{Address: "0xm", Name: "BTC/USD", SizeDecimals: 8, PriceDecimals: 6}
+
+	full := toPositionTrigger(market, models.PositionTrigger{
+		PositionAddress: "0xp",
+		Market:          "0xm",
+		Kind:            models.TriggerKindTakeProfit,
+		IsFullSize:      true,
+		TriggerPrice:    110_000_000_000,
+	})
+	if !almostEqual(full.TriggerPrice, 110000) || full.LimitPrice != nil || full.Size != nil {
+		t.Errorf("unexpected full-sized trigger: %+v", full)
+	}
+
+	fixed := toPositionTrigger(market, models.PositionTrigger{
+		PositionAddress: "0xp",
+		Market:          "0xm",
+		Kind:            models.TriggerKindStopLoss,
+		TriggerPrice:    90_000_000_000,
+		LimitPrice:      89_500_000_000,
+		Size:            25_000_000,
+	})
+	if fixed.LimitPrice == nil || !almostEqual(*fixed.LimitPrice, 89500) {
+		t.Errorf("LimitPrice = %v, want 89500", fixed.LimitPrice)
+	}
+	if fixed.Size == nil || !almostEqual(*fixed.Size, 0.25) {
+		t.Errorf("Size = %v, want 0.25", fixed.Size)
+	}
+
+	valuation := TraderValuation{Positions: []PositionValuation{
+		{PositionAddress: "0xp", Market: "0xm"},
+		{PositionAddress: "0xp", Market: "0xn"},
+	}}
+	attachTriggers(&valuation, []PositionTrigger{full, fixed})
+	if len(valuation.Positions[0].Triggers) != 2 || len(valuation.Positions[1].Triggers) != 0 {
+		t.Errorf("triggers attached to the wrong positions: %+v", valuation.Positions)
+	}
+}
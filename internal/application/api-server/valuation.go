@@ -0,0 +1,340 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/pkg/market/binance"
+	"github.com/cresendoo/decidash-backend/pkg/xredis"
+	"github.com/mediocregopher/radix/v3"
+	"gorm.io/gorm"
+)
+
+const (
+	valuationKeyPrefix = "valuation:trader:"
+	valuationTTL       = 10 * time.Minute
+
+	positionRefreshInterval = 5 * time.Second
+
+	priceSourceDecibel = "decibel"
+	priceSourceBinance = "binance"
+)
+
+type markPrice struct {
+	price        float64
+	fundingIndex *big.Int // nil if the price came from an external venue
+	source       string
+}
+
+// valuator 실시간 가격으로 포지션의 미실현 손익을 계산하여 redis 에 캐시
+type valuator struct {
+	db     *gorm.DB
+	pool   *radix.Pool
+	logger *slog.Logger
+
+	// binance symbol (e.g. BTCUSDT) by market name
+	symbols map[string]string
+	// converts `size * funding index delta` into collateral units
+	fundingScale *big.Int
+
+	mu        sync.RWMutex
+	markets   map[string]models.Market
+	bySymbol  map[string]string
+	positions map[string][]models.PerpPosition // by market
+	byOwner   map[string][]models.PerpPosition
+	decibel   map[string]models.MarketPrice
+	external  map[string]float64
+
+	ticks chan string
+}
+
+func newValuator(db *gorm.DB, pool *radix.Pool, logger *slog.Logger, symbols map[string]string, fundingScale *big.Int) *valuator {
+	upper := make(map[string]string, len(symbols))
+	for name, symbol := range symbols {
+		upper[name] = strings.ToUpper(symbol)
+	}
+	return &valuator{
+		db:           db,
+		pool:         pool,
+		logger:       logger.With("name", "valuator"),
+		symbols:      upper,
+		fundingScale: fundingScale,
+		markets:      make(map[string]models.Market),
+		bySymbol:     make(map[string]string),
+		decibel:      make(map[string]models.MarketPrice),
+		external:     make(map[string]float64),
+		ticks:        make(chan string, 1024),
+	}
+}
+
+func (v *valuator) Run(ctx context.Context) {
+	if _, err := v.refresh(); err != nil {
+		v.logger.Error("failed to refresh positions", "error", err)
+	}
+	v.revalueAll()
+
+	ticker := time.NewTicker(positionRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			closed, err := v.refresh()
+			if err != nil {
+				v.logger.Error("failed to refresh positions", "error", err)
+				continue
+			}
+			v.remove(closed)
+			v.revalueAll()
+		case market := <-v.ticks:
+			v.revalue(market)
+		}
+	}
+}
+
+// OnOrderbookUpdate records the binance index price of a symbol as the
+// fallback mark price of the market it backs.
+func (v *valuator) OnOrderbookUpdate(orderbook binance.Orderbook) {
+	v.mu.Lock()
+	market, ok := v.bySymbol[orderbook.Symbol]
+	if ok {
+		v.external[market] = orderbook.IndexPrice
+	}
+	v.mu.Unlock()
+	if ok {
+		v.tick(market)
+	}
+}
+
+// OnPriceTick reloads the decibel mark price of a market published by the indexer.
+func (v *valuator) OnPriceTick(market string) {
+	var price models.MarketPrice
+	if err := v.db.Where("market = ?", market).First(&price).Error; err != nil {
+		v.logger.Error("failed to load market price", "market", market, "error", err)
+		return
+	}
+	v.mu.Lock()
+	v.decibel[market] = price
+	v.mu.Unlock()
+	v.tick(market)
+}
+
+func (v *valuator) tick(market string) {
+	select {
+	case v.ticks <- market:
+	default:
+	}
+}
+
+// refresh reloads the markets and open positions, and returns the owners
+// whose last position closed since the previous refresh.
+func (v *valuator) refresh() ([]string, error) {
+	markets, err := models.GetMarkets(v.db)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := models.GetMarketPrices(v.db)
+	if err != nil {
+		return nil, err
+	}
+	var positions []models.PerpPosition
+	if err := v.db.Where("size > 0").Find(&positions).Error; err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.markets = make(map[string]models.Market, len(markets))
+	v.bySymbol = make(map[string]string, len(markets))
+	for _, m := range markets {
+		v.markets[m.Address] = m
+		if symbol, ok := v.symbols[m.Name]; ok {
+			v.bySymbol[symbol] = m.Address
+		}
+	}
+	for _, p := range prices {
+		v.decibel[p.Market] = p
+	}
+	previous := v.byOwner
+	v.positions = make(map[string][]models.PerpPosition)
+	v.byOwner = make(map[string][]models.PerpPosition)
+	for _, p := range positions {
+		v.positions[p.Market] = append(v.positions[p.Market], p)
+		v.byOwner[p.Owner] = append(v.byOwner[p.Owner], p)
+	}
+	return closedOwners(previous, v.byOwner), nil
+}
+
+// closedOwners returns the owners of previous without positions in current.
+func closedOwners(previous, current map[string][]models.PerpPosition) []string {
+	var closed []string
+	for owner := range previous {
+		if _, ok := current[owner]; !ok {
+			closed = append(closed, owner)
+		}
+	}
+	sort.Strings(closed)
+	return closed
+}
+
+func (v *valuator) revalueAll() {
+	v.mu.RLock()
+	owners := make([]string, 0, len(v.byOwner))
+	for owner := range v.byOwner {
+		owners = append(owners, owner)
+	}
+	v.mu.RUnlock()
+	v.store(v.valueOwners(owners))
+}
+
+func (v *valuator) revalue(market string) {
+	v.mu.RLock()
+	seen := make(map[string]bool)
+	var owners []string
+	for _, p := range v.positions[market] {
+		if !seen[p.Owner] {
+			seen[p.Owner] = true
+			owners = append(owners, p.Owner)
+		}
+	}
+	v.mu.RUnlock()
+	v.store(v.valueOwners(owners))
+}
+
+func (v *valuator) valueOwners(owners []string) []TraderValuation {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	now := time.Now()
+	traders := make([]TraderValuation, 0, len(owners))
+	for _, owner := range owners {
+		var positions []PositionValuation
+		for _, p := range v.byOwner[owner] {
+			market, ok := v.markets[p.Market]
+			if !ok {
+				continue
+			}
+			mark, ok := v.markPrice(market)
+			if !ok {
+				continue
+			}
+			positions = append(positions, valuePosition(market, p, mark, v.fundingScale))
+		}
+		traders = append(traders, valueTrader(owner, positions, now))
+	}
+	return traders
+}
+
+// markPrice prefers the on-chain mark price and falls back to the binance index price.
+func (v *valuator) markPrice(market models.Market) (markPrice, bool) {
+	if p, ok := v.decibel[market.Address]; ok && p.MarkPx > 0 {
+		return markPrice{
+			price:        market.Price(p.MarkPx),
+			fundingIndex: p.FundingIndex.BigInt(),
+			source:       priceSourceDecibel,
+		}, true
+	}
+	if price, ok := v.external[market.Address]; ok && price > 0 {
+		return markPrice{price: price, source: priceSourceBinance}, true
+	}
+	return markPrice{}, false
+}
+
+func (v *valuator) store(traders []TraderValuation) {
+	if len(traders) == 0 {
+		return
+	}
+	ttl := strconv.Itoa(int(valuationTTL.Seconds()))
+	cmds := make([]radix.CmdAction, 0, len(traders))
+	for _, t := range traders {
+		b, err := json.Marshal(t)
+		if err != nil {
+			v.logger.Error("failed to marshal valuation", "address", t.Address, "error", err)
+			continue
+		}
+		cmds = append(cmds, radix.Cmd(nil, "SET", valuationKeyPrefix+t.Address, string(b), "EX", ttl))
+	}
+	if err := xredis.Do(context.Background(), v.pool, "SET", radix.Pipeline(cmds...)); err != nil {
+		v.logger.Error("failed to store valuations", "error", err)
+	}
+}
+
+// remove deletes the cached valuations of owners, so closed positions are
+// not served as current until their ttl runs out.
+func (v *valuator) remove(owners []string) {
+	if len(owners) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		keys = append(keys, valuationKeyPrefix+owner)
+	}
+	if err := xredis.Do(context.Background(), v.pool, "DEL", radix.Cmd(nil, "DEL", keys...)); err != nil {
+		v.logger.Error("failed to remove valuations", "error", err)
+	}
+}
+
+// getTraderValuation returns the cached valuation of a trader.
+func getTraderValuation(ctx context.Context, pool *radix.Pool, address string) (TraderValuation, bool, error) {
+	var raw []byte
+	mn := radix.MaybeNil{Rcv: &raw}
+	if err := xredis.Do(ctx, pool, "GET", radix.Cmd(&mn, "GET", valuationKeyPrefix+address)); err != nil {
+		return TraderValuation{}, false, err
+	}
+	if mn.Nil {
+		return TraderValuation{}, false, nil
+	}
+	var t TraderValuation
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return TraderValuation{}, false, err
+	}
+	return t, true, nil
+}
+
+func valuePosition(market models.Market, p models.PerpPosition, mark markPrice, fundingScale *big.Int) PositionValuation {
+	value := models.ValuePosition(market, p, mark.price, mark.fundingIndex, fundingScale)
+	v := PositionValuation{
+		PositionAddress: p.PositionAddress,
+		Market:          market.Address,
+		MarketName:      market.Name,
+		IsCrossed:       p.IsCrossed,
+		IsLong:          p.IsLong,
+		Size:            value.Size,
+		EntryPrice:      value.EntryPrice,
+		MarkPrice:       mark.price,
+		PriceSource:     mark.source,
+		Notional:        value.Notional,
+		PendingFunding:  value.Funding,
+		UnrealizedPnL:   value.UnrealizedPnL(),
+	}
+	if p.UserLeverage > 0 {
+		v.Margin = value.Size * value.EntryPrice / float64(p.UserLeverage)
+	}
+	if v.Margin > 0 {
+		v.ROI = v.UnrealizedPnL / v.Margin * 100
+	}
+	return v
+}
+
+func valueTrader(owner string, positions []PositionValuation, now time.Time) TraderValuation {
+	t := TraderValuation{Address: owner, Positions: positions, UpdatedAt: now}
+	for _, p := range positions {
+		t.Notional += p.Notional
+		t.Margin += p.Margin
+		t.UnrealizedPnL += p.UnrealizedPnL
+		t.PendingFunding += p.PendingFunding
+	}
+	if t.Margin > 0 {
+		t.ROI = t.UnrealizedPnL / t.Margin * 100
+	}
+	return t
+}
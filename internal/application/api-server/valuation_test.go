@@ -0,0 +1,184 @@
+package apiserver
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+)
+
+var testFundingScale = (&Config{}).FundingIndexScale()
+
+func newTestPosition(t *testing.T, isLong bool, size uint64, entryPx uint64, fundingIndex string) models.PerpPosition {
+	t.Helper()
+	p := models.PerpPosition{
+		PositionAddress: "0x1",
+		Market:          "0xm",
+		Owner:           "0xowner",
+		Size:            types.Uint64(size),
+		IsLong:          isLong,
+		UserLeverage:    10,
+	}
+	if err := p.EntryPxTimesSizeSum.SetBigInt(new(big.Int).Mul(new(big.Int).SetUint64(size), new(big.Int).SetUint64(entryPx))); err != nil {
+		t.Fatalf("failed to set entry sum: %v", err)
+	}
+	if err := p.FundingIndexAtLastUpdate.Scan(fundingIndex); err != nil {
+		t.Fatalf("failed to set funding index: %v", err)
+	}
+	return p
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestValuePosition(t *testing.T) {
+	market := models.Market{Address: "0xm", Name: "BTC/USD", SizeDecimals: 8, PriceDecimals: 6}
+	const index = "170141183460469231731687303715884105728"
+
+	tests := []struct {
+		name      string
+		isLong    bool
+		mark      float64
+		wantPnL   float64
+		wantROI   float64
+		wantNotnl float64
+	}{
+		{name: "long in profit", isLong: true, mark: 110, wantPnL: 20, wantROI: 100, wantNotnl: 220},
+		{name: "long in loss", isLong: true, mark: 95, wantPnL: -10, wantROI: -50, wantNotnl: 190},
+		{name: "short in profit", isLong: false, mark: 95, wantPnL: 10, wantROI: 50, wantNotnl: 190},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 2 BTC entered at 100
+			p := newTestPosition(t, tt.isLong, 2_0000_0000, 100_000_000, index)
+			fundingIndex, _ := new(big.Int).SetString(index, 10)
+			got := valuePosition(market, p, markPrice{price: tt.mark, fundingIndex: fundingIndex, source: priceSourceDecibel}, testFundingScale)
+
+			if !almostEqual(got.EntryPrice, 100) {
+				t.Errorf("EntryPrice = %v, want 100", got.EntryPrice)
+			}
+			if !almostEqual(got.UnrealizedPnL, tt.wantPnL) {
+				t.Errorf("UnrealizedPnL = %v, want %v", got.UnrealizedPnL, tt.wantPnL)
+			}
+			if !almostEqual(got.Margin, 20) {
+				t.Errorf("Margin = %v, want 20", got.Margin)
+			}
+			if !almostEqual(got.ROI, tt.wantROI) {
+				t.Errorf("ROI = %v, want %v", got.ROI, tt.wantROI)
+			}
+			if !almostEqual(got.Notional, tt.wantNotnl) {
+				t.Errorf("Notional = %v, want %v", got.Notional, tt.wantNotnl)
+			}
+		})
+	}
+}
+
+func TestValuePositionPendingFunding(t *testing.T) {
+	market := models.Market{Address: "0xm", SizeDecimals: 8, PriceDecimals: 6}
+	p := newTestPosition(t, true, 2_0000_0000, 100_000_000, "1000000000000000000")
+	p.UnrealizedFundingAmountBeforeLastUpdate = types.I64{IsPositive: true, Amount: 500_000}
+
+	// index moved by 1e12 * 1e6 / 2e8 = 5e9, accruing 1 USDC on 2e8 size
+	current, _ := new(big.Int).SetString("1000000005000000000", 10)
+	got := valuePosition(market, p, markPrice{price: 100, fundingIndex: current}, testFundingScale)
+
+	if !almostEqual(got.PendingFunding, 1.5) {
+		t.Errorf("PendingFunding = %v, want 1.5", got.PendingFunding)
+	}
+	if !almostEqual(got.UnrealizedPnL, -1.5) {
+		t.Errorf("UnrealizedPnL = %v, want -1.5", got.UnrealizedPnL)
+	}
+
+	// external prices carry no funding index, only the already accrued amount counts
+	got = valuePosition(market, p, markPrice{price: 100, source: priceSourceBinance}, testFundingScale)
+	if !almostEqual(got.PendingFunding, 0.5) {
+		t.Errorf("PendingFunding = %v, want 0.5", got.PendingFunding)
+	}
+}
+
+// TestValuePositionRecorded values positions recorded in tx 32667225 of the
+// replay fixture at the price of the trades in it.
+func TestValuePositionRecorded(t *testing.T) {
+	// only the sum of the decimals matters for the pnl, the trade below
+	// realizes price delta * size / 10^5 collateral units
+	market := models.Market{Address: "0xe6de4f6ec47f1bc2ab73920e9f202953e60482e1c1a90e7eef3ee45c8aafee36", SizeDecimals: 5, PriceDecimals: 6}
+	const index = "170141183460469231731687301466822096015"
+	fundingIndex, _ := new(big.Int).SetString(index, 10)
+	mark := markPrice{price: market.Price(532817162), fundingIndex: fundingIndex, source: priceSourceDecibel}
+
+	// the isolated long of 0x57bf..e6b7 closed in full at the mark, realizing
+	// 66636190 of pnl of which 58940 is funding
+	p := newTestPosition(t, true, 2000000, 529488299, index)
+	got := valuePosition(market, p, mark, testFundingScale)
+	if want := float64(66636190-58940) / 1e6; math.Abs(got.UnrealizedPnL-want) > 1e-4 {
+		t.Errorf("UnrealizedPnL = %v, want %v", got.UnrealizedPnL, want)
+	}
+
+	// the crossed long of 0x4718..9ec5 was updated at the market index, so
+	// its pending funding is the amount accrued before the update
+	p = models.PerpPosition{Market: market.Address, Size: 8520021029, IsLong: true, UserLeverage: 10}
+	p.UnrealizedFundingAmountBeforeLastUpdate = types.I64{IsPositive: true, Amount: 7430678924}
+	if err := p.EntryPxTimesSizeSum.Scan("4500068895480680725"); err != nil {
+		t.Fatalf("failed to set entry sum: %v", err)
+	}
+	if err := p.FundingIndexAtLastUpdate.Scan(index); err != nil {
+		t.Fatalf("failed to set funding index: %v", err)
+	}
+	got = valuePosition(market, p, mark, testFundingScale)
+	if !almostEqual(got.PendingFunding, 7430.678924) {
+		t.Errorf("PendingFunding = %v, want 7430.678924", got.PendingFunding)
+	}
+	// avg_acquire_entry_px of the position
+	if math.Abs(got.EntryPrice-528.175796) > 1e-6 {
+		t.Errorf("EntryPrice = %v, want 528.175796", got.EntryPrice)
+	}
+}
+
+func TestConfigFundingIndexScale(t *testing.T) {
+	var cfg Config
+	if got := cfg.FundingIndexScale().String(); got != "1000000000000" {
+		t.Errorf("default scale = %s, want 10^12", got)
+	}
+	cfg.Funding.IndexDecimals = 9
+	if got := cfg.FundingIndexScale().String(); got != "1000000000" {
+		t.Errorf("scale = %s, want 10^9", got)
+	}
+}
+
+func TestValueTrader(t *testing.T) {
+	now := time.Now()
+	got := valueTrader("0xowner", []PositionValuation{
+		{Notional: 100, Margin: 10, UnrealizedPnL: 5, PendingFunding: 1},
+		{Notional: 300, Margin: 30, UnrealizedPnL: -1, PendingFunding: -2},
+	}, now)
+
+	if !almostEqual(got.Notional, 400) || !almostEqual(got.Margin, 40) {
+		t.Errorf("Notional, Margin = %v, %v, want 400, 40", got.Notional, got.Margin)
+	}
+	if !almostEqual(got.UnrealizedPnL, 4) || !almostEqual(got.PendingFunding, -1) {
+		t.Errorf("UnrealizedPnL, PendingFunding = %v, %v, want 4, -1", got.UnrealizedPnL, got.PendingFunding)
+	}
+	if !almostEqual(got.ROI, 10) {
+		t.Errorf("ROI = %v, want 10", got.ROI)
+	}
+	if !got.UpdatedAt.Equal(now) {
+		t.Errorf("UpdatedAt = %v, want %v", got.UpdatedAt, now)
+	}
+}
+
+func TestClosedOwners(t *testing.T) {
+	previous := map[string][]models.PerpPosition{"0xa": {{}}, "0xb": {{}}, "0xc": {{}}}
+	current := map[string][]models.PerpPosition{"0xb": {{}}, "0xd": {{}}}
+
+	got := closedOwners(previous, current)
+	if len(got) != 2 || got[0] != "0xa" || got[1] != "0xc" {
+		t.Errorf("closedOwners = %v, want [0xa 0xc]", got)
+	}
+	if got := closedOwners(nil, current); len(got) != 0 {
+		t.Errorf("closedOwners without a previous refresh = %v, want none", got)
+	}
+}
@@ -1,12 +1,30 @@
 package apiserver
 
 import (
+	"encoding/json"
+	"log/slog"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 )
 
+const (
+	wsChannelLiquidations = "liquidations"
+	// wsChannelCandles 캔들 채널 접두사, 뒤에 마켓 주소 또는 이름과 해상도
+	wsChannelCandles = "candles:"
+
+	wsOpSubscribe   = "subscribe"
+	wsOpUnsubscribe = "unsubscribe"
+
+	wsSendBuffer = 256
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+)
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
@@ -15,48 +33,202 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// wsRequest 클라이언트 구독 요청, e.g. {"op":"subscribe","channel":"liquidations"}
+type wsRequest struct {
+	Op      string `json:"op"`
+	Channel string `json:"channel"`
+}
+
+// wsMessage 구독 채널로 전달되는 메시지
+type wsMessage struct {
+	Channel string `json:"channel"`
+	Data    any    `json:"data,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// isWsChannel 구독 가능한 채널인지 확인
+func isWsChannel(channel string) bool {
+	return channel == wsChannelLiquidations || isCandleChannel(channel)
+}
+
+// wsHub 웹소켓 클라이언트와 채널 구독 관리
+type wsHub struct {
+	mu      sync.RWMutex
+	clients map[*wsClient]struct{}
+	logger  *slog.Logger
+}
+
+func newWsHub(logger *slog.Logger) *wsHub {
+	return &wsHub{
+		clients: make(map[*wsClient]struct{}),
+		logger:  logger.With("name", "websocket"),
+	}
+}
+
+// Broadcast sends data to every client subscribed to channel. Clients that
+// cannot keep up are disconnected.
+func (h *wsHub) Broadcast(channel string, data any) {
+	b, err := json.Marshal(wsMessage{Channel: channel, Data: data})
+	if err != nil {
+		h.logger.Error("failed to marshal message", "channel", channel, "error", err)
+		return
+	}
+
+	h.mu.RLock()
+	var slow []*wsClient
+	for client := range h.clients {
+		if !client.subscribed(channel) {
+			continue
+		}
+		select {
+		case client.send <- b:
+		default:
+			slow = append(slow, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range slow {
+		h.logger.Warn("dropping slow client", "remote", client.conn.RemoteAddr().String())
+		h.unregister(client)
+	}
+}
+
+// Close disconnects every client.
+func (h *wsHub) Close() {
+	h.mu.Lock()
+	clients := h.clients
+	h.clients = make(map[*wsClient]struct{})
+	h.mu.Unlock()
+	for client := range clients {
+		client.close()
+	}
+}
+
+func (h *wsHub) register(client *wsClient) {
+	h.mu.Lock()
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+}
+
+func (h *wsHub) unregister(client *wsClient) {
+	h.mu.Lock()
+	delete(h.clients, client)
+	h.mu.Unlock()
+	client.close()
+}
+
+type wsClient struct {
+	conn *websocket.Conn
+	send chan []byte
+	done chan struct{}
+	once sync.Once
+
+	mu       sync.RWMutex
+	channels map[string]bool
+}
+
+func newWsClient(conn *websocket.Conn) *wsClient {
+	return &wsClient{
+		conn:     conn,
+		send:     make(chan []byte, wsSendBuffer),
+		done:     make(chan struct{}),
+		channels: make(map[string]bool),
+	}
+}
+
+func (c *wsClient) subscribed(channel string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.channels[channel]
+}
+
+func (c *wsClient) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+func (c *wsClient) reply(msg wsMessage) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	select {
+	case c.send <- b:
+	default:
+	}
+}
+
+// readLoop handles subscription requests until the connection closes.
+func (c *wsClient) readLoop() {
+	c.conn.SetReadLimit(4096)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	for {
+		var req wsRequest
+		if err := c.conn.ReadJSON(&req); err != nil {
+			return
+		}
+		if !isWsChannel(req.Channel) {
+			c.reply(wsMessage{Channel: req.Channel, Error: "unknown channel"})
+			continue
+		}
+		switch req.Op {
+		case wsOpSubscribe:
+			c.mu.Lock()
+			c.channels[req.Channel] = true
+			c.mu.Unlock()
+		case wsOpUnsubscribe:
+			c.mu.Lock()
+			delete(c.channels, req.Channel)
+			c.mu.Unlock()
+		default:
+			c.reply(wsMessage{Channel: req.Channel, Error: "unknown op"})
+			continue
+		}
+		c.reply(wsMessage{Channel: req.Channel, Data: gin.H{"op": req.Op}})
+	}
+}
+
+// writeLoop writes queued messages and keeps the connection alive.
+func (c *wsClient) writeLoop() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case b := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, b); err != nil {
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+// websocketHandler 실시간 채널 구독 웹소켓
 func (a *Application) websocketHandler(c *gin.Context) {
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		ErrorWithCode(c, err, ErrInternalServer)
 		return
 	}
-	conn.Close()
-	// a.handleWebsocket(conn)
-}
-
-// func (a *Application) handleWebsocket(conn *websocket.Conn) {
-// 	ticker := time.NewTicker(1 * time.Second)
-// 	defer ticker.Stop()
-// 	defer conn.Close()
-
-// 	for {
-// 		select {
-// 		case <-a.ctx.Done():
-// 			return
-// 		case <-ticker.C:
-// 			orderbook, ok := a.binance.GetOrderbook("BTCUSDT")
-// 			if !ok {
-// 				continue
-// 			}
-// 			if err := conn.WriteJSON(map[string]any{
-// 				"s": "b",
-// 				"d": orderbook,
-// 			}); err != nil {
-// 				return
-// 			}
-// 			if err := conn.WriteJSON(map[string]any{
-// 				"s": "p",
-// 				"d": struct {
-// 					Symbol string `json:"s"`
-// 					Price float64 `json:"p"`
-// 				}{
-// 					Symbol: orderbook.Symbol,
-// 					Price: orderbook.IndexPrice,
-// 				},
-// 			}); err != nil {
-// 				return
-// 			}
-// 		}
-// 	}
-// }
+	client := newWsClient(conn)
+	a.hub.register(client)
+	defer a.hub.unregister(client)
+
+	go client.writeLoop()
+	client.readLoop()
+}
@@ -0,0 +1,426 @@
+package decibelindexer
+
+import (
+	"context"
+	"maps"
+	"math"
+	"math/big"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAnalyticsInterval = 15 * time.Minute
+	// analyticsActiveWindow keeps accounts in the refresh for a while after
+	// their last activity, until the monthly window no longer covers it.
+	analyticsActiveWindow = 31 * 24 * time.Hour
+	analyticsBatchSize    = 100
+	oneDay                = 24 * time.Hour
+)
+
+// analyticsJob refreshes the performance metrics of the accounts that were
+// active recently.
+type analyticsJob struct {
+	db *gorm.DB
+	// converts `size * funding index delta` into collateral units
+	fundingScale *big.Int
+}
+
+func newAnalyticsJob(db *gorm.DB, fundingScale *big.Int) *analyticsJob {
+	return &analyticsJob{db: db, fundingScale: fundingScale}
+}
+
+func (j *analyticsJob) Name() string {
+	return "analytics"
+}
+
+func (j *analyticsJob) Interval() time.Duration {
+	return defaultAnalyticsInterval
+}
+
+func (j *analyticsJob) Run(ctx context.Context, _ time.Time) error {
+	db := j.db.WithContext(ctx)
+	now := time.Now().UTC()
+
+	accounts, err := models.GetActiveAccounts(db, now.Add(-analyticsActiveWindow))
+	if err != nil {
+		return err
+	}
+	markets, err := models.GetMarkets(db)
+	if err != nil {
+		return err
+	}
+	prices, err := models.GetMarketPrices(db)
+	if err != nil {
+		return err
+	}
+	marks := make(map[string]models.MarketPrice, len(prices))
+	for _, p := range prices {
+		marks[p.Market] = p
+	}
+	byAddress := make(map[string]models.Market, len(markets))
+	for _, m := range markets {
+		byAddress[m.Address] = m
+	}
+
+	for start := 0; start < len(accounts); start += analyticsBatchSize {
+		batch := accounts[start:min(start+analyticsBatchSize, len(accounts))]
+		analytics, err := analyzeAccounts(db, batch, byAddress, marks, j.fundingScale, now)
+		if err != nil {
+			return err
+		}
+		if err := models.UpsertTraderAnalytics(db, analytics); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// analyzeAccounts computes the analytics of a batch of accounts with one
+// query per table. Trades are only replayed for the accounts that traded
+// since their last analysis; the others keep their closed position metrics.
+func analyzeAccounts(db *gorm.DB, accounts []string, markets map[string]models.Market, marks map[string]models.MarketPrice, fundingScale *big.Int, now time.Time) ([]models.TraderAnalytics, error) {
+	stored, err := models.GetTradersAnalytics(db, accounts)
+	if err != nil {
+		return nil, err
+	}
+	lastTrades, err := models.GetLastTradeVersions(db, accounts)
+	if err != nil {
+		return nil, err
+	}
+	var traded []string
+	for _, account := range accounts {
+		if a, ok := stored[account]; lastTrades[account] > 0 && (!ok || lastTrades[account] > a.TradesVersion) {
+			traded = append(traded, account)
+		}
+	}
+	trades := make(map[string][]models.Trade)
+	if len(traded) > 0 {
+		if trades, err = models.GetAccountsTrades(db, traded); err != nil {
+			return nil, err
+		}
+	}
+	pnl, err := models.GetTradersPnl(db, accounts, now.Add(time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	balances, err := models.GetAccountsBalances(db, accounts, now.Add(time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	// positions are owned by the long form of the accounts
+	owners := make(map[string]string, len(accounts))
+	for _, account := range accounts {
+		owners[types.NormalizeAddress(account)] = account
+	}
+	var positions []models.PerpPosition
+	if err := db.Where("owner IN ? AND size > 0", slices.Collect(maps.Keys(owners))).Find(&positions).Error; err != nil {
+		return nil, err
+	}
+	byOwner := make(map[string][]models.PerpPosition)
+	for _, p := range positions {
+		account := owners[types.NormalizeAddress(p.Owner)]
+		byOwner[account] = append(byOwner[account], p)
+	}
+
+	analytics := make([]models.TraderAnalytics, 0, len(accounts))
+	for _, account := range accounts {
+		a := analyzeTrader(account, trades[account], pnl[account], balances[account], now)
+		if accountTrades, ok := trades[account]; ok {
+			a.TradesVersion = accountTrades[len(accountTrades)-1].Version
+		} else {
+			keepTradeStats(&a, stored[account])
+		}
+		exposure := valueExposure(byOwner[account], markets, marks, fundingScale)
+		a.Equity += exposure.unrealized
+		a.MainMarket = exposure.mainMarket
+		a.MainIsLong = exposure.mainIsLong
+		a.MainNotional = exposure.mainNotional
+		a.LongNotional = exposure.long
+		a.ShortNotional = exposure.short
+		analytics = append(analytics, a)
+	}
+	return analytics, nil
+}
+
+// keepTradeStats copies the closed position metrics of the stored analytics
+// of an account without new trades.
+func keepTradeStats(a *models.TraderAnalytics, stored models.TraderAnalytics) {
+	a.Trades = stored.Trades
+	a.ClosedPositions = stored.ClosedPositions
+	a.WinRate = stored.WinRate
+	a.AvgWin = stored.AvgWin
+	a.AvgLoss = stored.AvgLoss
+	a.ProfitFactor = stored.ProfitFactor
+	a.AvgLeverage = stored.AvgLeverage
+	a.AvgHoldingSeconds = stored.AvgHoldingSeconds
+	a.TradesVersion = stored.TradesVersion
+}
+
+// closedPosition is a position from its first trade until it was closed or
+// flipped to the other side.
+type closedPosition struct {
+	opened   time.Time
+	closed   time.Time
+	pnl      int64
+	leverage int
+}
+
+// closedPositions replays the trades of an account, oldest first, into the
+// positions they closed. Positions opened before the first indexed trade
+// are skipped as their entry is unknown.
+func closedPositions(trades []models.Trade) []closedPosition {
+	type open struct {
+		closedPosition
+		isLong  bool
+		partial bool
+	}
+	opens := make(map[string]*open)
+	var closed []closedPosition
+	for _, t := range trades {
+		p := opens[t.Market]
+		if p == nil {
+			p = &open{
+				closedPosition: closedPosition{opened: t.VersionTimestamp, leverage: t.Leverage},
+				isLong:         t.PositionIsLong,
+				partial:        strings.HasPrefix(t.Action, "Close"),
+			}
+			opens[t.Market] = p
+		}
+		p.pnl += t.Net()
+		if t.PositionSize > 0 && t.PositionIsLong == p.isLong {
+			continue
+		}
+
+		p.closed = t.VersionTimestamp
+		if !p.partial {
+			closed = append(closed, p.closedPosition)
+		}
+		delete(opens, t.Market)
+		if t.PositionSize > 0 {
+			// flipped, the rest of the trade opened the other side
+			opens[t.Market] = &open{
+				closedPosition: closedPosition{opened: t.VersionTimestamp, leverage: t.Leverage},
+				isLong:         t.PositionIsLong,
+			}
+		}
+	}
+	return closed
+}
+
+// equitySample is the collateral of all balance sheets at the close of an
+// hour.
+type equitySample struct {
+	hour  time.Time
+	total int64
+}
+
+// equitySamples folds the hourly sheet balances, oldest first, into the
+// total collateral after every hour with a change.
+func equitySamples(balances []models.AccountBalance) []equitySample {
+	sheets := make(map[string]int64)
+	var samples []equitySample
+	for i, b := range balances {
+		sheets[b.Market] = int64(b.Balance)
+		if i+1 < len(balances) && balances[i+1].Hour.Equal(b.Hour) {
+			continue
+		}
+		var total int64
+		for _, balance := range sheets {
+			total += balance
+		}
+		samples = append(samples, equitySample{hour: b.Hour, total: total})
+	}
+	return samples
+}
+
+// equityAt is the total collateral at t, 0 before the first sample.
+func equityAt(samples []equitySample, t time.Time) int64 {
+	i := sort.Search(len(samples), func(i int) bool {
+		return samples[i].hour.Add(time.Hour).After(t)
+	})
+	if i == 0 {
+		return 0
+	}
+	return samples[i-1].total
+}
+
+// dailyReturns are the net PnL of every day since the first activity over
+// the equity at the start of the day. Days starting without equity have no
+// return.
+func dailyReturns(pnl []models.TraderPnl, samples []equitySample, now time.Time) []float64 {
+	if len(pnl) == 0 {
+		return nil
+	}
+	first := pnl[0].Hour
+	if len(samples) > 0 && samples[0].hour.Before(first) {
+		first = samples[0].hour
+	}
+
+	var returns []float64
+	i := 0
+	for start := first.Truncate(oneDay); start.Before(now); start = start.Add(oneDay) {
+		end := start.Add(oneDay)
+		var net int64
+		for ; i < len(pnl) && pnl[i].Hour.Before(end); i++ {
+			net += pnl[i].Net()
+		}
+		if equity := equityAt(samples, start); equity > 0 {
+			returns = append(returns, float64(net)/float64(equity))
+		}
+	}
+	return returns
+}
+
+// analyzeTrader computes the metrics of an account from its trades, hourly
+// PnL and balances, all oldest first. Equity is the collateral only.
+func analyzeTrader(account string, trades []models.Trade, pnl []models.TraderPnl, balances []models.AccountBalance, now time.Time) models.TraderAnalytics {
+	unit := math.Pow10(models.CollateralDecimals)
+	samples := equitySamples(balances)
+	a := models.TraderAnalytics{
+		Account:   account,
+		Trades:    int64(len(trades)),
+		UpdatedAt: now,
+	}
+	if len(samples) > 0 {
+		a.Equity = float64(samples[len(samples)-1].total) / unit
+	}
+
+	window := func(span time.Duration) (float64, float64) {
+		start := now.Truncate(time.Hour).Add(-span)
+		var net int64
+		for _, p := range pnl {
+			if !p.Hour.Before(start) {
+				net += p.Net()
+			}
+		}
+		var roi float64
+		if equity := equityAt(samples, start); equity > 0 {
+			roi = float64(net) / float64(equity) * 100
+		}
+		return float64(net) / unit, roi
+	}
+	a.DailyPnl, a.DailyRoi = window(oneDay)
+	a.WeeklyPnl, a.WeeklyRoi = window(7 * oneDay)
+	a.MonthlyPnl, a.MonthlyRoi = window(30 * oneDay)
+	for _, p := range pnl {
+		a.AllTimePnl += float64(p.Net()) / unit
+	}
+
+	closed := closedPositions(trades)
+	var wins, losses int
+	var grossWin, grossLoss, leverage float64
+	var holding time.Duration
+	for _, p := range closed {
+		switch {
+		case p.pnl > 0:
+			wins++
+			grossWin += float64(p.pnl) / unit
+		case p.pnl < 0:
+			losses++
+			grossLoss -= float64(p.pnl) / unit
+		}
+		leverage += float64(p.leverage)
+		holding += p.closed.Sub(p.opened)
+	}
+	a.ClosedPositions = int64(len(closed))
+	if len(closed) > 0 {
+		a.WinRate = float64(wins) / float64(len(closed)) * 100
+		a.AvgLeverage = leverage / float64(len(closed))
+		a.AvgHoldingSeconds = holding.Seconds() / float64(len(closed))
+	}
+	if wins > 0 {
+		a.AvgWin = grossWin / float64(wins)
+	}
+	if losses > 0 {
+		a.AvgLoss = grossLoss / float64(losses)
+		a.ProfitFactor = grossWin / grossLoss
+	}
+
+	returns := dailyReturns(pnl, samples, now)
+	index, peak := 1.0, 1.0
+	for _, r := range returns {
+		index *= 1 + r
+		peak = math.Max(peak, index)
+		if peak > 0 {
+			a.MaxDrawdown = math.Max(a.MaxDrawdown, (peak-index)/peak*100)
+		}
+	}
+	a.AllTimeRoi = (index - 1) * 100
+	a.Sharpe, a.Sortino = sharpeSortino(returns)
+	return a
+}
+
+// sharpeSortino annualizes the mean daily return over its standard
+// deviation and over its downside deviation.
+func sharpeSortino(returns []float64) (float64, float64) {
+	if len(returns) < 2 {
+		return 0, 0
+	}
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance, downside float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	std := math.Sqrt(variance / float64(len(returns)-1))
+	downsideDev := math.Sqrt(downside / float64(len(returns)))
+
+	annualize := math.Sqrt(365)
+	var sharpe, sortino float64
+	if std > 0 {
+		sharpe = mean / std * annualize
+	}
+	if downsideDev > 0 {
+		sortino = mean / downsideDev * annualize
+	}
+	return sharpe, sortino
+}
+
+// exposure is the value of the open positions of an account at mark.
+type exposure struct {
+	unrealized   float64
+	long, short  float64
+	mainMarket   string
+	mainIsLong   bool
+	mainNotional float64
+}
+
+// valueExposure values the open positions of an account at mark, funding
+// included, the same way the api server values them.
+func valueExposure(positions []models.PerpPosition, markets map[string]models.Market, marks map[string]models.MarketPrice, fundingScale *big.Int) exposure {
+	var e exposure
+	for _, p := range positions {
+		market, ok := markets[p.Market]
+		mark, hasMark := marks[p.Market]
+		if !ok || !hasMark || p.Size == 0 {
+			continue
+		}
+		v := models.ValuePosition(market, p, market.Price(mark.MarkPx), mark.FundingIndex.BigInt(), fundingScale)
+		if p.IsLong {
+			e.long += v.Notional
+		} else {
+			e.short += v.Notional
+		}
+		e.unrealized += v.UnrealizedPnL()
+		if v.Notional > e.mainNotional {
+			e.mainMarket = p.Market
+			e.mainIsLong = p.IsLong
+			e.mainNotional = v.Notional
+		}
+	}
+	return e
+}
@@ -0,0 +1,130 @@
+package decibelindexer
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+)
+
+func trade(at time.Time, action types.Action, net int64, size uint64, isLong bool) models.Trade {
+	return models.Trade{
+		VersionTimestamp: at,
+		Market:           "0xm",
+		Action:           string(action),
+		IsProfit:         net >= 0,
+		RealizedPnl:      types.Uint64(max(net, -net)),
+		PositionSize:     types.Uint64(size),
+		PositionIsLong:   isLong,
+		Leverage:         10,
+	}
+}
+
+func TestClosedPositions(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	trades := []models.Trade{
+		// opened before indexing, closed at a loss: skipped
+		trade(t0, types.ActionCloseShort, -5, 0, false),
+		// long over two hours, closed in two steps
+		trade(t0.Add(time.Hour), types.ActionOpenLong, 0, 10, true),
+		trade(t0.Add(2*time.Hour), types.ActionCloseLong, 30, 4, true),
+		trade(t0.Add(3*time.Hour), types.ActionCloseLong, 20, 0, true),
+		// short flipped to a long by one trade
+		trade(t0.Add(4*time.Hour), types.ActionOpenShort, 0, 3, false),
+		trade(t0.Add(5*time.Hour), types.ActionNet, -10, 2, true),
+	}
+
+	closed := closedPositions(trades)
+	if len(closed) != 2 {
+		t.Fatalf("closed = %+v, want 2 positions", closed)
+	}
+	if closed[0].pnl != 50 || closed[0].closed.Sub(closed[0].opened) != 2*time.Hour {
+		t.Errorf("long = %+v, want 50 pnl over 2h", closed[0])
+	}
+	if closed[1].pnl != -10 || closed[1].closed.Sub(closed[1].opened) != time.Hour {
+		t.Errorf("flipped short = %+v, want -10 pnl over 1h", closed[1])
+	}
+}
+
+func TestAnalyzeTrader(t *testing.T) {
+	now := time.Date(2025, 1, 4, 12, 0, 0, 0, time.UTC)
+	d0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	balances := []models.AccountBalance{
+		{Hour: d0, Balance: 1_000_000_000},                     // deposit 1000
+		{Hour: d0.Add(24 * time.Hour), Balance: 1_100_000_000}, // +10%
+		{Hour: d0.Add(48 * time.Hour), Balance: 990_000_000},   // -10%
+		{Hour: d0.Add(72 * time.Hour), Balance: 1_089_000_000}, // +10%
+	}
+	pnl := []models.TraderPnl{
+		{Hour: d0.Add(24 * time.Hour), RealizedPnl: 100_000_000},
+		{Hour: d0.Add(48 * time.Hour), RealizedPnl: -110_000_000},
+		{Hour: d0.Add(72 * time.Hour), RealizedPnl: 99_000_000},
+	}
+	trades := []models.Trade{
+		trade(d0.Add(time.Hour), types.ActionOpenLong, 0, 10, true),
+		trade(d0.Add(24*time.Hour), types.ActionCloseLong, 100_000_000, 0, true),
+		trade(d0.Add(25*time.Hour), types.ActionOpenLong, 0, 10, true),
+		trade(d0.Add(48*time.Hour), types.ActionCloseLong, -110_000_000, 0, true),
+	}
+
+	a := analyzeTrader("0xa", trades, pnl, balances, now)
+	almost := func(got, want float64) bool { return math.Abs(got-want) < 1e-6 }
+	if !almost(a.Equity, 1089) || !almost(a.AllTimePnl, 89) {
+		t.Errorf("equity/pnl = %v/%v, want 1089/89", a.Equity, a.AllTimePnl)
+	}
+	if !almost(a.DailyPnl, 99) || !almost(a.DailyRoi, 10) {
+		t.Errorf("daily = %v/%v%%, want 99/10%%", a.DailyPnl, a.DailyRoi)
+	}
+	// the first day has no return as there was no equity at its start
+	if !almost(a.AllTimeRoi, 8.9) || !almost(a.MaxDrawdown, 10) {
+		t.Errorf("roi/drawdown = %v/%v, want 8.9/10", a.AllTimeRoi, a.MaxDrawdown)
+	}
+	if a.ClosedPositions != 2 || !almost(a.WinRate, 50) || !almost(a.ProfitFactor, 100.0/110) {
+		t.Errorf("closed/win rate/profit factor = %d/%v/%v", a.ClosedPositions, a.WinRate, a.ProfitFactor)
+	}
+	if !almost(a.AvgWin, 100) || !almost(a.AvgLoss, 110) || !almost(a.AvgLeverage, 10) || !almost(a.AvgHoldingSeconds, 23*3600) {
+		t.Errorf("avg win/loss/leverage/holding = %v/%v/%v/%v", a.AvgWin, a.AvgLoss, a.AvgLeverage, a.AvgHoldingSeconds)
+	}
+	if a.Sharpe <= 0 || a.Sortino <= a.Sharpe {
+		t.Errorf("sharpe/sortino = %v/%v, want positive with sortino above sharpe", a.Sharpe, a.Sortino)
+	}
+}
+
+func TestValueExposure(t *testing.T) {
+	markets := map[string]models.Market{"0xm": {Address: "0xm", SizeDecimals: 8, PriceDecimals: 6}}
+	var mark models.MarketPrice
+	mark.MarkPx = 110_000_000
+	// 1e12 * 1e6 / 2e8 above the index of the positions, accruing 1 USDC on 2e8 size
+	if err := mark.FundingIndex.Scan("1000000005000000000"); err != nil {
+		t.Fatalf("failed to set funding index: %v", err)
+	}
+	position := func(isLong bool, size uint64) models.PerpPosition {
+		p := models.PerpPosition{Market: "0xm", IsLong: isLong, Size: types.Uint64(size)}
+		if err := p.EntryPxTimesSizeSum.Scan(fmt.Sprint(size * 100_000_000)); err != nil {
+			t.Fatalf("failed to set entry sum: %v", err)
+		}
+		if err := p.FundingIndexAtLastUpdate.Scan("1000000000000000000"); err != nil {
+			t.Fatalf("failed to set funding index: %v", err)
+		}
+		return p
+	}
+
+	// a 2 unit long and a 1 unit short entered at 100
+	e := valueExposure(
+		[]models.PerpPosition{position(true, 2_0000_0000), position(false, 1_0000_0000)},
+		markets, map[string]models.MarketPrice{"0xm": mark}, models.FundingIndexScale(0),
+	)
+	// the long gains 20 and owes 1, the short loses 10 and receives 0.5
+	if math.Abs(e.unrealized-(20-1-10+0.5)) > 1e-9 {
+		t.Errorf("unrealized = %v, want 9.5", e.unrealized)
+	}
+	if e.long != 220 || e.short != 110 {
+		t.Errorf("long/short = %v/%v, want 220/110", e.long, e.short)
+	}
+	if e.mainMarket != "0xm" || !e.mainIsLong || e.mainNotional != 220 {
+		t.Errorf("main = %s/%v/%v, want 0xm/long/220", e.mainMarket, e.mainIsLong, e.mainNotional)
+	}
+}
@@ -2,12 +2,19 @@ package decibelindexer
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"sync"
+	"time"
 
 	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
 	"github.com/cresendoo/decidash-backend/pkg/fullnode"
+	"github.com/cresendoo/decidash-backend/pkg/metrics"
+	"github.com/cresendoo/decidash-backend/pkg/xredis"
+	"github.com/cresendoo/decidash-backend/pkg/xtrace"
 	"github.com/mediocregopher/radix/v3"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -15,17 +22,42 @@ import (
 
 type Application struct {
 	ctx    context.Context
+	cfg    *Config
 	pool   *radix.Pool
 	logger *slog.Logger
 
-	db      *gorm.DB
-	fetcher *fullnode.FullnodeFetcher
-	stream  *fullnode.FullnodeRpcStream
+	db       *gorm.DB
+	fetcher  *fullnode.FullnodeFetcher
+	stream   fullnode.Stream
+	recorder *fullnode.Recorder
+
+	processors *registry
+	enabled    []Processor
+	states     map[string]models.IndexerState
+	jobs       []job
+
+	health       health
+	healthServer *http.Server
+
+	shutdownTracing func(context.Context) error
+
+	// quit is closed by Close to stop the pipeline after the batch in
+	// flight; done is closed once the pipeline stopped and err is what
+	// stopped it on its own.
+	quit     chan struct{}
+	quitOnce sync.Once
+	done     chan struct{}
+	err      error
 
 	wg sync.WaitGroup
 }
 
 func NewApplication(ctx context.Context, logger *slog.Logger, cfg *Config) (*Application, error) {
+	shutdownTracing, err := xtrace.Init(ctx, "decibel-indexer", cfg.Trace)
+	if err != nil {
+		return nil, err
+	}
+
 	fetcher, err := fullnode.NewFullnodeRpcClient(
 		"https://api.netna.staging.aptoslabs.com/v1",
 		"",
@@ -38,53 +70,261 @@ func NewApplication(ctx context.Context, logger *slog.Logger, cfg *Config) (*App
 	if err != nil {
 		return nil, err
 	}
+	if err := metrics.InstrumentDB(db); err != nil {
+		return nil, err
+	}
+	if err := xtrace.InstrumentDB(db); err != nil {
+		return nil, err
+	}
+
+	if err := migrate(db); err != nil {
+		return nil, err
+	}
+	if err := models.UpsertMarkets(db, cfg.Markets); err != nil {
+		return nil, err
+	}
 
-	err = db.AutoMigrate(&models.IndexerState{}, &models.PerpPosition{})
+	pool, err := xredis.NewRedisPool(cfg.Redis.Addr, cfg.Redis.Pool, cfg.Redis.DB, "")
 	if err != nil {
 		return nil, err
 	}
 
-	return &Application{ctx: ctx, logger: logger, fetcher: fetcher, db: db}, nil
+	processors, err := newProcessors(db, pool, cfg, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var recorder *fullnode.Recorder
+	if cfg.Record != "" {
+		if recorder, err = fullnode.NewRecorder(cfg.Record, 10000); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Application{
+		ctx:      ctx,
+		cfg:      cfg,
+		logger:   logger,
+		fetcher:  fetcher,
+		recorder: recorder,
+
+		shutdownTracing: shutdownTracing,
+		quit:            make(chan struct{}),
+		db:              db,
+		pool:            pool,
+		processors:      processors,
+		jobs:            []job{newAnalyticsJob(db, models.FundingIndexScale(cfg.Funding.IndexDecimals)), newLeaderboardJob(db)},
+		enabled:         processors.Enabled(cfg.Processors),
+		states:          make(map[string]models.IndexerState),
+	}, nil
+}
+
+// newProcessors builds the registry of every processor. Processors without a
+// pool write to the database only and publish nothing. Processors built for
+// a backfill do not record the open interest, which sums the current
+// positions and would stamp them on the past versions backfilled.
+func newProcessors(db *gorm.DB, pool *radix.Pool, cfg *Config, backfill bool) (*registry, error) {
+	positions := newPositionProcessor(db)
+	positions.skipOpenInterest = backfill
+	return newRegistry(
+		positions,
+		newPriceProcessor(db, pool),
+		newFundingProcessor(db),
+		newLiquidationProcessor(db, pool, cfg.BackstopLiquidator),
+		newFeeProcessor(db),
+		newTradeProcessor(db, pool),
+		newBalanceProcessor(db),
+	)
 }
 
 func (a *Application) Start() error {
-	state, err := models.GetIndexerState(a.db, "decibel-indexer")
-	if err != nil {
-		return err
+	if len(a.enabled) == 0 {
+		return errors.New("no processor enabled")
+	}
+	var start uint64
+	for i, p := range a.enabled {
+		state, ok, err := a.checkpoint(p.Name())
+		if err != nil {
+			return err
+		}
+		if !ok && p.Name() == positionProcessorName && a.cfg.Bootstrap.Enabled {
+			if state, err = a.bootstrap(); err != nil {
+				return err
+			}
+		}
+		a.states[p.Name()] = state
+		a.health.observe(state, 0, time.Now())
+		if i == 0 || state.LastProcessedVersion < start {
+			start = state.LastProcessedVersion
+		}
 	}
 
-	a.stream, err = a.fetcher.NewStream(state.LastProcessedVersion, 100)
+	var err error
+	a.stream, err = a.newStream(start, transactionFilter(a.enabled...))
 	if err != nil {
 		return err
 	}
 
-	a.wg.Add(1)
+	if a.cfg.Health.Port != "" {
+		a.startHealth()
+	}
+
+	a.startJobs()
+
+	a.health.setStreaming(true, nil)
+	a.done = make(chan struct{})
 	go func() {
-		defer a.wg.Done()
-		slog.Info("started streaming transactions", "start", state.LastProcessedVersion)
-		for {
-			txs, err := a.stream.Recv()
-			if err != nil {
-				if err == io.EOF {
-					return
-				}
-				slog.Error("failed to receive transactions", "error", err)
-				return
-			}
-			if err := a.Process(txs); err != nil {
-				slog.Error("failed to process transactions", "error", err)
-				return
-			}
-		}
+		defer close(a.done)
+		slog.Info("started streaming transactions", "start", start, "processors", len(a.enabled))
+		a.err = a.pipeline()
+		a.health.setStreaming(false, a.err)
 	}()
 
 	return nil
 }
 
+// pipeline processes batches until Close is called, the stream ends or a
+// batch fails. A batch that was received is always processed and
+// checkpointed before it stops, so nothing is lost or indexed twice.
+func (a *Application) pipeline() error {
+	for {
+		select {
+		case <-a.quit:
+			return nil
+		default:
+		}
+		batch, err := a.stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			slog.Error("failed to receive transactions", "error", err)
+			return err
+		}
+		if err := a.Process(batch); err != nil {
+			slog.Error("failed to process transactions", "error", err)
+			return err
+		}
+	}
+}
+
+// Done is closed once the pipeline started by Start stops, either after
+// Close or on its own.
+func (a *Application) Done() <-chan struct{} {
+	return a.done
+}
+
+// Err returns the error that stopped the pipeline, or nil if it was closed
+// or ran out of transactions.
+func (a *Application) Err() error {
+	select {
+	case <-a.done:
+		return a.err
+	default:
+		return nil
+	}
+}
+
+func migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(
+		&models.IndexerState{},
+		&models.PerpPosition{},
+		&models.PositionTrigger{},
+		&models.Market{},
+		&models.MarketPrice{},
+		&models.MarketFunding{},
+		&models.FundingPayment{},
+		&models.Liquidation{},
+		&models.FeeDistribution{},
+		&models.FeeRevenue{},
+		&models.Trade{},
+		&models.Candle{},
+		&models.TraderPnl{},
+		&models.BalanceChange{},
+		&models.AccountBalance{},
+		&models.TraderAnalytics{},
+		&models.LeaderboardEntry{},
+		&models.OpenInterest{},
+	); err != nil {
+		return err
+	}
+	return models.NormalizeAccounts(db)
+}
+
+// newStream streams the transactions from start, from the replay files when
+// configured and from the node otherwise.
+func (a *Application) newStream(start uint64, filter fullnode.TransactionFilter) (fullnode.Stream, error) {
+	options := []fullnode.StreamOption{fullnode.WithFilter(filter)}
+	if a.cfg.Replay != "" {
+		files, err := fullnode.RecordFiles(a.cfg.Replay)
+		if err != nil {
+			return nil, err
+		}
+		return fullnode.NewFileStream(files, start, 100, options...)
+	}
+	if a.recorder != nil {
+		options = append(options, fullnode.WithRecorder(a.recorder))
+	}
+	switch {
+	case a.cfg.VerifyNode != "":
+		verifier, err := fullnode.NewFullnodeRpcClient(a.cfg.VerifyNode, "")
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, fullnode.WithVerifyNode(verifier))
+	case a.cfg.VerifyAccumulator:
+		options = append(options, fullnode.WithVerifyAccumulator())
+	}
+	return a.fetcher.NewStream(start, 100, options...)
+}
+
+// Close stops fetching, waits up to the shutdown timeout for the batch in
+// flight to be processed and checkpointed, then releases the connections.
 func (a *Application) Close() error {
+	var closeErr error
+	if a.quit != nil {
+		a.quitOnce.Do(func() { close(a.quit) })
+	}
+	a.stopHealth()
 	if a.stream != nil {
 		a.stream.Close()
 	}
+	if a.done != nil {
+		timeout := a.cfg.ShutdownTimeout
+		if timeout <= 0 {
+			timeout = defaultShutdownTimeout
+		}
+		select {
+		case <-a.done:
+		case <-time.After(timeout):
+			closeErr = fmt.Errorf("pipeline did not drain within %s", timeout)
+			slog.Error("failed to drain pipeline", "error", closeErr)
+		}
+	}
 	a.wg.Wait()
-	return nil
+	if a.recorder != nil {
+		if err := a.recorder.Close(); err != nil {
+			slog.Error("failed to close recorder", "error", err)
+		}
+	}
+	if a.shutdownTracing != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := a.shutdownTracing(ctx); err != nil {
+			slog.Warn("failed to flush traces", "error", err)
+		}
+	}
+	if a.db != nil {
+		if sqlDB, err := a.db.DB(); err == nil {
+			if err := sqlDB.Close(); err != nil {
+				slog.Warn("failed to close database", "error", err)
+			}
+		}
+	}
+	if a.pool != nil {
+		if err := a.pool.Close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	return closeErr
 }
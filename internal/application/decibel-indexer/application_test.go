@@ -0,0 +1,80 @@
+package decibelindexer
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/pkg/fullnode"
+)
+
+// stubStream returns err from Recv until Close is called, then io.EOF.
+type stubStream struct {
+	err    error
+	closed chan struct{}
+}
+
+func (s *stubStream) Recv() (fullnode.Batch, error) {
+	select {
+	case <-s.closed:
+		return fullnode.Batch{}, io.EOF
+	default:
+	}
+	if s.err != nil {
+		return fullnode.Batch{}, s.err
+	}
+	<-s.closed
+	return fullnode.Batch{}, io.EOF
+}
+
+func (s *stubStream) Close() {
+	close(s.closed)
+}
+
+func startPipeline(a *Application) {
+	a.quit = make(chan struct{})
+	a.done = make(chan struct{})
+	go func() {
+		defer close(a.done)
+		a.err = a.pipeline()
+	}()
+}
+
+func TestPipelineStopsOnError(t *testing.T) {
+	want := errors.New("fullnode unreachable")
+	app := &Application{cfg: &Config{}, stream: &stubStream{err: want, closed: make(chan struct{})}}
+	startPipeline(app)
+
+	select {
+	case <-app.Done():
+	case <-time.After(time.Second):
+		t.Fatal("pipeline did not stop")
+	}
+	if !errors.Is(app.Err(), want) {
+		t.Fatalf("Err() = %v, want %v", app.Err(), want)
+	}
+	if err := app.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+}
+
+func TestCloseDrainsPipeline(t *testing.T) {
+	app := &Application{cfg: &Config{ShutdownTimeout: time.Second}, stream: &stubStream{closed: make(chan struct{})}}
+	startPipeline(app)
+
+	if app.Err() != nil {
+		t.Fatalf("Err() = %v before the pipeline stopped", app.Err())
+	}
+	if err := app.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	select {
+	case <-app.Done():
+	default:
+		t.Fatal("Close returned before the pipeline stopped")
+	}
+	if app.Err() != nil {
+		t.Fatalf("Err() = %v after Close", app.Err())
+	}
+}
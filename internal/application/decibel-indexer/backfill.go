@@ -0,0 +1,127 @@
+package decibelindexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/aptos-labs/aptos-go-sdk/api"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+)
+
+// Processors returns the names of the registered processors.
+func (a *Application) Processors() []string {
+	return a.processors.Names()
+}
+
+func (a *Application) processor(name string) (Processor, error) {
+	p, ok := a.processors.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown processor %q, available: %s", name, strings.Join(a.processors.Names(), ", "))
+	}
+	return p, nil
+}
+
+// Backfill replays the versions [from, to] into a single processor. The
+// processor's checkpoint is left untouched, so it can run next to the live
+// indexer. The processor is built without a pool: historical liquidations,
+// candles and prices are not published on the live channels. Nor does it
+// record the open interest, which only the live indexer keeps.
+func (a *Application) Backfill(name string, from, to uint64) error {
+	if _, err := a.processor(name); err != nil {
+		return err
+	}
+	if to < from {
+		return fmt.Errorf("invalid range %d..%d", from, to)
+	}
+	processors, err := newProcessors(a.db, nil, a.cfg, true)
+	if err != nil {
+		return err
+	}
+	p, _ := processors.Get(name)
+
+	// the stream never scans past the head, so stop there
+	if a.cfg.Replay == "" {
+		info, err := a.fetcher.GetLedgerInfo()
+		if err != nil {
+			return err
+		}
+		if to > info.LedgerVersion {
+			slog.Warn("backfill ends past the chain head", "to", to, "head", info.LedgerVersion)
+			to = info.LedgerVersion
+		}
+	}
+
+	stream, err := a.newStream(from, transactionFilter(p))
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	stop := context.AfterFunc(a.ctx, stream.Close)
+	defer stop()
+
+	slog.Info("started backfill", "processor", name, "from", from, "to", to)
+	var processed int
+	for {
+		next, err := stream.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+			if a.ctx.Err() != nil {
+				return a.ctx.Err()
+			}
+			// the replay files ended before to
+			slog.Info("finished backfill at the end of the replay files", "processor", name, "from", from, "processed", processed)
+			return nil
+		}
+		batch := make([]*api.UserTransaction, 0, len(next.Transactions))
+		for _, tx := range next.Transactions {
+			if tx.Version <= to {
+				batch = append(batch, tx)
+			}
+		}
+		if pending := pendingTransactions(p, batch, from); len(pending) > 0 {
+			if err := p.Process(a.ctx, pending); err != nil {
+				return fmt.Errorf("processor %s: %w", name, err)
+			}
+			processed += len(pending)
+		}
+		if len(batch) > 0 {
+			slog.Info("backfilled transactions", "processor", name, "end", batch[len(batch)-1].Version, "to", to, "processed", processed)
+		}
+		if next.Version >= to {
+			slog.Info("finished backfill", "processor", name, "from", from, "to", to, "processed", processed)
+			return nil
+		}
+	}
+}
+
+// ResetProcessor rewinds the checkpoint of a processor so the live indexer
+// replays it from version on. The checkpoint never moves forward, which
+// would skip versions, and its timestamp is cleared until the next batch.
+// Run it while the indexer is stopped; a running indexer would write its
+// own checkpoint back.
+func (a *Application) ResetProcessor(name string, version uint64) error {
+	if _, err := a.processor(name); err != nil {
+		return err
+	}
+	state, _, err := a.checkpoint(name)
+	if err != nil {
+		return err
+	}
+	if version > state.LastProcessedVersion {
+		return fmt.Errorf("processor %s is at version %d, refusing to move it forward to %d", name, state.LastProcessedVersion, version)
+	}
+	if err := models.SetIndexerState(a.db, models.IndexerState{
+		ProcessorName:        name,
+		LastProcessedVersion: version,
+	}); err != nil {
+		return err
+	}
+	slog.Info("reset processor", "processor", name, "from", state.LastProcessedVersion, "to", version)
+	return nil
+}
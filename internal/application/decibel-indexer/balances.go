@@ -0,0 +1,75 @@
+package decibelindexer
+
+import (
+	"context"
+	"time"
+
+	"github.com/aptos-labs/aptos-go-sdk/api"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+)
+
+// balanceProcessor indexes collateral balance changes and the hourly
+// closing balance of every balance sheet, from which trader equity is
+// charted.
+type balanceProcessor struct {
+	db *gorm.DB
+}
+
+func newBalanceProcessor(db *gorm.DB) *balanceProcessor {
+	return &balanceProcessor{db: db}
+}
+
+func (proc *balanceProcessor) Name() string {
+	return "balances"
+}
+
+func (proc *balanceProcessor) ResourceTypes() []string {
+	return nil
+}
+
+func (proc *balanceProcessor) EventTypes() []string {
+	return []string{balanceChangeEvent}
+}
+
+func (proc *balanceProcessor) Process(ctx context.Context, txs []*api.UserTransaction) error {
+	var changes []models.BalanceChange
+	for _, tx := range txs {
+		rows, err := extractBalanceChanges(tx)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, rows...)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := models.InsertBalanceChanges(proc.db.WithContext(ctx), changes); err != nil {
+		return err
+	}
+	from := changes[0].VersionTimestamp
+	to := changes[len(changes)-1].VersionTimestamp
+	return models.RefreshAccountBalances(proc.db.WithContext(ctx), from, to)
+}
+
+// extractBalanceChanges finds the collateral balance changes of a
+// transaction.
+func extractBalanceChanges(tx *api.UserTransaction) ([]models.BalanceChange, error) {
+	timestamp := time.UnixMicro(int64(tx.Timestamp))
+	var changes []models.BalanceChange
+	for _, event := range types.ExtractEvents(tx) {
+		if event.Type != balanceChangeEvent {
+			continue
+		}
+		var e types.CollateralBalanceChangeEvent
+		if err := MapToStructJSON(event.Data, &e); err != nil {
+			return nil, err
+		}
+		var change models.BalanceChange
+		change.FromCollateralBalanceChangeEvent(tx.Version, event.EventIndex, timestamp, e)
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
@@ -0,0 +1,125 @@
+package decibelindexer
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"github.com/cresendoo/decidash-backend/pkg/fullnode"
+)
+
+// bootstrap seeds the positions of the configured accounts from the node's
+// state at its latest ledger version and saves that version as the positions
+// checkpoint, so the positions processor tails from there instead of
+// replaying the chain.
+func (a *Application) bootstrap() (models.IndexerState, error) {
+	info, err := a.fetcher.GetLedgerInfo()
+	if err != nil {
+		return models.IndexerState{}, err
+	}
+	version := info.LedgerVersion
+	timestamp := time.UnixMicro(int64(info.LedgerTimestamp))
+
+	var positions []models.PerpPosition
+	for _, account := range a.cfg.Bootstrap.Accounts {
+		rows, err := a.accountPositions(account, version, timestamp)
+		if err != nil {
+			return models.IndexerState{}, fmt.Errorf("bootstrap %s: %w", account, err)
+		}
+		positions = append(positions, rows...)
+	}
+
+	if len(positions) > 0 {
+		sort.Slice(positions, func(i, j int) bool {
+			if positions[i].PositionAddress == positions[j].PositionAddress {
+				return positions[i].Market < positions[j].Market
+			}
+			return positions[i].PositionAddress < positions[j].PositionAddress
+		})
+		if err := models.UpsertPositions(a.db, positions); err != nil {
+			return models.IndexerState{}, err
+		}
+		var triggers []models.PositionTrigger
+		for _, p := range positions {
+			triggers = append(triggers, models.TriggersFromPosition(p, nil)...)
+		}
+		if err := models.ReplacePositionTriggers(a.db, positions, triggers); err != nil {
+			return models.IndexerState{}, err
+		}
+	}
+
+	state := models.IndexerState{
+		ProcessorName:          positionProcessorName,
+		LastProcessedVersion:   version,
+		LastProcessedTimestamp: timestamp,
+	}
+	if err := models.UpsertIndexerState(a.db, state); err != nil {
+		return models.IndexerState{}, err
+	}
+	slog.Info("bootstrapped positions", "version", version, "accounts", len(a.cfg.Bootstrap.Accounts), "positions", len(positions))
+	return state, nil
+}
+
+// accountPositions reads the crossed position of an account and the isolated
+// positions it references.
+func (a *Application) accountPositions(account string, version uint64, timestamp time.Time) ([]models.PerpPosition, error) {
+	address := types.NormalizeAddress(account)
+	if address == "" {
+		return nil, fmt.Errorf("invalid account address %q", account)
+	}
+
+	var positions []models.PerpPosition
+	resource, err := a.fetcher.GetAccountResource(address, crossedPosition, version)
+	switch {
+	case errors.Is(err, fullnode.ErrNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		var crossed types.CrossedPosition
+		if err := MapToStructJSON(resource.Data, &crossed); err != nil {
+			return nil, err
+		}
+		for _, position := range crossed.Positions {
+			var row models.PerpPosition
+			row.FromPerpPosition(address, version, timestamp, address, true, position)
+			positions = append(positions, row)
+		}
+	}
+
+	resource, err = a.fetcher.GetAccountResource(address, isolatedPositionRefs, version)
+	if errors.Is(err, fullnode.ErrNotFound) {
+		return positions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var refs types.IsolatedPositionRefs
+	if err := MapToStructJSON(resource.Data, &refs); err != nil {
+		return nil, err
+	}
+	for _, entry := range refs.ExtendRefs.Vec {
+		positionAddress := types.NormalizeAddress(entry.Value.Inner)
+		if positionAddress == "" {
+			continue
+		}
+		resource, err := a.fetcher.GetAccountResource(positionAddress, isolatedPosition, version)
+		if errors.Is(err, fullnode.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var isolated types.IsolatedPosition
+		if err := MapToStructJSON(resource.Data, &isolated); err != nil {
+			return nil, err
+		}
+		var row models.PerpPosition
+		row.FromPerpPosition(positionAddress, version, timestamp, address, false, isolated.Position)
+		positions = append(positions, row)
+	}
+	return positions, nil
+}
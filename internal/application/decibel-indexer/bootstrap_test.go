@@ -0,0 +1,98 @@
+package decibelindexer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"github.com/cresendoo/decidash-backend/pkg/fullnode"
+)
+
+func TestAccountPositions(t *testing.T) {
+	tx := loadTransaction(t)
+
+	// serve the resources written by the fixture as the node's account resources
+	raw, err := os.ReadFile("types/testdata/tx_32667225.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var fixture struct {
+		Changes []struct {
+			Address string          `json:"address"`
+			Data    json.RawMessage `json:"data"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal(raw, &fixture); err != nil {
+		t.Fatalf("failed to unmarshal fixture: %v", err)
+	}
+	resources := make(map[string]json.RawMessage)
+	for _, change := range fixture.Changes {
+		var resource fullnode.AccountResource
+		if err := json.Unmarshal(change.Data, &resource); err == nil && resource.Type != "" {
+			resources["/v1/accounts/"+types.NormalizeAddress(change.Address)+"/resource/"+resource.Type] = change.Data
+		}
+	}
+
+	const (
+		account          = "0x47182c30c91a9d43bd6e528b25af98d032f1494b8c5c19c869a997f056d19ec5"
+		isolatedAccount  = "0x57bf3e3938f00f4fc079f67e3af5caa3a3fe7d1942a23253ecd3ad958ae9e6b7"
+		isolatedPosAddr  = "0xf0c5d220b92d673ea2d007587aa55e3ecd74d932515e29112772311d5441c600"
+		isolatedRefsPath = "/v1/accounts/" + isolatedAccount + "/resource/" + isolatedPositionRefs
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ledger_version") != "32667225" {
+			t.Errorf("resource read at %q, want the bootstrap version", r.URL.Query().Get("ledger_version"))
+		}
+		if r.URL.Path == isolatedRefsPath {
+			w.Write([]byte(`{"type":"` + isolatedPositionRefs + `","data":{"extend_refs":{"vec":[{"key":{"inner":"0xe6"},"value":{"inner":"` + isolatedPosAddr + `"}}]}}}`))
+			return
+		}
+		resource, ok := resources[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(resource)
+	}))
+	defer server.Close()
+
+	fetcher, err := fullnode.NewFullnodeRpcClient(server.URL+"/v1", "")
+	if err != nil {
+		t.Fatalf("failed to create fetcher: %v", err)
+	}
+	app := &Application{fetcher: fetcher}
+	timestamp := time.UnixMicro(int64(tx.Timestamp))
+
+	crossed, err := app.accountPositions(account, tx.Version, timestamp)
+	if err != nil {
+		t.Fatalf("accountPositions: %v", err)
+	}
+	if len(crossed) == 0 {
+		t.Fatal("expected crossed positions")
+	}
+	for _, p := range crossed {
+		if !p.IsCrossed || p.PositionAddress != account || p.Owner != account || p.Version != tx.Version {
+			t.Errorf("unexpected crossed position: %+v", p)
+		}
+	}
+
+	isolated, err := app.accountPositions(isolatedAccount, tx.Version, timestamp)
+	if err != nil {
+		t.Fatalf("accountPositions: %v", err)
+	}
+	if len(isolated) != 1 {
+		t.Fatalf("expected 1 isolated position, got %d", len(isolated))
+	}
+	if p := isolated[0]; p.IsCrossed || p.PositionAddress != isolatedPosAddr || p.Owner != isolatedAccount {
+		t.Errorf("unexpected isolated position: %+v", p)
+	}
+
+	if _, err := app.accountPositions("not an address", tx.Version, timestamp); err == nil || !strings.Contains(err.Error(), "invalid account") {
+		t.Errorf("expected an invalid address error, got %v", err)
+	}
+}
@@ -2,9 +2,12 @@ package decibelindexer
 
 import (
 	"log/slog"
+	"time"
 
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
 	"github.com/cresendoo/decidash-backend/pkg/config"
 	"github.com/cresendoo/decidash-backend/pkg/utils"
+	"github.com/cresendoo/decidash-backend/pkg/xtrace"
 )
 
 type Config struct {
@@ -16,12 +19,72 @@ type Config struct {
 
 	SentryDSN string `yaml:"sentry_dsn"`
 
+	Trace xtrace.Config `yaml:"trace"`
+
 	DB    string `yaml:"db"`
 	Redis struct {
 		Addr string `yaml:"addr"`
 		Pool int    `yaml:"pool"`
 		DB   int    `yaml:"db"`
 	} `yaml:"redis"`
+
+	// ShutdownTimeout bounds how long Close waits for the batch in flight
+	// (default 30s).
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// Health serves /healthz, /readyz and /status on Port. The indexer is
+	// not ready while a processor lags more than MaxLag behind the chain
+	// head (default 1m).
+	Health struct {
+		Port   string        `yaml:"port"`
+		MaxLag time.Duration `yaml:"max_lag"`
+	} `yaml:"health"`
+
+	Markets []models.Market `yaml:"markets"`
+
+	// StartVersion is where processors without a checkpoint start, e.g. the
+	// version the decibel package was deployed at.
+	StartVersion uint64 `yaml:"start_version"`
+
+	// Bootstrap seeds the positions of Accounts from the node's current state
+	// before the positions processor indexes its first transaction.
+	Bootstrap struct {
+		Enabled  bool     `yaml:"enabled"`
+		Accounts []string `yaml:"accounts"`
+	} `yaml:"bootstrap"`
+
+	// BackstopLiquidator is the account of the vault that takes over
+	// positions the order book could not absorb during liquidation.
+	BackstopLiquidator string `yaml:"backstop_liquidator"`
+
+	// Funding.IndexDecimals is the decimals of the scale of the
+	// perp_positions funding index the analytics value open positions with
+	// (default 12).
+	Funding struct {
+		IndexDecimals int `yaml:"index_decimals"`
+	} `yaml:"funding"`
+
+	// VerifyAccumulator checks every fetched page against the node's
+	// accumulator and refetches pages from a rolled back ledger. VerifyNode
+	// is the URL of a second node to check against instead, e.g.
+	// https://api.testnet.aptoslabs.com/v1.
+	VerifyAccumulator bool   `yaml:"verify_accumulator"`
+	VerifyNode        string `yaml:"verify_node"`
+
+	// Record dumps the streamed transactions into compressed JSONL files in
+	// this directory. Replay streams the transactions of such a directory
+	// instead of the node, e.g. to reproduce an incident locally.
+	Record string `yaml:"record"`
+	Replay string `yaml:"replay"`
+
+	// Processors switches processors on or off by name, e.g. fees: false.
+	// Processors not listed are enabled.
+	Processors map[string]bool `yaml:"processors"`
+
+	// Jobs overrides how often scheduled jobs run by name, e.g. analytics:
+	// 5m. Runs are aligned to multiples of the interval, and the leaderboard
+	// runs after the analytics. A zero or negative interval disables the job.
+	Jobs map[string]time.Duration `yaml:"jobs"`
 }
 
 func (c *Config) Load() error {
@@ -0,0 +1,109 @@
+package decibelindexer
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aptos-labs/aptos-go-sdk/api"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+)
+
+// feeProcessor indexes fee distributions and the daily revenue aggregated
+// from them.
+type feeProcessor struct {
+	db *gorm.DB
+}
+
+func newFeeProcessor(db *gorm.DB) *feeProcessor {
+	return &feeProcessor{db: db}
+}
+
+func (proc *feeProcessor) Name() string {
+	return "fees"
+}
+
+func (proc *feeProcessor) ResourceTypes() []string {
+	return nil
+}
+
+func (proc *feeProcessor) EventTypes() []string {
+	return []string{decibelContract + "::"}
+}
+
+func (proc *feeProcessor) Process(ctx context.Context, txs []*api.UserTransaction) error {
+	var distributions []models.FeeDistribution
+	for _, tx := range txs {
+		rows, err := extractFeeDistributions(tx)
+		if err != nil {
+			return err
+		}
+		distributions = append(distributions, rows...)
+	}
+	if len(distributions) == 0 {
+		return nil
+	}
+
+	// revenue only adds distributions that were not indexed before, so both
+	// are written together
+	return proc.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		fresh, err := models.InsertFeeDistributions(tx, distributions)
+		if err != nil {
+			return err
+		}
+		return models.MergeFeeRevenue(tx, fresh)
+	})
+}
+
+// extractFeeDistributions finds the fee distributions of a transaction. A
+// distribution is either the data of an event or its fee_distribution field;
+// when the event does not name the market, the market of the trade it
+// follows is used.
+func extractFeeDistributions(tx *api.UserTransaction) ([]models.FeeDistribution, error) {
+	timestamp := time.UnixMicro(int64(tx.Timestamp))
+	var tradeMarket string
+	var distributions []models.FeeDistribution
+	for _, event := range types.ExtractEvents(tx) {
+		if !strings.HasPrefix(event.Type, decibelContract+"::") {
+			continue
+		}
+		if event.Type == tradeEvent {
+			var trade types.TradeEvent
+			if err := MapToStructJSON(event.Data, &trade); err != nil {
+				return nil, err
+			}
+			tradeMarket = trade.Market.Inner
+			continue
+		}
+
+		data := event.Data
+		if nested, ok := data["fee_distribution"].(map[string]any); ok {
+			data = nested
+		} else if _, ok := data["treasury_fee_delta"]; !ok {
+			continue
+		}
+		var fee types.FeeDistribution
+		if err := MapToStructJSON(data, &fee); err != nil {
+			return nil, err
+		}
+
+		market := tradeMarket
+		if m, ok := event.Data["market"].(map[string]any); ok {
+			if inner, ok := m["inner"].(string); ok {
+				market = inner
+			}
+		}
+		if market == "" {
+			slog.Warn("fee distribution without market", "version", tx.Version, "event_index", event.EventIndex)
+			continue
+		}
+
+		var row models.FeeDistribution
+		row.FromFeeDistribution(tx.Version, event.EventIndex, timestamp, market, fee)
+		distributions = append(distributions, row)
+	}
+	return distributions, nil
+}
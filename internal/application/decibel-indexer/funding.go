@@ -0,0 +1,139 @@
+package decibelindexer
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aptos-labs/aptos-go-sdk/api"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+)
+
+// fundingProcessor indexes hourly funding index buckets and the funding
+// realized by trades.
+type fundingProcessor struct {
+	db *gorm.DB
+}
+
+func newFundingProcessor(db *gorm.DB) *fundingProcessor {
+	return &fundingProcessor{db: db}
+}
+
+func (proc *fundingProcessor) Name() string {
+	return "funding"
+}
+
+func (proc *fundingProcessor) ResourceTypes() []string {
+	return []string{marketPrice}
+}
+
+func (proc *fundingProcessor) EventTypes() []string {
+	return []string{positionUpdateEvent, tradeEvent}
+}
+
+func (proc *fundingProcessor) Process(ctx context.Context, txs []*api.UserTransaction) error {
+	type bucketKey struct {
+		market string
+		hour   time.Time
+	}
+	buckets := make(map[bucketKey]models.MarketFunding)
+	observe := func(market string, version uint64, timestamp time.Time, index types.Uint128, markPx types.Uint64) {
+		key := bucketKey{market: market, hour: timestamp.UTC().Truncate(time.Hour)}
+		bucket, ok := buckets[key]
+		if !ok {
+			bucket = models.MarketFunding{
+				Market:      market,
+				Hour:        key.hour,
+				OpenVersion: version,
+				OpenIndex:   index,
+			}
+		}
+		bucket.CloseVersion = version
+		bucket.CloseIndex = index
+		if markPx > 0 {
+			bucket.MarkPx = markPx
+		}
+		buckets[key] = bucket
+	}
+
+	var payments []models.FundingPayment
+	for _, tx := range txs {
+		timestamp := time.UnixMicro(int64(tx.Timestamp))
+		indexes := make(map[string]types.Uint128)
+		tradePx := make(map[string]types.Uint64)
+
+		for _, event := range types.ExtractEvents(tx) {
+			switch event.Type {
+			case positionUpdateEvent:
+				var update types.PositionUpdateEvent
+				if err := MapToStructJSON(event.Data, &update); err != nil {
+					return err
+				}
+				indexes[update.Market.Inner] = update.FundingIndexAtLastUpdate
+			case tradeEvent:
+				var trade types.TradeEvent
+				if err := MapToStructJSON(event.Data, &trade); err != nil {
+					return err
+				}
+				tradePx[trade.Market.Inner] = trade.Price
+				if trade.RealizedFundingAmount == 0 {
+					continue
+				}
+				amount := int64(trade.RealizedFundingAmount)
+				if !trade.IsFundingPositive {
+					amount = -amount
+				}
+				payments = append(payments, models.FundingPayment{
+					Version:          tx.Version,
+					EventIndex:       event.EventIndex,
+					VersionTimestamp: timestamp,
+					Account:          types.NormalizeAddress(trade.Account),
+					Market:           trade.Market.Inner,
+					Amount:           amount,
+				})
+			}
+		}
+
+		_, writeResources, _, _ := types.ExtractWriteSetChange(tx)
+		for _, writeResource := range writeResources {
+			if writeResource.Data.Type != marketPrice {
+				continue
+			}
+			var price types.Price
+			if err := MapToStructJSON(writeResource.Data.Data, &price); err != nil {
+				return err
+			}
+			market := writeResource.Address.StringLong()
+			indexes[market] = price.AccumulativeIndex.Index
+			tradePx[market] = price.MarkPx
+		}
+
+		for market, index := range indexes {
+			observe(market, tx.Version, timestamp, index, tradePx[market])
+		}
+	}
+
+	if len(buckets) > 0 {
+		fundings := make([]models.MarketFunding, 0, len(buckets))
+		for _, b := range buckets {
+			fundings = append(fundings, b)
+		}
+		sort.Slice(fundings, func(i, j int) bool {
+			if fundings[i].Market == fundings[j].Market {
+				return fundings[i].Hour.Before(fundings[j].Hour)
+			}
+			return fundings[i].Market < fundings[j].Market
+		})
+		if err := models.UpsertMarketFundings(proc.db.WithContext(ctx), fundings); err != nil {
+			return err
+		}
+	}
+	if len(payments) > 0 {
+		if err := models.InsertFundingPayments(proc.db.WithContext(ctx), payments); err != nil {
+			return err
+		}
+	}
+	return nil
+}
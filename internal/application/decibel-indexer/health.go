@@ -0,0 +1,248 @@
+package decibelindexer
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/pkg/errorx"
+	"github.com/cresendoo/decidash-backend/pkg/fullnode"
+	"github.com/cresendoo/decidash-backend/pkg/metrics"
+)
+
+const (
+	defaultMaxLag = time.Minute
+	// defaultShutdownTimeout bounds how long Close waits for the pipeline.
+	defaultShutdownTimeout = 30 * time.Second
+	headPollInterval       = 5 * time.Second
+	throughputWindow       = time.Minute
+)
+
+// health tracks what the health endpoints report. The stream loop writes
+// it and the HTTP handlers read it.
+type health struct {
+	mu         sync.RWMutex
+	streaming  bool
+	streamErr  error
+	head       fullnode.LedgerInfo
+	processors map[string]*processorHealth
+}
+
+type processorHealth struct {
+	state     models.IndexerState
+	processed uint64
+	samples   []throughputSample
+}
+
+type throughputSample struct {
+	at    time.Time
+	count int
+}
+
+type indexerStatus struct {
+	Ready         bool              `json:"ready"`
+	Streaming     bool              `json:"streaming"`
+	Error         string            `json:"error,omitempty"`
+	HeadVersion   uint64            `json:"head_version"`
+	HeadTimestamp time.Time         `json:"head_timestamp"`
+	Processors    []processorStatus `json:"processors"`
+}
+
+type processorStatus struct {
+	Name                   string    `json:"name"`
+	LastProcessedVersion   uint64    `json:"last_processed_version"`
+	LastProcessedTimestamp time.Time `json:"last_processed_timestamp"`
+	LagVersions            uint64    `json:"lag_versions"`
+	LagSeconds             float64   `json:"lag_seconds"`
+	Processed              uint64    `json:"processed_transactions"`
+	// Throughput is the processed transactions per second over the last
+	// minute.
+	Throughput float64 `json:"throughput"`
+}
+
+func (h *health) setStreaming(streaming bool, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.streaming = streaming
+	h.streamErr = err
+}
+
+func (h *health) setHead(head fullnode.LedgerInfo) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.head = head
+}
+
+// observe records that a processor handled count transactions and moved its
+// checkpoint to state.
+func (h *health) observe(state models.IndexerState, count int, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.processors == nil {
+		h.processors = make(map[string]*processorHealth)
+	}
+	p, ok := h.processors[state.ProcessorName]
+	if !ok {
+		p = &processorHealth{}
+		h.processors[state.ProcessorName] = p
+	}
+	p.state = state
+	p.processed += uint64(count)
+	p.samples = append(p.samples, throughputSample{at: now, count: count})
+	for len(p.samples) > 0 && now.Sub(p.samples[0].at) > throughputWindow {
+		p.samples = p.samples[1:]
+	}
+}
+
+// status reports the processors in the given order. The indexer is ready
+// while it streams and no processor lags more than maxLag behind the chain
+// head. The lag is measured from the last scanned version and its time, so a
+// processor whose transactions are quiet is not reported behind.
+func (h *health) status(names []string, maxLag time.Duration, now time.Time) indexerStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	status := indexerStatus{
+		Streaming:   h.streaming,
+		HeadVersion: h.head.LedgerVersion,
+		Processors:  make([]processorStatus, 0, len(names)),
+	}
+	if h.streamErr != nil {
+		status.Error = h.streamErr.Error()
+	}
+	headTime := time.UnixMicro(int64(h.head.LedgerTimestamp))
+	if h.head.LedgerVersion > 0 {
+		status.HeadTimestamp = headTime
+	}
+	status.Ready = status.Streaming && h.head.LedgerVersion > 0
+
+	for _, name := range names {
+		ps := processorStatus{Name: name}
+		p, ok := h.processors[name]
+		if !ok {
+			status.Ready = false
+			status.Processors = append(status.Processors, ps)
+			continue
+		}
+		ps.LastProcessedVersion = p.state.LastProcessedVersion
+		ps.LastProcessedTimestamp = p.state.LastProcessedTimestamp
+		ps.Processed = p.processed
+		if h.head.LedgerVersion > ps.LastProcessedVersion {
+			ps.LagVersions = h.head.LedgerVersion - ps.LastProcessedVersion
+		}
+		if lag := headTime.Sub(ps.LastProcessedTimestamp); h.head.LedgerVersion > 0 && lag > 0 {
+			ps.LagSeconds = lag.Seconds()
+			if lag > maxLag {
+				status.Ready = false
+			}
+		}
+		var count int
+		for _, sample := range p.samples {
+			if now.Sub(sample.at) <= throughputWindow {
+				count += sample.count
+			}
+		}
+		ps.Throughput = float64(count) / throughputWindow.Seconds()
+		status.Processors = append(status.Processors, ps)
+	}
+	return status
+}
+
+func (a *Application) healthHandler() http.Handler {
+	maxLag := a.cfg.Health.MaxLag
+	if maxLag <= 0 {
+		maxLag = defaultMaxLag
+	}
+	status := func() indexerStatus {
+		return a.health.status(a.enabledNames(), maxLag, time.Now())
+	}
+	writeStatus := func(w http.ResponseWriter, ok bool, body any) {
+		w.Header().Set("Content-Type", "application/json")
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(body)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		s := status()
+		writeStatus(w, s.Streaming, map[string]any{"streaming": s.Streaming, "error": s.Error})
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		s := status()
+		writeStatus(w, s.Ready, map[string]any{"ready": s.Ready})
+	})
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		writeStatus(w, true, status())
+	})
+	mux.Handle("GET /metrics", metrics.Handler())
+	return mux
+}
+
+// startHealth serves the health endpoints and polls the chain head until
+// the application is closed.
+func (a *Application) startHealth() {
+	a.healthServer = &http.Server{
+		Addr:    ":" + a.cfg.Health.Port,
+		Handler: a.healthHandler(),
+	}
+	go func() {
+		slog.Info("listen health server", "port", a.healthServer.Addr)
+		if err := a.healthServer.ListenAndServe(); !errorx.Is(err, http.ErrServerClosed) {
+			slog.Error("failed to serve health", "error", err)
+		}
+	}()
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(headPollInterval)
+		defer ticker.Stop()
+		for {
+			if info, err := a.fetcher.GetLedgerInfo(); err != nil {
+				metrics.FetchErrors.WithLabelValues("fullnode").Inc()
+				slog.Warn("failed to get chain head", "error", err)
+			} else {
+				a.health.setHead(info)
+				a.observeLag()
+			}
+			select {
+			case <-a.quit:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (a *Application) enabledNames() []string {
+	names := make([]string, 0, len(a.enabled))
+	for _, p := range a.enabled {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+// observeLag exports the lag of every enabled processor.
+func (a *Application) observeLag() {
+	for _, p := range a.health.status(a.enabledNames(), 0, time.Now()).Processors {
+		metrics.IndexerLagVersions.WithLabelValues(p.Name).Set(float64(p.LagVersions))
+		metrics.IndexerLagSeconds.WithLabelValues(p.Name).Set(p.LagSeconds)
+	}
+}
+
+func (a *Application) stopHealth() {
+	if a.healthServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := a.healthServer.Shutdown(ctx); err != nil {
+		slog.Warn("failed to shutdown health server", "error", err)
+	}
+}
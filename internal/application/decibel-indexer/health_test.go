@@ -0,0 +1,81 @@
+package decibelindexer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/pkg/fullnode"
+)
+
+func TestHealthStatus(t *testing.T) {
+	now := time.Now().Truncate(time.Microsecond)
+	var h health
+	h.setStreaming(true, nil)
+	h.setHead(fullnode.LedgerInfo{LedgerVersion: 1000, LedgerTimestamp: uint64(now.UnixMicro())})
+	h.observe(models.IndexerState{ProcessorName: "positions", LastProcessedVersion: 990, LastProcessedTimestamp: now.Add(-10 * time.Second)}, 0, now.Add(-2*time.Minute))
+	h.observe(models.IndexerState{ProcessorName: "positions", LastProcessedVersion: 995, LastProcessedTimestamp: now.Add(-5 * time.Second)}, 30, now)
+	h.observe(models.IndexerState{ProcessorName: "funding", LastProcessedVersion: 900, LastProcessedTimestamp: now.Add(-2 * time.Minute)}, 6, now)
+
+	status := h.status([]string{"positions"}, time.Minute, now)
+	if !status.Ready {
+		t.Errorf("expected positions to be ready: %+v", status)
+	}
+	p := status.Processors[0]
+	if p.LagVersions != 5 || p.LagSeconds != 5 {
+		t.Errorf("lag = %d versions, %v seconds, want 5/5", p.LagVersions, p.LagSeconds)
+	}
+	if p.Processed != 30 || p.Throughput != 0.5 {
+		t.Errorf("processed = %d, throughput = %v, want 30/0.5", p.Processed, p.Throughput)
+	}
+
+	if status := h.status([]string{"positions", "funding"}, time.Minute, now); status.Ready {
+		t.Error("expected a lagging processor to fail readiness")
+	}
+	if status := h.status([]string{"positions", "fees"}, time.Minute, now); status.Ready {
+		t.Error("expected a processor without a checkpoint to fail readiness")
+	}
+
+	h.setStreaming(false, errors.New("stream closed"))
+	if status := h.status([]string{"positions"}, time.Minute, now); status.Ready || status.Error != "stream closed" {
+		t.Errorf("expected a stopped stream to fail readiness: %+v", status)
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	now := time.Now()
+	app := &Application{cfg: &Config{}, enabled: []Processor{newPositionProcessor(nil)}}
+	app.health.setHead(fullnode.LedgerInfo{LedgerVersion: 1000, LedgerTimestamp: uint64(now.UnixMicro())})
+	app.health.observe(models.IndexerState{ProcessorName: "positions", LastProcessedVersion: 1000, LastProcessedTimestamp: now}, 1, now)
+	handler := app.healthHandler()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	if w := get("/healthz"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("healthz = %d before streaming, want 503", w.Code)
+	}
+	app.health.setStreaming(true, nil)
+	if w := get("/healthz"); w.Code != http.StatusOK {
+		t.Errorf("healthz = %d, want 200", w.Code)
+	}
+	if w := get("/readyz"); w.Code != http.StatusOK {
+		t.Errorf("readyz = %d, want 200", w.Code)
+	}
+
+	w := get("/status")
+	var status indexerStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to decode status: %v", err)
+	}
+	if status.HeadVersion != 1000 || len(status.Processors) != 1 || status.Processors[0].LastProcessedVersion != 1000 {
+		t.Errorf("unexpected status: %s", w.Body.String())
+	}
+}
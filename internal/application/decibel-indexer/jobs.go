@@ -0,0 +1,107 @@
+package decibelindexer
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// job is periodic work on the indexed tables that runs alongside the
+// pipeline, e.g. refreshing the trader analytics.
+type job interface {
+	Name() string
+	// Interval is how often the job runs unless configured otherwise.
+	Interval() time.Duration
+	// Run runs the job scheduled at at, a multiple of its interval except
+	// for the first run at startup.
+	Run(ctx context.Context, at time.Time) error
+}
+
+// followingJob is a job that reads what another job writes. It runs right
+// after the first run of that job at or past each of its own interval
+// boundaries, scheduled at the boundary, instead of on its own.
+type followingJob interface {
+	job
+	// After is the name of the job it follows.
+	After() string
+}
+
+// startJobs runs every job once and then on the multiples of its interval
+// until Close, so hourly jobs run at the top of the hour. A run in progress
+// is cancelled by Close.
+func (a *Application) startJobs() {
+	ctx, cancel := context.WithCancel(a.ctx)
+	go func() {
+		<-a.quit
+		cancel()
+	}()
+
+	intervals := make(map[string]time.Duration, len(a.jobs))
+	followers := make(map[string][]followingJob)
+	for _, j := range a.jobs {
+		interval := j.Interval()
+		if configured, ok := a.cfg.Jobs[j.Name()]; ok {
+			interval = configured
+		}
+		if interval <= 0 {
+			slog.Info("job disabled", "job", j.Name())
+			continue
+		}
+		intervals[j.Name()] = interval
+		if f, ok := j.(followingJob); ok {
+			followers[f.After()] = append(followers[f.After()], f)
+		}
+	}
+
+	for _, j := range a.jobs {
+		interval, ok := intervals[j.Name()]
+		if _, following := j.(followingJob); !ok || following {
+			continue
+		}
+		// the followers are due at their first boundary after startup
+		fs := followers[j.Name()]
+		delete(followers, j.Name())
+		due := make(map[string]time.Time, len(fs))
+		for _, f := range fs {
+			due[f.Name()] = time.Now().Truncate(intervals[f.Name()]).Add(intervals[f.Name()])
+		}
+
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			at := time.Now()
+			for {
+				runJob(ctx, j, at)
+				for _, f := range fs {
+					if ctx.Err() == nil && !at.Before(due[f.Name()]) {
+						runJob(ctx, f, due[f.Name()])
+						due[f.Name()] = at.Truncate(intervals[f.Name()]).Add(intervals[f.Name()])
+					}
+				}
+
+				at = time.Now().Truncate(interval).Add(interval)
+				timer := time.NewTimer(time.Until(at))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+		}()
+	}
+	for after, fs := range followers {
+		for _, f := range fs {
+			slog.Warn("job disabled, the job it follows does not run", "job", f.Name(), "after", after)
+		}
+	}
+}
+
+func runJob(ctx context.Context, j job, at time.Time) {
+	started := time.Now()
+	if err := j.Run(ctx, at); err != nil && ctx.Err() == nil {
+		slog.Error("failed to run job", "job", j.Name(), "error", err)
+	} else if err == nil {
+		slog.Debug("ran job", "job", j.Name(), "at", at, "duration", time.Since(started))
+	}
+}
@@ -0,0 +1,81 @@
+package decibelindexer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type stubJob struct {
+	name     string
+	interval time.Duration
+	after    string
+	runs     chan<- jobRun
+}
+
+type jobRun struct {
+	name string
+	at   time.Time
+}
+
+func (j *stubJob) Name() string            { return j.name }
+func (j *stubJob) Interval() time.Duration { return j.interval }
+
+func (j *stubJob) Run(ctx context.Context, at time.Time) error {
+	j.runs <- jobRun{name: j.name, at: at}
+	return nil
+}
+
+type stubFollowingJob struct{ stubJob }
+
+func (j *stubFollowingJob) After() string { return j.after }
+
+func TestStartJobsRunsFollowersOnTheirBoundaries(t *testing.T) {
+	runs := make(chan jobRun, 100)
+	const interval = 20 * time.Millisecond
+	app := &Application{
+		ctx:  context.Background(),
+		cfg:  &Config{},
+		quit: make(chan struct{}),
+		jobs: []job{
+			&stubFollowingJob{stubJob{name: "snapshot", interval: 3 * interval, after: "refresh", runs: runs}},
+			&stubJob{name: "refresh", interval: interval, runs: runs},
+		},
+	}
+	app.startJobs()
+
+	var got []jobRun
+	deadline := time.After(5 * time.Second)
+	for snapshots := 0; snapshots < 2; {
+		select {
+		case run := <-runs:
+			got = append(got, run)
+			if run.name == "snapshot" {
+				snapshots++
+			}
+		case <-deadline:
+			t.Fatalf("timed out, runs: %+v", got)
+		}
+	}
+	close(app.quit)
+	app.wg.Wait()
+
+	if got[0].name != "refresh" {
+		t.Errorf("first run = %s, want refresh", got[0].name)
+	}
+	for i, run := range got {
+		switch run.name {
+		case "snapshot":
+			if !run.at.Equal(run.at.Truncate(3 * interval)) {
+				t.Errorf("snapshot at %s, want a multiple of its interval", run.at)
+			}
+			if prev := got[i-1]; prev.name != "refresh" || prev.at.Before(run.at) {
+				t.Errorf("snapshot at %s after %+v, want it after a refresh at or past it", run.at, prev)
+			}
+		case "refresh":
+			if i > 0 && !run.at.Equal(run.at.Truncate(interval)) {
+				t.Errorf("refresh at %s, want a multiple of its interval", run.at)
+			}
+		}
+	}
+}
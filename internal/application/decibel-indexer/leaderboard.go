@@ -0,0 +1,54 @@
+package decibelindexer
+
+import (
+	"context"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultLeaderboardInterval = time.Hour
+	// leaderboardSize is the number of traders ranked in a snapshot.
+	leaderboardSize = 500
+	// leaderboardMinRoiEquity keeps accounts with less equity (USDC) off
+	// the ROI leaderboards.
+	leaderboardMinRoiEquity = 100
+	// leaderboardRetention is how long every snapshot is kept; older ones
+	// are thinned out to one a day.
+	leaderboardRetention = 7 * 24 * time.Hour
+)
+
+// leaderboardJob snapshots the leaderboards from the trader analytics, so
+// ranks can be compared over time. It follows the analytics job, so every
+// snapshot ranks analytics refreshed at or after the hour it is taken at.
+type leaderboardJob struct {
+	db *gorm.DB
+}
+
+func newLeaderboardJob(db *gorm.DB) *leaderboardJob {
+	return &leaderboardJob{db: db}
+}
+
+func (j *leaderboardJob) Name() string {
+	return "leaderboard"
+}
+
+func (j *leaderboardJob) Interval() time.Duration {
+	return defaultLeaderboardInterval
+}
+
+func (j *leaderboardJob) After() string {
+	return "analytics"
+}
+
+func (j *leaderboardJob) Run(ctx context.Context, at time.Time) error {
+	db := j.db.WithContext(ctx)
+	// at is on the hour, so reruns replace the snapshot
+	at = at.UTC()
+	if err := models.SnapshotLeaderboards(db, at, leaderboardSize, leaderboardMinRoiEquity); err != nil {
+		return err
+	}
+	return models.PruneLeaderboards(db, at.Add(-leaderboardRetention))
+}
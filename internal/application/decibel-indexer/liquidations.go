@@ -0,0 +1,185 @@
+package decibelindexer
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aptos-labs/aptos-go-sdk/api"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"github.com/mediocregopher/radix/v3"
+	"gorm.io/gorm"
+)
+
+// liquidationProcessor indexes liquidated positions and publishes them to the
+// live feed.
+type liquidationProcessor struct {
+	db                 *gorm.DB
+	pool               *radix.Pool
+	backstopLiquidator string
+}
+
+func newLiquidationProcessor(db *gorm.DB, pool *radix.Pool, backstopLiquidator string) *liquidationProcessor {
+	return &liquidationProcessor{db: db, pool: pool, backstopLiquidator: backstopLiquidator}
+}
+
+func (proc *liquidationProcessor) Name() string {
+	return "liquidations"
+}
+
+func (proc *liquidationProcessor) ResourceTypes() []string {
+	return nil
+}
+
+func (proc *liquidationProcessor) EventTypes() []string {
+	return []string{tradeEvent, liquidationModule}
+}
+
+func (proc *liquidationProcessor) Process(ctx context.Context, txs []*api.UserTransaction) error {
+	var liquidations []models.Liquidation
+	for _, tx := range txs {
+		rows, err := extractLiquidations(tx, proc.backstopLiquidator)
+		if err != nil {
+			return err
+		}
+		liquidations = append(liquidations, rows...)
+	}
+	if len(liquidations) == 0 {
+		return nil
+	}
+
+	if err := models.InsertLiquidations(proc.db.WithContext(ctx), liquidations); err != nil {
+		return err
+	}
+	for _, l := range liquidations {
+		b, err := json.Marshal(l)
+		if err != nil {
+			return err
+		}
+		if err := publish(ctx, proc.pool, models.LiquidationChannel, string(b)); err != nil {
+			slog.Warn("failed to publish liquidation", "version", l.Version, "account", l.Account, "error", err)
+		}
+	}
+	return nil
+}
+
+// extractLiquidations finds the positions closed by a liquidation in a
+// transaction. The liquidated positions are the ones the liquidation module
+// events report, or the accounts named in the arguments of a liquidation
+// module call; other traders closing in the same transaction are only their
+// counterparties. A liquidated position is taken over by the backstop when
+// the backstop liquidator traded in its market, and closed on the market
+// otherwise.
+func extractLiquidations(tx *api.UserTransaction, backstopLiquidator string) ([]models.Liquidation, error) {
+	type trade struct {
+		index int
+		types.TradeEvent
+	}
+	var trades []trade
+	// what the liquidation module reported by account and market
+	details := make(map[[2]string]types.LiquidationEvent)
+	for _, event := range types.ExtractEvents(tx) {
+		switch {
+		case event.Type == tradeEvent:
+			var t types.TradeEvent
+			if err := MapToStructJSON(event.Data, &t); err != nil {
+				return nil, err
+			}
+			trades = append(trades, trade{index: event.EventIndex, TradeEvent: t})
+		case strings.HasPrefix(event.Type, liquidationModule):
+			var l types.LiquidationEvent
+			if err := MapToStructJSON(event.Data, &l); err != nil {
+				slog.Warn("failed to decode liquidation event", "version", tx.Version, "type", event.Type, "error", err)
+				continue
+			}
+			account := l.Account
+			if account == "" {
+				account = l.User
+			}
+			key := [2]string{types.NormalizeAddress(account), l.Market.Inner}
+			d := details[key]
+			d.LiquidationMargin += l.LiquidationMargin
+			d.BackstopLiquidatorCoveredLoss += l.BackstopLiquidatorCoveredLoss
+			details[key] = d
+		}
+	}
+	if len(trades) == 0 {
+		return nil, nil
+	}
+
+	// the liquidated positions by account and market, an empty market
+	// standing for every market of the account
+	liquidated := make(map[[2]string]bool, len(details))
+	for key := range details {
+		liquidated[key] = true
+	}
+	if payload := types.EntryFunction(tx); payload != nil && strings.HasPrefix(payload.Function, liquidationModule) {
+		for _, arg := range payload.Arguments {
+			if s, ok := arg.(string); ok {
+				if address := types.NormalizeAddress(s); address != "" {
+					liquidated[[2]string{address, ""}] = true
+				}
+			}
+		}
+	}
+	if len(liquidated) == 0 {
+		return nil, nil
+	}
+
+	backstop := types.NormalizeAddress(backstopLiquidator)
+	backstopMarkets := make(map[string]bool)
+	if backstop != "" {
+		for _, t := range trades {
+			if types.NormalizeAddress(t.Account) == backstop {
+				backstopMarkets[t.Market.Inner] = true
+			}
+		}
+	}
+
+	timestamp := time.UnixMicro(int64(tx.Timestamp))
+	var liquidations []models.Liquidation
+	for _, t := range trades {
+		if t.Action != types.ActionCloseLong && t.Action != types.ActionCloseShort {
+			continue
+		}
+		account := types.NormalizeAddress(t.Account)
+		if account == backstop {
+			continue
+		}
+		if !liquidated[[2]string{account, t.Market.Inner}] && !liquidated[[2]string{account, ""}] {
+			continue
+		}
+		kind := models.LiquidationKindMarket
+		if backstopMarkets[t.Market.Inner] {
+			kind = models.LiquidationKindBackstop
+		}
+		var loss types.Uint64
+		if !t.IsProfit {
+			loss = t.RealizedPnlAmount
+		}
+		// the module may report the account without the market
+		d, ok := details[[2]string{account, t.Market.Inner}]
+		if !ok {
+			d = details[[2]string{account, ""}]
+		}
+		liquidations = append(liquidations, models.Liquidation{
+			Version:          tx.Version,
+			EventIndex:       t.index,
+			VersionTimestamp: timestamp,
+			Account:          account,
+			Market:           t.Market.Inner,
+			Kind:             kind,
+			IsLong:           t.Action == types.ActionCloseLong,
+			Size:             t.Size,
+			Price:            t.Price,
+			Loss:             loss,
+			CoveredLoss:      d.BackstopLiquidatorCoveredLoss,
+			Margin:           d.LiquidationMargin,
+			Fee:              t.FeeAmount,
+		})
+	}
+	return liquidations, nil
+}
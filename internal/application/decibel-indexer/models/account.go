@@ -0,0 +1,43 @@
+package models
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// accountColumns are the columns that store account addresses. They are
+// written in the long form of types.NormalizeAddress, so a lookup by a
+// normalized address matches however the events spelled it. An event always
+// spells an address the same way, so the rows of an aggregate keyed by one
+// were all written in the short form before and cannot collide once
+// rewritten.
+var accountColumns = []struct {
+	table, column string
+}{
+	{"TRADES", "account"},
+	{"TRADER_PNL", "account"},
+	{"BALANCE_CHANGES", "account"},
+	{"ACCOUNT_BALANCES", "account"},
+	{"FUNDING_PAYMENTS", "account"},
+	{"TRADER_ANALYTICS", "account"},
+	{"LEADERBOARD", "account"},
+	{"PERP_POSITIONS", "owner"},
+	{"POSITION_TRIGGERS", "owner"},
+	{"FEE_DISTRIBUTIONS", "builder"},
+	{"FEE_REVENUE", "builder"},
+}
+
+// NormalizeAccounts rewrites the addresses stored before they were
+// normalized on write to their long form, e.g. 0x3938…f07e to 0x03938…f07e.
+func NormalizeAccounts(conn *gorm.DB) error {
+	for _, c := range accountColumns {
+		long := fmt.Sprintf(`'0x' || lpad(lower(substr(%s, 3)), 64, '0')`, c.column)
+		if err := conn.Exec(fmt.Sprintf(`UPDATE "%s" SET %s = %s WHERE %s LIKE '0x%%' AND %s <> %s`,
+			c.table, c.column, long, c.column, c.column, long,
+		)).Error; err != nil {
+			return fmt.Errorf("models: normalize %s.%s: %w", c.table, c.column, err)
+		}
+	}
+	return nil
+}
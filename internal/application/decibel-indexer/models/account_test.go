@@ -0,0 +1,36 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+)
+
+// events spell addresses without their leading zeros
+const (
+	shortAccount = "0x3938fe00000000000000000000000000000000000000000000000000000f07e"
+	longAccount  = "0x03938fe00000000000000000000000000000000000000000000000000000f07e"
+)
+
+func TestAccountsNormalizedOnWrite(t *testing.T) {
+	var trade Trade
+	trade.FromTradeEvent(1, 0, time.Now(), types.TradeEvent{Account: shortAccount})
+	if trade.Account != longAccount {
+		t.Errorf("trade account = %s, want %s", trade.Account, longAccount)
+	}
+
+	var change BalanceChange
+	change.FromCollateralBalanceChangeEvent(1, 0, time.Now(), types.CollateralBalanceChangeEvent{
+		BalanceType: types.CollateralBalanceType{Variant: "Cross", Account: shortAccount},
+	})
+	if change.Account != longAccount {
+		t.Errorf("balance change account = %s, want %s", change.Account, longAccount)
+	}
+
+	var position PerpPosition
+	position.FromPerpPosition("0x1", 1, time.Now(), shortAccount, false, types.PerpPosition{})
+	if position.Owner != longAccount {
+		t.Errorf("position owner = %s, want %s", position.Owner, longAccount)
+	}
+}
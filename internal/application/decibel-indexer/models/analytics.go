@@ -0,0 +1,182 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TraderAnalytics is the performance of an account, refreshed periodically
+// by the analytics job. Amounts are in USDC and ratios in percent.
+type TraderAnalytics struct {
+	Account string `gorm:"primaryKey;column:account;type:varchar(66);not null"`
+
+	// Equity is the collateral plus the unrealized PnL of the open
+	// positions at mark.
+	Equity float64 `gorm:"column:equity;type:double precision;not null"`
+
+	// PnL after fees and funding over the last day, week, month and the
+	// whole history, and the same as a return on the equity at the start of
+	// the window. The all time ROI compounds the daily returns.
+	DailyPnl   float64 `gorm:"column:daily_pnl;type:double precision;not null"`
+	WeeklyPnl  float64 `gorm:"column:weekly_pnl;type:double precision;not null"`
+	MonthlyPnl float64 `gorm:"column:monthly_pnl;type:double precision;not null"`
+	AllTimePnl float64 `gorm:"column:all_time_pnl;type:double precision;not null"`
+	DailyRoi   float64 `gorm:"column:daily_roi;type:double precision;not null"`
+	WeeklyRoi  float64 `gorm:"column:weekly_roi;type:double precision;not null"`
+	MonthlyRoi float64 `gorm:"column:monthly_roi;type:double precision;not null"`
+	AllTimeRoi float64 `gorm:"column:all_time_roi;type:double precision;not null"`
+
+	// MainMarket is the market of the open position with the largest
+	// notional, empty without open positions.
+	MainMarket    string  `gorm:"column:main_market;type:varchar(66);not null"`
+	MainIsLong    bool    `gorm:"column:main_is_long;type:bool;not null"`
+	MainNotional  float64 `gorm:"column:main_notional;type:double precision;not null"`
+	LongNotional  float64 `gorm:"column:long_notional;type:double precision;not null"`
+	ShortNotional float64 `gorm:"column:short_notional;type:double precision;not null"`
+
+	Trades          int64   `gorm:"column:trades;type:bigint;not null"`
+	ClosedPositions int64   `gorm:"column:closed_positions;type:bigint;not null"`
+	WinRate         float64 `gorm:"column:win_rate;type:double precision;not null"`
+	AvgWin          float64 `gorm:"column:avg_win;type:double precision;not null"`
+	AvgLoss         float64 `gorm:"column:avg_loss;type:double precision;not null"`
+	// ProfitFactor is the gross profit over the gross loss of the closed
+	// positions, 0 while there is no loss.
+	ProfitFactor float64 `gorm:"column:profit_factor;type:double precision;not null"`
+	// MaxDrawdown is the largest drop from a peak of the compounded daily
+	// returns, so deposits and withdrawals do not count as losses.
+	MaxDrawdown float64 `gorm:"column:max_drawdown;type:double precision;not null"`
+	// Sharpe and Sortino are annualized from the daily returns.
+	Sharpe            float64 `gorm:"column:sharpe;type:double precision;not null"`
+	Sortino           float64 `gorm:"column:sortino;type:double precision;not null"`
+	AvgLeverage       float64 `gorm:"column:avg_leverage;type:double precision;not null"`
+	AvgHoldingSeconds float64 `gorm:"column:avg_holding_seconds;type:double precision;not null"`
+	// TradesVersion is the version of the last trade the closed position
+	// metrics include; they are only recomputed after newer trades.
+	TradesVersion uint64 `gorm:"column:trades_version;type:numeric;not null;default:0"`
+
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp;not null"`
+}
+
+func (s *TraderAnalytics) TableName() string {
+	return "TRADER_ANALYTICS"
+}
+
+func UpsertTraderAnalytics(conn *gorm.DB, analytics []TraderAnalytics) error {
+	return conn.Clauses(clause.OnConflict{UpdateAll: true}).Create(&analytics).Error
+}
+
+// TraderAnalyticsSortColumns are the columns the traders can be sorted by,
+// by sort key.
+var TraderAnalyticsSortColumns = map[string]string{
+	"equity":           "equity",
+	"daily_pnl":        "daily_pnl",
+	"weekly_pnl":       "weekly_pnl",
+	"monthly_pnl":      "monthly_pnl",
+	"all_time_pnl":     "all_time_pnl",
+	"daily_roi":        "daily_roi",
+	"weekly_roi":       "weekly_roi",
+	"monthly_roi":      "monthly_roi",
+	"all_time_roi":     "all_time_roi",
+	"trades":           "trades",
+	"win_rate":         "win_rate",
+	"profit_factor":    "profit_factor",
+	"max_drawdown":     "max_drawdown",
+	"sharpe":           "sharpe",
+	"sortino":          "sortino",
+	"avg_leverage":     "avg_leverage",
+	"avg_holding_time": "avg_holding_seconds",
+}
+
+type TraderAnalyticsFilter struct {
+	// Search matches accounts starting with it.
+	Search string
+	// SortBy is a key of TraderAnalyticsSortColumns.
+	SortBy   string
+	SortDesc bool
+	Offset   int
+	Limit    int
+}
+
+// ListTraderAnalytics returns a page of the traders matching filter and
+// the number of matching traders.
+func ListTraderAnalytics(conn *gorm.DB, filter TraderAnalyticsFilter) ([]TraderAnalytics, int64, error) {
+	query := conn.Model(&TraderAnalytics{})
+	if filter.Search != "" {
+		query = query.Where("account LIKE ?", escapeLike(filter.Search)+"%")
+	}
+	query = query.Session(&gorm.Session{})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := TraderAnalyticsSortColumns[filter.SortBy]
+	if !ok {
+		column = "all_time_pnl"
+	}
+	order := clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: filter.SortDesc}
+	var analytics []TraderAnalytics
+	if err := query.
+		Order(order).
+		Order("account").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&analytics).Error; err != nil {
+		return nil, 0, err
+	}
+	return analytics, total, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike escapes the wildcards of s to match it literally in a LIKE
+// pattern.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// GetActiveAccounts returns the accounts that traded or moved collateral
+// since since, and those never analyzed.
+func GetActiveAccounts(conn *gorm.DB, since time.Time) ([]string, error) {
+	var accounts []string
+	if err := conn.Raw(`
+SELECT account FROM "TRADER_PNL" WHERE hour >= @since
+UNION
+SELECT account FROM "ACCOUNT_BALANCES" WHERE hour >= @since
+UNION
+SELECT DISTINCT p.account FROM "TRADER_PNL" p
+WHERE NOT EXISTS (SELECT 1 FROM "TRADER_ANALYTICS" a WHERE a.account = p.account)
+ORDER BY account`,
+		map[string]any{"since": since},
+	).Scan(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func GetTraderAnalytics(conn *gorm.DB, account string) (TraderAnalytics, bool, error) {
+	var analytics []TraderAnalytics
+	if err := conn.Where("account = ?", account).Limit(1).Find(&analytics).Error; err != nil {
+		return TraderAnalytics{}, false, err
+	}
+	if len(analytics) == 0 {
+		return TraderAnalytics{}, false, nil
+	}
+	return analytics[0], true, nil
+}
+
+// GetTradersAnalytics returns the stored analytics of accounts by account.
+func GetTradersAnalytics(conn *gorm.DB, accounts []string) (map[string]TraderAnalytics, error) {
+	var analytics []TraderAnalytics
+	if err := conn.Where("account IN ?", accounts).Find(&analytics).Error; err != nil {
+		return nil, err
+	}
+	byAccount := make(map[string]TraderAnalytics, len(analytics))
+	for _, a := range analytics {
+		byAccount[a.Account] = a
+	}
+	return byAccount, nil
+}
@@ -0,0 +1,9 @@
+package models
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	if got, want := escapeLike(`0x_a%b\c`), `0x\_a\%b\\c`; got != want {
+		t.Errorf("escapeLike = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BalanceChange is a change of the collateral held in one balance sheet of
+// an account. Market is empty for the cross margin sheet. Delta is signed in
+// raw collateral units.
+type BalanceChange struct {
+	Version          uint64       `gorm:"primaryKey;column:version;type:numeric;not null"`
+	EventIndex       int          `gorm:"primaryKey;column:event_index;type:int;not null"`
+	VersionTimestamp time.Time    `gorm:"column:version_timestamp;type:timestamp;not null;index:idx_balance_changes_timestamp"`
+	Account          string       `gorm:"column:account;type:varchar(66);not null;index:idx_balance_changes_account"`
+	Market           string       `gorm:"column:market;type:varchar(66);not null"`
+	ChangeType       string       `gorm:"column:change_type;type:varchar(32);not null"`
+	Delta            int64        `gorm:"column:delta;type:bigint;not null"`
+	BalanceAfter     types.Uint64 `gorm:"column:balance_after;type:decimal(20,0);not null"`
+}
+
+func (s *BalanceChange) FromCollateralBalanceChangeEvent(
+	version uint64,
+	eventIndex int,
+	versionTimestamp time.Time,
+	value types.CollateralBalanceChangeEvent,
+) {
+	s.Version = version
+	s.EventIndex = eventIndex
+	s.VersionTimestamp = versionTimestamp
+	s.Account = types.NormalizeAddress(value.BalanceType.Account)
+	if value.BalanceType.Variant == types.CollateralBalanceIsolated {
+		s.Market = value.BalanceType.Market.Inner
+	}
+	s.ChangeType = string(value.ChangeType)
+	s.Delta = int64(value.Delta)
+	if !value.IsDeltaPositive {
+		s.Delta = -s.Delta
+	}
+	s.BalanceAfter = value.BalanceAfter.Value
+}
+
+func (s *BalanceChange) TableName() string {
+	return "BALANCE_CHANGES"
+}
+
+func InsertBalanceChanges(conn *gorm.DB, changes []BalanceChange) error {
+	return conn.Clauses(clause.OnConflict{DoNothing: true}).Create(&changes).Error
+}
+
+// AccountBalance is the collateral of one balance sheet of an account at the
+// close of an hour.
+type AccountBalance struct {
+	Account string       `gorm:"primaryKey;column:account;type:varchar(66);not null"`
+	Market  string       `gorm:"primaryKey;column:market;type:varchar(66);not null"`
+	Hour    time.Time    `gorm:"primaryKey;column:hour;type:timestamp;not null"`
+	Version uint64       `gorm:"column:version;type:numeric;not null"`
+	Balance types.Uint64 `gorm:"column:balance;type:decimal(20,0);not null"`
+}
+
+func (s *AccountBalance) TableName() string {
+	return "ACCOUNT_BALANCES"
+}
+
+// RefreshAccountBalances recomputes the hourly closing balances of [from,
+// to) from the balance changes, so reprocessing a batch is harmless.
+func RefreshAccountBalances(conn *gorm.DB, from, to time.Time) error {
+	return conn.Exec(`
+INSERT INTO "ACCOUNT_BALANCES" (account, market, hour, version, balance)
+SELECT DISTINCT ON (account, market, date_trunc('hour', version_timestamp))
+	account,
+	market,
+	date_trunc('hour', version_timestamp),
+	version,
+	balance_after
+FROM "BALANCE_CHANGES"
+WHERE version_timestamp >= ? AND version_timestamp < ?
+ORDER BY account, market, date_trunc('hour', version_timestamp), version DESC, event_index DESC
+ON CONFLICT (account, market, hour) DO UPDATE SET
+	version = EXCLUDED.version,
+	balance = EXCLUDED.balance`,
+		from.UTC().Truncate(time.Hour), to.UTC().Truncate(time.Hour).Add(time.Hour),
+	).Error
+}
+
+// GetAccountBalances returns the hourly balances of an account in [from,
+// to), oldest first, preceded by the last balance of every sheet before
+// from so the total is known from the start.
+func GetAccountBalances(conn *gorm.DB, account string, from, to time.Time) ([]AccountBalance, error) {
+	var prev []AccountBalance
+	if err := conn.
+		Raw(`
+SELECT DISTINCT ON (market) *
+FROM "ACCOUNT_BALANCES"
+WHERE account = ? AND hour < ?
+ORDER BY market, hour DESC`,
+			account, from,
+		).
+		Scan(&prev).Error; err != nil {
+		return nil, err
+	}
+	var balances []AccountBalance
+	if err := conn.
+		Where("account = ? AND hour >= ? AND hour < ?", account, from, to).
+		Order("hour, market").
+		Find(&balances).Error; err != nil {
+		return nil, err
+	}
+	return append(prev, balances...), nil
+}
+
+// GetAccountsBalances returns the hourly sheet balances of accounts before
+// to by account, oldest first.
+func GetAccountsBalances(conn *gorm.DB, accounts []string, to time.Time) (map[string][]AccountBalance, error) {
+	var balances []AccountBalance
+	if err := conn.
+		Where("account IN ? AND hour < ?", accounts, to).
+		Order("account, hour, market").
+		Find(&balances).Error; err != nil {
+		return nil, err
+	}
+	byAccount := make(map[string][]AccountBalance)
+	for _, b := range balances {
+		byAccount[b.Account] = append(byAccount[b.Account], b)
+	}
+	return byAccount, nil
+}
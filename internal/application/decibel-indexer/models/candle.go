@@ -0,0 +1,184 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CandleChannel is the redis channel the indexer publishes the latest candle
+// of every market and resolution it refreshed to, JSON encoded.
+const CandleChannel = "decibel:candle"
+
+// CandleResolution is the length of a candle, e.g. 1m.
+type CandleResolution struct {
+	Name     string
+	Duration time.Duration
+}
+
+// CandleResolutions are the resolutions candles are aggregated to.
+var CandleResolutions = []CandleResolution{
+	{"1m", time.Minute},
+	{"5m", 5 * time.Minute},
+	{"15m", 15 * time.Minute},
+	{"1h", time.Hour},
+	{"4h", 4 * time.Hour},
+	{"1d", 24 * time.Hour},
+}
+
+func GetCandleResolution(name string) (CandleResolution, bool) {
+	i := slices.IndexFunc(CandleResolutions, func(r CandleResolution) bool { return r.Name == name })
+	if i < 0 {
+		return CandleResolution{}, false
+	}
+	return CandleResolutions[i], true
+}
+
+// Candle is the OHLCV of a market over one resolution bucket, in raw price
+// and size units. Volume and Trades count every match once.
+type Candle struct {
+	Market     string       `gorm:"primaryKey;column:market;type:varchar(66);not null" json:"market"`
+	Resolution string       `gorm:"primaryKey;column:resolution;type:varchar(8);not null" json:"resolution"`
+	OpenTime   time.Time    `gorm:"primaryKey;column:open_time;type:timestamp;not null" json:"open_time"`
+	Open       types.Uint64 `gorm:"column:open;type:decimal(20,0);not null" json:"open"`
+	High       types.Uint64 `gorm:"column:high;type:decimal(20,0);not null" json:"high"`
+	Low        types.Uint64 `gorm:"column:low;type:decimal(20,0);not null" json:"low"`
+	Close      types.Uint64 `gorm:"column:close;type:decimal(20,0);not null" json:"close"`
+	Volume     types.Uint64 `gorm:"column:volume;type:decimal(20,0);not null" json:"volume"`
+	Trades     int64        `gorm:"column:trades;type:bigint;not null" json:"trades"`
+	// OpenVersion and CloseVersion are the versions of the trades that
+	// opened and closed the candle, to merge later trades in order.
+	OpenVersion  uint64 `gorm:"column:open_version;type:numeric;not null;default:0" json:"-"`
+	CloseVersion uint64 `gorm:"column:close_version;type:numeric;not null;default:0" json:"-"`
+}
+
+func (s *Candle) TableName() string {
+	return "CANDLES"
+}
+
+// MergeCandles merges newly indexed trades into the candles of every
+// resolution, touching only the buckets they fall in. Each match emits a
+// trade for both counterparties, hence the halved volume and count; both
+// always come in the same transaction.
+func MergeCandles(conn *gorm.DB, trades []Trade) error {
+	candles := aggregateCandles(trades)
+	if len(candles) == 0 {
+		return nil
+	}
+	return conn.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "market"}, {Name: "resolution"}, {Name: "open_time"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"open":          gorm.Expr(`CASE WHEN EXCLUDED.open_version < "CANDLES".open_version THEN EXCLUDED.open ELSE "CANDLES".open END`),
+			"open_version":  gorm.Expr(`LEAST("CANDLES".open_version, EXCLUDED.open_version)`),
+			"high":          gorm.Expr(`GREATEST("CANDLES".high, EXCLUDED.high)`),
+			"low":           gorm.Expr(`LEAST("CANDLES".low, EXCLUDED.low)`),
+			"close":         gorm.Expr(`CASE WHEN EXCLUDED.close_version >= "CANDLES".close_version THEN EXCLUDED.close ELSE "CANDLES".close END`),
+			"close_version": gorm.Expr(`GREATEST("CANDLES".close_version, EXCLUDED.close_version)`),
+			"volume":        gorm.Expr(`"CANDLES".volume + EXCLUDED.volume`),
+			"trades":        gorm.Expr(`"CANDLES".trades + EXCLUDED.trades`),
+		}),
+	}).Create(&candles).Error
+}
+
+// aggregateCandles builds the candles of trades for every resolution, in
+// the order of their first trade.
+func aggregateCandles(trades []Trade) []Candle {
+	type key struct {
+		market     string
+		resolution string
+		openTime   time.Time
+	}
+	index := make(map[key]int)
+	var candles []Candle
+	for _, t := range trades {
+		for _, resolution := range CandleResolutions {
+			k := key{t.Market, resolution.Name, t.VersionTimestamp.UTC().Truncate(resolution.Duration)}
+			i, ok := index[k]
+			if !ok {
+				index[k] = len(candles)
+				candles = append(candles, Candle{
+					Market:       k.market,
+					Resolution:   k.resolution,
+					OpenTime:     k.openTime,
+					Open:         t.Price,
+					OpenVersion:  t.Version,
+					High:         t.Price,
+					Low:          t.Price,
+					Close:        t.Price,
+					CloseVersion: t.Version,
+				})
+				i = len(candles) - 1
+			}
+			c := &candles[i]
+			if t.Version < c.OpenVersion {
+				c.Open, c.OpenVersion = t.Price, t.Version
+			}
+			if t.Version >= c.CloseVersion {
+				c.Close, c.CloseVersion = t.Price, t.Version
+			}
+			c.High = max(c.High, t.Price)
+			c.Low = min(c.Low, t.Price)
+			c.Volume += t.Size
+			c.Trades++
+		}
+	}
+	for i := range candles {
+		candles[i].Volume /= 2
+		candles[i].Trades /= 2
+	}
+	return candles
+}
+
+type CandleFilter struct {
+	Market     string
+	Resolution string
+	From       time.Time
+	To         time.Time
+	// Limit keeps the newest candles of the range when positive.
+	Limit int
+}
+
+// GetCandles returns the candles opened in [from, to), oldest first.
+func GetCandles(conn *gorm.DB, filter CandleFilter) ([]Candle, error) {
+	query := conn.Where("market = ? AND resolution = ? AND open_time >= ? AND open_time < ?",
+		filter.Market, filter.Resolution, filter.From, filter.To)
+	var candles []Candle
+	if filter.Limit > 0 {
+		if err := query.Order("open_time DESC").Limit(filter.Limit).Find(&candles).Error; err != nil {
+			return nil, err
+		}
+		slices.Reverse(candles)
+		return candles, nil
+	}
+	if err := query.Order("open_time").Find(&candles).Error; err != nil {
+		return nil, err
+	}
+	return candles, nil
+}
+
+// GetCandleBefore returns the newest candle opened before t.
+func GetCandleBefore(conn *gorm.DB, market, resolution string, t time.Time) (Candle, bool, error) {
+	var candles []Candle
+	err := conn.Where("market = ? AND resolution = ? AND open_time < ?", market, resolution, t).
+		Order("open_time DESC").Limit(1).Find(&candles).Error
+	if err != nil || len(candles) == 0 {
+		return Candle{}, false, err
+	}
+	return candles[0], true, nil
+}
+
+// GetLatestCandles returns the newest candle of every resolution of markets.
+func GetLatestCandles(conn *gorm.DB, markets []string) ([]Candle, error) {
+	var candles []Candle
+	err := conn.Raw(`
+SELECT DISTINCT ON (market, resolution) *
+FROM "CANDLES"
+WHERE market IN ?
+ORDER BY market, resolution, open_time DESC`,
+		markets,
+	).Scan(&candles).Error
+	return candles, err
+}
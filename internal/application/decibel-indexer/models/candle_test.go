@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAggregateCandles(t *testing.T) {
+	at := time.Date(2025, 1, 1, 10, 0, 30, 0, time.UTC)
+	// two matches, each traded by both counterparties, given out of order
+	trades := []Trade{
+		{Version: 12, EventIndex: 0, VersionTimestamp: at.Add(time.Minute), Market: "0xm", Price: 90, Size: 3},
+		{Version: 12, EventIndex: 1, VersionTimestamp: at.Add(time.Minute), Market: "0xm", Price: 90, Size: 3},
+		{Version: 10, EventIndex: 0, VersionTimestamp: at, Market: "0xm", Price: 100, Size: 2},
+		{Version: 10, EventIndex: 1, VersionTimestamp: at, Market: "0xm", Price: 100, Size: 2},
+	}
+
+	candles := aggregateCandles(trades)
+	byResolution := make(map[string][]Candle)
+	for _, c := range candles {
+		byResolution[c.Resolution] = append(byResolution[c.Resolution], c)
+	}
+	if got := len(byResolution["1m"]); got != 2 {
+		t.Fatalf("1m candles = %d, want 2", got)
+	}
+	hour := byResolution["1h"]
+	if len(hour) != 1 {
+		t.Fatalf("1h candles = %d, want 1", len(hour))
+	}
+	c := hour[0]
+	if !c.OpenTime.Equal(at.Truncate(time.Hour)) {
+		t.Errorf("open time = %v, want %v", c.OpenTime, at.Truncate(time.Hour))
+	}
+	if c.Open != 100 || c.Close != 90 || c.High != 100 || c.Low != 90 {
+		t.Errorf("ohlc = %d/%d/%d/%d, want 100/100/90/90", c.Open, c.High, c.Low, c.Close)
+	}
+	if c.OpenVersion != 10 || c.CloseVersion != 12 {
+		t.Errorf("open/close version = %d/%d, want 10/12", c.OpenVersion, c.CloseVersion)
+	}
+	if c.Volume != 5 || c.Trades != 2 {
+		t.Errorf("volume/trades = %d/%d, want 5/2", c.Volume, c.Trades)
+	}
+}
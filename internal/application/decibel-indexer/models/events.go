@@ -0,0 +1,45 @@
+package models
+
+import "gorm.io/gorm"
+
+// eventKey identifies a row indexed from the event at EventIndex of the
+// transaction at Version.
+type eventKey struct {
+	Version    uint64
+	EventIndex int
+}
+
+// unindexedEvents returns the rows whose event is not stored in table yet.
+// Aggregates merged from them count every event once, even when a batch is
+// processed again.
+func unindexedEvents[T any](conn *gorm.DB, table string, rows []T, key func(T) eventKey) ([]T, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	from, to := key(rows[0]).Version, key(rows[0]).Version
+	for _, row := range rows {
+		v := key(row).Version
+		from, to = min(from, v), max(to, v)
+	}
+	var stored []eventKey
+	if err := conn.Table(table).
+		Select("version, event_index").
+		Where("version BETWEEN ? AND ?", from, to).
+		Scan(&stored).Error; err != nil {
+		return nil, err
+	}
+	if len(stored) == 0 {
+		return rows, nil
+	}
+	seen := make(map[eventKey]bool, len(stored))
+	for _, k := range stored {
+		seen[k] = true
+	}
+	var fresh []T
+	for _, row := range rows {
+		if !seen[key(row)] {
+			fresh = append(fresh, row)
+		}
+	}
+	return fresh, nil
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FeeDistribution is how the fee of a single trade was split. Deltas are
+// signed raw collateral units; a negative position delta is a fee the trader
+// paid and a positive one a rebate.
+type FeeDistribution struct {
+	Version           uint64       `gorm:"primaryKey;column:version;type:numeric;not null"`
+	EventIndex        int          `gorm:"primaryKey;column:event_index;type:int;not null"`
+	VersionTimestamp  time.Time    `gorm:"column:version_timestamp;type:timestamp;not null;index:idx_fee_distributions_timestamp"`
+	Market            string       `gorm:"column:market;type:varchar(66);not null"`
+	PositionAddress   string       `gorm:"column:position_address;type:varchar(66);not null"`
+	PositionFeeDelta  int64        `gorm:"column:position_fee_delta;type:bigint;not null"`
+	TreasuryFeeDelta  int64        `gorm:"column:treasury_fee_delta;type:bigint;not null"`
+	BackstopVaultFees types.Uint64 `gorm:"column:backstop_vault_fees;type:decimal(20,0);not null"`
+	Builder           string       `gorm:"column:builder;type:varchar(66);not null;default:''"`
+	BuilderFees       types.Uint64 `gorm:"column:builder_fees;type:decimal(20,0);not null"`
+}
+
+func (s *FeeDistribution) FromFeeDistribution(
+	version uint64,
+	eventIndex int,
+	versionTimestamp time.Time,
+	market string,
+	value types.FeeDistribution,
+) {
+	s.Version = version
+	s.EventIndex = eventIndex
+	s.VersionTimestamp = versionTimestamp
+	s.Market = market
+	s.PositionAddress = value.PositionAddress
+	s.PositionFeeDelta = signed(value.PositionFeeDelta)
+	s.TreasuryFeeDelta = signed(value.TreasuryFeeDelta)
+	s.BackstopVaultFees = value.BackstopVaultFees
+	if len(value.BuilderOrReferrerFees.Vec) > 0 {
+		s.Builder = types.NormalizeAddress(value.BuilderOrReferrerFees.Vec[0].Address)
+		s.BuilderFees = value.BuilderOrReferrerFees.Vec[0].Fees
+	}
+}
+
+func (s *FeeDistribution) TableName() string {
+	return "FEE_DISTRIBUTIONS"
+}
+
+// InsertFeeDistributions stores the distributions not indexed yet and
+// returns them.
+func InsertFeeDistributions(conn *gorm.DB, distributions []FeeDistribution) ([]FeeDistribution, error) {
+	fresh, err := unindexedEvents(conn, "FEE_DISTRIBUTIONS", distributions, func(d FeeDistribution) eventKey {
+		return eventKey{d.Version, d.EventIndex}
+	})
+	if err != nil || len(fresh) == 0 {
+		return nil, err
+	}
+	if err := conn.Clauses(clause.OnConflict{DoNothing: true}).Create(&fresh).Error; err != nil {
+		return nil, err
+	}
+	return fresh, nil
+}
+
+// FeeRevenue is the daily fee revenue of a market through a builder or
+// referrer. Builder is empty for trades placed without one.
+type FeeRevenue struct {
+	Day          time.Time `gorm:"primaryKey;column:day;type:timestamp;not null"`
+	Market       string    `gorm:"primaryKey;column:market;type:varchar(66);not null"`
+	Builder      string    `gorm:"primaryKey;column:builder;type:varchar(66);not null"`
+	TraderFees   int64     `gorm:"column:trader_fees;type:bigint;not null"`
+	TreasuryFees int64     `gorm:"column:treasury_fees;type:bigint;not null"`
+	BackstopFees int64     `gorm:"column:backstop_fees;type:bigint;not null"`
+	BuilderFees  int64     `gorm:"column:builder_fees;type:bigint;not null"`
+	Trades       int64     `gorm:"column:trades;type:bigint;not null"`
+}
+
+func (s *FeeRevenue) TableName() string {
+	return "FEE_REVENUE"
+}
+
+// MergeFeeRevenue adds newly indexed distributions to the daily revenue of
+// their day, market and builder.
+func MergeFeeRevenue(conn *gorm.DB, distributions []FeeDistribution) error {
+	revenue := aggregateFeeRevenue(distributions)
+	if len(revenue) == 0 {
+		return nil
+	}
+	return conn.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "day"}, {Name: "market"}, {Name: "builder"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"trader_fees":   gorm.Expr(`"FEE_REVENUE".trader_fees + EXCLUDED.trader_fees`),
+			"treasury_fees": gorm.Expr(`"FEE_REVENUE".treasury_fees + EXCLUDED.treasury_fees`),
+			"backstop_fees": gorm.Expr(`"FEE_REVENUE".backstop_fees + EXCLUDED.backstop_fees`),
+			"builder_fees":  gorm.Expr(`"FEE_REVENUE".builder_fees + EXCLUDED.builder_fees`),
+			"trades":        gorm.Expr(`"FEE_REVENUE".trades + EXCLUDED.trades`),
+		}),
+	}).Create(&revenue).Error
+}
+
+// aggregateFeeRevenue sums distributions by day, market and builder, in the
+// order of their first distribution.
+func aggregateFeeRevenue(distributions []FeeDistribution) []FeeRevenue {
+	type key struct {
+		day     time.Time
+		market  string
+		builder string
+	}
+	index := make(map[key]int)
+	var revenue []FeeRevenue
+	for _, d := range distributions {
+		k := key{d.VersionTimestamp.UTC().Truncate(24 * time.Hour), d.Market, d.Builder}
+		i, ok := index[k]
+		if !ok {
+			i = len(revenue)
+			index[k] = i
+			revenue = append(revenue, FeeRevenue{Day: k.day, Market: k.market, Builder: k.builder})
+		}
+		r := &revenue[i]
+		r.TraderFees -= d.PositionFeeDelta
+		r.TreasuryFees += d.TreasuryFeeDelta
+		r.BackstopFees += int64(d.BackstopVaultFees)
+		r.BuilderFees += int64(d.BuilderFees)
+		r.Trades++
+	}
+	return revenue
+}
+
+type FeeRevenueFilter struct {
+	Market  string
+	Builder string
+	From    time.Time
+	To      time.Time
+}
+
+// GetFeeRevenue returns the daily revenue rows in [from, to), oldest first.
+func GetFeeRevenue(conn *gorm.DB, filter FeeRevenueFilter) ([]FeeRevenue, error) {
+	query := conn.Where("day >= ? AND day < ?", filter.From, filter.To)
+	if filter.Market != "" {
+		query = query.Where("market = ?", filter.Market)
+	}
+	if filter.Builder != "" {
+		query = query.Where("builder = ?", filter.Builder)
+	}
+	var revenue []FeeRevenue
+	if err := query.Order("day, market, builder").Find(&revenue).Error; err != nil {
+		return nil, err
+	}
+	return revenue, nil
+}
+
+func signed(v types.I64) int64 {
+	if v.IsPositive {
+		return int64(v.Amount)
+	}
+	return -int64(v.Amount)
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+)
+
+func TestFromFeeDistributionNormalizesBuilder(t *testing.T) {
+	var value types.FeeDistribution
+	value.BuilderOrReferrerFees.Vec = []types.FeeWithDestination{{Address: "0x3938fe", Fees: 5}}
+
+	var d FeeDistribution
+	d.FromFeeDistribution(1, 0, time.Now(), "0xm", value)
+	if want := "0x00000000000000000000000000000000000000000000000000000000003938fe"; d.Builder != want {
+		t.Errorf("builder = %s, want %s", d.Builder, want)
+	}
+}
+
+func TestAggregateFeeRevenue(t *testing.T) {
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	distributions := []FeeDistribution{
+		{VersionTimestamp: day.Add(time.Hour), Market: "0xm", PositionFeeDelta: -100, TreasuryFeeDelta: 60, BackstopVaultFees: 10, Builder: "0xb", BuilderFees: 30},
+		{VersionTimestamp: day.Add(2 * time.Hour), Market: "0xm", PositionFeeDelta: 20, TreasuryFeeDelta: -20, Builder: "0xb"},
+		{VersionTimestamp: day.Add(25 * time.Hour), Market: "0xm", PositionFeeDelta: -50, TreasuryFeeDelta: 50},
+	}
+
+	revenue := aggregateFeeRevenue(distributions)
+	if len(revenue) != 2 {
+		t.Fatalf("revenue rows = %d, want 2", len(revenue))
+	}
+	r := revenue[0]
+	if !r.Day.Equal(day) || r.Builder != "0xb" {
+		t.Errorf("day/builder = %v/%s, want %v/0xb", r.Day, r.Builder, day)
+	}
+	if r.TraderFees != 80 || r.TreasuryFees != 40 || r.BackstopFees != 10 || r.BuilderFees != 30 || r.Trades != 2 {
+		t.Errorf("revenue = %+v, want trader 80, treasury 40, backstop 10, builder 30 over 2 trades", r)
+	}
+	if !revenue[1].Day.Equal(day.Add(24*time.Hour)) || revenue[1].Builder != "" {
+		t.Errorf("second row = %+v, want the next day without builder", revenue[1])
+	}
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MarketFunding is an hourly bucket of a market's accumulative funding index.
+// The funding paid over a bucket is the close index delta against the
+// previous bucket.
+type MarketFunding struct {
+	Market       string        `gorm:"primaryKey;column:market;type:varchar(66);not null"`
+	Hour         time.Time     `gorm:"primaryKey;column:hour;type:timestamp;not null"`
+	OpenVersion  uint64        `gorm:"column:open_version;type:numeric;not null"`
+	OpenIndex    types.Uint128 `gorm:"column:open_index;type:decimal(39,0);not null"`
+	CloseVersion uint64        `gorm:"column:close_version;type:numeric;not null"`
+	CloseIndex   types.Uint128 `gorm:"column:close_index;type:decimal(39,0);not null"`
+	MarkPx       types.Uint64  `gorm:"column:mark_px;type:decimal(20,0);not null"`
+}
+
+func (s *MarketFunding) TableName() string {
+	return "MARKET_FUNDING"
+}
+
+func UpsertMarketFundings(conn *gorm.DB, fundings []MarketFunding) error {
+	return conn.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "market"}, {Name: "hour"}},
+			DoUpdates: clause.Set{
+				{
+					Column: clause.Column{Name: "open_index"},
+					Value:  gorm.Expr(`CASE WHEN EXCLUDED.open_version < "MARKET_FUNDING".open_version THEN EXCLUDED.open_index ELSE "MARKET_FUNDING".open_index END`),
+				},
+				{
+					Column: clause.Column{Name: "open_version"},
+					Value:  gorm.Expr(`LEAST(EXCLUDED.open_version, "MARKET_FUNDING".open_version)`),
+				},
+				{
+					Column: clause.Column{Name: "close_index"},
+					Value:  gorm.Expr(`CASE WHEN EXCLUDED.close_version > "MARKET_FUNDING".close_version THEN EXCLUDED.close_index ELSE "MARKET_FUNDING".close_index END`),
+				},
+				{
+					Column: clause.Column{Name: "mark_px"},
+					Value:  gorm.Expr(`CASE WHEN EXCLUDED.close_version > "MARKET_FUNDING".close_version AND EXCLUDED.mark_px > 0 THEN EXCLUDED.mark_px ELSE "MARKET_FUNDING".mark_px END`),
+				},
+				{
+					Column: clause.Column{Name: "close_version"},
+					Value:  gorm.Expr(`GREATEST(EXCLUDED.close_version, "MARKET_FUNDING".close_version)`),
+				},
+			},
+		},
+	).Create(&fundings).Error
+}
+
+// GetMarketFundings returns the hourly buckets of a market in [from, to),
+// including the last bucket before from so the first rate can be derived.
+func GetMarketFundings(conn *gorm.DB, market string, from, to time.Time) ([]MarketFunding, error) {
+	var prev []MarketFunding
+	if err := conn.
+		Where("market = ? AND hour < ?", market, from).
+		Order("hour DESC").
+		Limit(1).
+		Find(&prev).Error; err != nil {
+		return nil, err
+	}
+	var fundings []MarketFunding
+	if err := conn.
+		Where("market = ? AND hour >= ? AND hour < ?", market, from, to).
+		Order("hour").
+		Find(&fundings).Error; err != nil {
+		return nil, err
+	}
+	return append(prev, fundings...), nil
+}
+
+// FundingPayment is funding realized by a trade. Amount is signed in raw
+// collateral units, positive when the trader paid.
+type FundingPayment struct {
+	Version          uint64    `gorm:"primaryKey;column:version;type:numeric;not null"`
+	EventIndex       int       `gorm:"primaryKey;column:event_index;type:int;not null"`
+	VersionTimestamp time.Time `gorm:"column:version_timestamp;type:timestamp;not null"`
+	Account          string    `gorm:"column:account;type:varchar(66);not null;index:idx_funding_payments_account"`
+	Market           string    `gorm:"column:market;type:varchar(66);not null"`
+	Amount           int64     `gorm:"column:amount;type:bigint;not null"`
+}
+
+func (s *FundingPayment) TableName() string {
+	return "FUNDING_PAYMENTS"
+}
+
+func InsertFundingPayments(conn *gorm.DB, payments []FundingPayment) error {
+	return conn.Clauses(clause.OnConflict{DoNothing: true}).Create(&payments).Error
+}
+
+type TraderFunding struct {
+	Market   string `gorm:"column:market"`
+	Paid     int64  `gorm:"column:paid"`
+	Received int64  `gorm:"column:received"`
+}
+
+// GetTraderFundings sums the realized funding of an account per market.
+func GetTraderFundings(conn *gorm.DB, account string) ([]TraderFunding, error) {
+	var fundings []TraderFunding
+	if err := conn.
+		Model(&FundingPayment{}).
+		Select(
+			"market, "+
+				"COALESCE(SUM(CASE WHEN amount > 0 THEN amount ELSE 0 END), 0) AS paid, "+
+				"COALESCE(SUM(CASE WHEN amount < 0 THEN -amount ELSE 0 END), 0) AS received",
+		).
+		Where("account = ?", account).
+		Group("market").
+		Order("market").
+		Scan(&fundings).Error; err != nil {
+		return nil, err
+	}
+	return fundings, nil
+}
@@ -54,3 +54,21 @@ func UpsertIndexerState(conn *gorm.DB, state IndexerState) error {
 		Create(&state).
 		Error
 }
+
+// SetIndexerState overwrites the state of a processor, even when it moves the
+// checkpoint backwards.
+func SetIndexerState(conn *gorm.DB, state IndexerState) error {
+	return conn.
+		Clauses(
+			clause.OnConflict{
+				Columns: []clause.Column{{Name: "processor_name"}},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"last_processed_version",
+					"last_processed_timestamp",
+					"updated_at",
+				}),
+			},
+		).
+		Create(&state).
+		Error
+}
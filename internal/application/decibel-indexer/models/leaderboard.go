@@ -0,0 +1,140 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LeaderboardWindows are the PnL windows traders are ranked over, by name,
+// with the column prefix of their TRADER_ANALYTICS figures.
+var LeaderboardWindows = map[string]string{
+	"1d":  "daily",
+	"7d":  "weekly",
+	"30d": "monthly",
+	"all": "all_time",
+}
+
+// LeaderboardMetrics are what traders are ranked by.
+var LeaderboardMetrics = []string{"pnl", "roi"}
+
+// LeaderboardEntry is the rank of an account in a leaderboard snapshot.
+// Value is the figure ranked by, Pnl and Roi are over the window. The window
+// column is named pnl_window as WINDOW is reserved in SQL.
+type LeaderboardEntry struct {
+	Window  string    `gorm:"primaryKey;column:pnl_window;type:varchar(8);not null;index:idx_leaderboard_rank,priority:1"`
+	Metric  string    `gorm:"primaryKey;column:metric;type:varchar(8);not null;index:idx_leaderboard_rank,priority:2"`
+	At      time.Time `gorm:"primaryKey;column:at;type:timestamp;not null;index:idx_leaderboard_rank,priority:3"`
+	Account string    `gorm:"primaryKey;column:account;type:varchar(66);not null"`
+	Rank    int       `gorm:"column:rank;type:int;not null;index:idx_leaderboard_rank,priority:4"`
+	Value   float64   `gorm:"column:value;type:double precision;not null"`
+	Pnl     float64   `gorm:"column:pnl;type:double precision;not null"`
+	Roi     float64   `gorm:"column:roi;type:double precision;not null"`
+	Equity  float64   `gorm:"column:equity;type:double precision;not null"`
+}
+
+func (s *LeaderboardEntry) TableName() string {
+	return "LEADERBOARD"
+}
+
+// SnapshotLeaderboards ranks the top size traders of every window and
+// metric at at, replacing a snapshot taken at the same time. Only traders
+// with at least minRoiEquity USDC are ranked by ROI, so tiny accounts do
+// not top it.
+func SnapshotLeaderboards(conn *gorm.DB, at time.Time, size int, minRoiEquity float64) error {
+	return conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("at = ?", at).Delete(&LeaderboardEntry{}).Error; err != nil {
+			return err
+		}
+		for window, prefix := range LeaderboardWindows {
+			for _, metric := range LeaderboardMetrics {
+				column := prefix + "_" + metric
+				minEquity := 0.0
+				if metric == "roi" {
+					minEquity = minRoiEquity
+				}
+				err := tx.Exec(fmt.Sprintf(`
+INSERT INTO "LEADERBOARD" (pnl_window, metric, at, account, rank, value, pnl, roi, equity)
+SELECT ?, ?, ?, account, ROW_NUMBER() OVER (ORDER BY %[1]s DESC, account), %[1]s, %[2]s_pnl, %[2]s_roi, equity
+FROM "TRADER_ANALYTICS"
+WHERE equity >= ? AND %[2]s_pnl <> 0
+ORDER BY %[1]s DESC, account
+LIMIT ?`, column, prefix),
+					window, metric, at, minEquity, size,
+				).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// PruneLeaderboards drops the snapshots before before except the first of
+// every day, which are kept for the rank history.
+func PruneLeaderboards(conn *gorm.DB, before time.Time) error {
+	return conn.
+		Where("at < ? AND at <> date_trunc('day', at)", before).
+		Delete(&LeaderboardEntry{}).Error
+}
+
+// GetLeaderboardTime returns the time of the latest snapshot of a
+// leaderboard taken at or before at.
+func GetLeaderboardTime(conn *gorm.DB, window, metric string, at time.Time) (time.Time, bool, error) {
+	var times []time.Time
+	if err := conn.
+		Model(&LeaderboardEntry{}).
+		Where("pnl_window = ? AND metric = ? AND at <= ?", window, metric, at).
+		Order("at DESC").
+		Limit(1).
+		Pluck("at", &times).Error; err != nil {
+		return time.Time{}, false, err
+	}
+	if len(times) == 0 {
+		return time.Time{}, false, nil
+	}
+	return times[0], true, nil
+}
+
+// GetLeaderboard returns a page of a leaderboard snapshot by rank.
+func GetLeaderboard(conn *gorm.DB, window, metric string, at time.Time, offset, limit int) ([]LeaderboardEntry, error) {
+	var entries []LeaderboardEntry
+	if err := conn.
+		Where("pnl_window = ? AND metric = ? AND at = ?", window, metric, at).
+		Order("rank").
+		Offset(offset).
+		Limit(limit).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// CountLeaderboard returns the number of traders in a leaderboard snapshot.
+func CountLeaderboard(conn *gorm.DB, window, metric string, at time.Time) (int64, error) {
+	var count int64
+	err := conn.
+		Model(&LeaderboardEntry{}).
+		Where("pnl_window = ? AND metric = ? AND at = ?", window, metric, at).
+		Count(&count).Error
+	return count, err
+}
+
+// GetLeaderboardRanks returns the ranks of accounts in a leaderboard
+// snapshot. Accounts outside of it are missing.
+func GetLeaderboardRanks(conn *gorm.DB, window, metric string, at time.Time, accounts []string) (map[string]int, error) {
+	var entries []LeaderboardEntry
+	if err := conn.
+		Select("account, rank").
+		Where("pnl_window = ? AND metric = ? AND at = ? AND account IN ?", window, metric, at, accounts).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	ranks := make(map[string]int, len(entries))
+	for _, e := range entries {
+		ranks[e.Account] = e.Rank
+	}
+	return ranks, nil
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LiquidationChannel is the redis channel the indexer publishes every newly
+// indexed liquidation to, JSON encoded.
+const LiquidationChannel = "decibel:liquidation"
+
+const (
+	// LiquidationKindMarket is a position closed against the order book.
+	LiquidationKindMarket = "market"
+	// LiquidationKindBackstop is a position taken over by the backstop liquidator vault.
+	LiquidationKindBackstop = "backstop"
+)
+
+type Liquidation struct {
+	Version          uint64       `gorm:"primaryKey;column:version;type:numeric;not null" json:"version"`
+	EventIndex       int          `gorm:"primaryKey;column:event_index;type:int;not null" json:"event_index"`
+	VersionTimestamp time.Time    `gorm:"column:version_timestamp;type:timestamp;not null;index:idx_liquidations_timestamp" json:"version_timestamp"`
+	Account          string       `gorm:"column:account;type:varchar(66);not null;index:idx_liquidations_account" json:"account"`
+	Market           string       `gorm:"column:market;type:varchar(66);not null;index:idx_liquidations_market" json:"market"`
+	Kind             string       `gorm:"column:kind;type:varchar(16);not null" json:"kind"`
+	IsLong           bool         `gorm:"column:is_long;type:bool;not null" json:"is_long"`
+	Size             types.Uint64 `gorm:"column:size;type:decimal(20,0);not null" json:"size"`
+	Price            types.Uint64 `gorm:"column:price;type:decimal(20,0);not null" json:"price"`
+	// Loss is the realized loss of the closing trade and CoveredLoss the part
+	// of it the backstop liquidator covered. Margin is the liquidation margin
+	// of the account when it was liquidated.
+	Loss        types.Uint64 `gorm:"column:loss;type:decimal(20,0);not null" json:"loss"`
+	CoveredLoss types.Uint64 `gorm:"column:covered_loss;type:decimal(20,0);not null;default:0" json:"covered_loss"`
+	Margin      types.Uint64 `gorm:"column:margin;type:decimal(20,0);not null;default:0" json:"margin"`
+	Fee         types.Uint64 `gorm:"column:fee;type:decimal(20,0);not null" json:"fee"`
+}
+
+func (s *Liquidation) TableName() string {
+	return "LIQUIDATIONS"
+}
+
+func InsertLiquidations(conn *gorm.DB, liquidations []Liquidation) error {
+	return conn.Clauses(clause.OnConflict{DoNothing: true}).Create(&liquidations).Error
+}
+
+type LiquidationFilter struct {
+	Market  string
+	Account string
+	Kind    string
+	From    time.Time
+	To      time.Time
+	Offset  int
+	Limit   int
+}
+
+// GetLiquidations returns the matching liquidations, newest first, and the
+// total number of matches.
+func GetLiquidations(conn *gorm.DB, filter LiquidationFilter) ([]Liquidation, int64, error) {
+	query := conn.Model(&Liquidation{})
+	if filter.Market != "" {
+		query = query.Where("market = ?", filter.Market)
+	}
+	if filter.Account != "" {
+		query = query.Where("account = ?", filter.Account)
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("version_timestamp >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("version_timestamp < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var liquidations []Liquidation
+	if err := query.
+		Order("version DESC, event_index DESC").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&liquidations).Error; err != nil {
+		return nil, 0, err
+	}
+	return liquidations, total, nil
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"math"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CollateralDecimals is the number of decimals of the USDC collateral that
+// pnl, margin and funding amounts are settled in.
+const CollateralDecimals = 6
+
+type Market struct {
+	Address       string `gorm:"primaryKey;column:address;type:varchar(66);not null" yaml:"address" json:"address"`
+	Name          string `gorm:"column:name;type:varchar(64);not null" yaml:"name" json:"name"`
+	SizeDecimals  int    `gorm:"column:size_decimals;type:int;not null" yaml:"size_decimals" json:"size_decimals"`
+	PriceDecimals int    `gorm:"column:price_decimals;type:int;not null" yaml:"price_decimals" json:"price_decimals"`
+}
+
+func (s *Market) TableName() string {
+	return "MARKETS"
+}
+
+// Size converts an on-chain size to base asset units.
+func (s Market) Size(v types.Uint64) float64 {
+	return float64(v) / math.Pow10(s.SizeDecimals)
+}
+
+// Price converts an on-chain price to quote units.
+func (s Market) Price(v types.Uint64) float64 {
+	return float64(v) / math.Pow10(s.PriceDecimals)
+}
+
+// Collateral converts an on-chain collateral amount to USDC.
+func Collateral(v types.Uint64) float64 {
+	return float64(v) / math.Pow10(CollateralDecimals)
+}
+
+func GetMarkets(conn *gorm.DB) ([]Market, error) {
+	var markets []Market
+	if err := conn.Order("address").Find(&markets).Error; err != nil {
+		return nil, err
+	}
+	return markets, nil
+}
+
+func UpsertMarkets(conn *gorm.DB, markets []Market) error {
+	if len(markets) == 0 {
+		return nil
+	}
+	return conn.Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "address"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "size_decimals", "price_decimals"}),
+		},
+	).Create(&markets).Error
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PriceTickChannel is the redis channel the indexer publishes a market
+// address to whenever that market's oracle or mark price changes.
+const PriceTickChannel = "decibel:price_tick"
+
+type MarketPrice struct {
+	Market           string        `gorm:"primaryKey;column:market;type:varchar(66);not null"`
+	Version          uint64        `gorm:"column:version;type:numeric;not null"`
+	VersionTimestamp time.Time     `gorm:"column:version_timestamp;type:timestamp;not null"`
+	OraclePx         types.Uint64  `gorm:"column:oracle_px;type:decimal(20,0);not null"`
+	MarkPx           types.Uint64  `gorm:"column:mark_px;type:decimal(20,0);not null"`
+	FundingIndex     types.Uint128 `gorm:"column:funding_index;type:decimal(39,0);not null"`
+}
+
+func (s *MarketPrice) FromPrice(
+	market string,
+	version uint64,
+	versionTimestamp time.Time,
+	value types.Price,
+) {
+	s.Market = market
+	s.Version = version
+	s.VersionTimestamp = versionTimestamp
+	s.OraclePx = value.OraclePx
+	s.MarkPx = value.MarkPx
+	s.FundingIndex = value.AccumulativeIndex.Index
+}
+
+func (s *MarketPrice) TableName() string {
+	return "MARKET_PRICES"
+}
+
+func GetMarketPrices(conn *gorm.DB) ([]MarketPrice, error) {
+	var prices []MarketPrice
+	if err := conn.Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+func UpsertMarketPrices(conn *gorm.DB, prices []MarketPrice) error {
+	return conn.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "market"}},
+			Where: clause.Where{
+				Exprs: []clause.Expression{
+					clause.Expr{
+						SQL: "EXCLUDED.version > \"MARKET_PRICES\".version",
+					},
+				},
+			},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"version",
+				"version_timestamp",
+				"oracle_px",
+				"mark_px",
+				"funding_index",
+			}),
+		},
+	).Create(&prices).Error
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+)
+
+// OpenInterestResolution is the bucket the open interest is recorded in.
+// Every batch overwrites the bucket it ends in.
+const OpenInterestResolution = time.Minute
+
+// OpenInterest is the open positions of a market at the end of a bucket.
+// Every long has a short on the other side, so the sizes only differ by
+// rounding; the trader counts tell the sentiment. MarkPx is the mark price
+// the indexer last saw when the bucket was written.
+type OpenInterest struct {
+	Market       string       `gorm:"primaryKey;column:market;type:varchar(66);not null"`
+	Time         time.Time    `gorm:"primaryKey;column:time;type:timestamp;not null"`
+	Version      uint64       `gorm:"column:version;type:numeric;not null"`
+	LongSize     types.Uint64 `gorm:"column:long_size;type:decimal(20,0);not null"`
+	ShortSize    types.Uint64 `gorm:"column:short_size;type:decimal(20,0);not null"`
+	LongTraders  int64        `gorm:"column:long_traders;type:bigint;not null"`
+	ShortTraders int64        `gorm:"column:short_traders;type:bigint;not null"`
+	MarkPx       types.Uint64 `gorm:"column:mark_px;type:decimal(20,0);not null"`
+}
+
+func (s *OpenInterest) TableName() string {
+	return "OPEN_INTEREST"
+}
+
+// RecordOpenInterest aggregates the open positions of markets as of
+// version into the bucket of timestamp. Only the touched markets are read,
+// through the (market, size) index of the positions.
+func RecordOpenInterest(conn *gorm.DB, markets []string, version uint64, timestamp time.Time) error {
+	if len(markets) == 0 {
+		return nil
+	}
+	return conn.Exec(`
+INSERT INTO "OPEN_INTEREST" (market, time, version, long_size, short_size, long_traders, short_traders, mark_px)
+SELECT
+	m.market,
+	?,
+	?,
+	COALESCE(SUM(p.size) FILTER (WHERE p.is_long), 0),
+	COALESCE(SUM(p.size) FILTER (WHERE NOT p.is_long), 0),
+	COUNT(DISTINCT p.owner) FILTER (WHERE p.is_long),
+	COUNT(DISTINCT p.owner) FILTER (WHERE NOT p.is_long),
+	COALESCE(MAX(mp.mark_px), 0)
+FROM (SELECT DISTINCT market FROM "PERP_POSITIONS" WHERE market IN ?) m
+LEFT JOIN "PERP_POSITIONS" p ON p.market = m.market AND p.size > 0
+LEFT JOIN "MARKET_PRICES" mp ON mp.market = m.market
+GROUP BY m.market
+ON CONFLICT (market, time) DO UPDATE SET
+	version = EXCLUDED.version,
+	long_size = EXCLUDED.long_size,
+	short_size = EXCLUDED.short_size,
+	long_traders = EXCLUDED.long_traders,
+	short_traders = EXCLUDED.short_traders,
+	mark_px = EXCLUDED.mark_px
+WHERE EXCLUDED.version >= "OPEN_INTEREST".version`,
+		timestamp.UTC().Truncate(OpenInterestResolution), version, markets,
+	).Error
+}
+
+// GetOpenInterest returns the last open interest of every interval of a
+// market in [from, to), oldest first, with Time set to the interval start.
+func GetOpenInterest(conn *gorm.DB, market string, from, to time.Time, interval time.Duration) ([]OpenInterest, error) {
+	seconds := int64(interval / time.Second)
+	var rows []OpenInterest
+	if err := conn.Raw(`
+SELECT DISTINCT ON (o.bucket)
+	o.market, o.bucket AS time, o.version, o.long_size, o.short_size, o.long_traders, o.short_traders, o.mark_px
+FROM (
+	SELECT *, to_timestamp((floor(extract(epoch FROM time) / ?) * ?)::float8) AT TIME ZONE 'UTC' AS bucket
+	FROM "OPEN_INTEREST"
+	WHERE market = ? AND time >= ? AND time < ?
+) o
+ORDER BY o.bucket, o.time DESC`,
+		seconds, seconds, market, from, to,
+	).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// GetLatestOpenInterest returns the latest open interest of every market.
+func GetLatestOpenInterest(conn *gorm.DB) ([]OpenInterest, error) {
+	var rows []OpenInterest
+	if err := conn.Raw(`
+SELECT DISTINCT ON (market) *
+FROM "OPEN_INTEREST"
+ORDER BY market, time DESC`,
+	).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TraderPnl is the PnL an account realized over an hour, in raw collateral
+// units. Fees are net of rebates and funding is positive when paid, so the
+// net PnL is RealizedPnl - Fees - Funding.
+type TraderPnl struct {
+	Account     string    `gorm:"primaryKey;column:account;type:varchar(66);not null"`
+	Hour        time.Time `gorm:"primaryKey;column:hour;type:timestamp;not null"`
+	RealizedPnl int64     `gorm:"column:realized_pnl;type:bigint;not null"`
+	Fees        int64     `gorm:"column:fees;type:bigint;not null"`
+	Funding     int64     `gorm:"column:funding;type:bigint;not null"`
+	Trades      int64     `gorm:"column:trades;type:bigint;not null"`
+}
+
+func (s *TraderPnl) TableName() string {
+	return "TRADER_PNL"
+}
+
+// Net is the PnL after fees and funding.
+func (s TraderPnl) Net() int64 {
+	return s.RealizedPnl - s.Fees - s.Funding
+}
+
+// RefreshTraderPnl recomputes the hourly PnL of [from, to) from the trades,
+// so reprocessing a batch never double counts.
+func RefreshTraderPnl(conn *gorm.DB, from, to time.Time) error {
+	return conn.Exec(`
+INSERT INTO "TRADER_PNL" (account, hour, realized_pnl, fees, funding, trades)
+SELECT
+	account,
+	date_trunc('hour', version_timestamp),
+	SUM(CASE WHEN is_profit THEN realized_pnl ELSE -realized_pnl END),
+	SUM(CASE WHEN is_rebate THEN -fee ELSE fee END),
+	SUM(CASE WHEN is_funding_positive THEN realized_funding ELSE -realized_funding END),
+	COUNT(*)
+FROM "TRADES"
+WHERE version_timestamp >= ? AND version_timestamp < ?
+GROUP BY 1, 2
+ON CONFLICT (account, hour) DO UPDATE SET
+	realized_pnl = EXCLUDED.realized_pnl,
+	fees = EXCLUDED.fees,
+	funding = EXCLUDED.funding,
+	trades = EXCLUDED.trades`,
+		from.UTC().Truncate(time.Hour), to.UTC().Truncate(time.Hour).Add(time.Hour),
+	).Error
+}
+
+// GetTraderPnl returns the hourly PnL of an account in [from, to), oldest
+// first.
+func GetTraderPnl(conn *gorm.DB, account string, from, to time.Time) ([]TraderPnl, error) {
+	var pnl []TraderPnl
+	if err := conn.
+		Where("account = ? AND hour >= ? AND hour < ?", account, from, to).
+		Order("hour").
+		Find(&pnl).Error; err != nil {
+		return nil, err
+	}
+	return pnl, nil
+}
+
+// GetTraderPnlBefore returns the net PnL an account realized before an hour,
+// which seeds cumulative PnL series that start later.
+func GetTraderPnlBefore(conn *gorm.DB, account string, before time.Time) (int64, error) {
+	var net int64
+	if err := conn.
+		Model(&TraderPnl{}).
+		Select("COALESCE(SUM(realized_pnl - fees - funding), 0)").
+		Where("account = ? AND hour < ?", account, before).
+		Scan(&net).Error; err != nil {
+		return 0, err
+	}
+	return net, nil
+}
+
+// GetTradersPnl returns the hourly PnL of accounts before to by account,
+// oldest first.
+func GetTradersPnl(conn *gorm.DB, accounts []string, to time.Time) (map[string][]TraderPnl, error) {
+	var pnl []TraderPnl
+	if err := conn.
+		Where("account IN ? AND hour < ?", accounts, to).
+		Order("account, hour").
+		Find(&pnl).Error; err != nil {
+		return nil, err
+	}
+	byAccount := make(map[string][]TraderPnl)
+	for _, p := range pnl {
+		byAccount[p.Account] = append(byAccount[p.Account], p)
+	}
+	return byAccount, nil
+}
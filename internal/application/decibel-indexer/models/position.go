@@ -10,12 +10,12 @@ import (
 
 type PerpPosition struct {
 	PositionAddress                         string              `gorm:"primaryKey;column:address;type:varchar(66);not null"`
-	Market                                  string              `gorm:"primaryKey;column:market;type:varchar(66);not null"`
+	Market                                  string              `gorm:"primaryKey;column:market;type:varchar(66);not null;index:idx_perp_positions_market_size,priority:1"`
 	IsCrossed                               bool                `gorm:"primaryKey;column:is_crossed;type:bool;not null"`
 	Version                                 uint64              `gorm:"column:version;type:numeric;not null"`
 	VersionTimestamp                        time.Time           `gorm:"column:version_timestamp;type:timestamp;not null"`
 	Owner                                   string              `gorm:"column:owner;type:varchar(66);not null"`
-	Size                                    types.Uint64        `gorm:"column:size;type:decimal(20,0);not null"`
+	Size                                    types.Uint64        `gorm:"column:size;type:decimal(20,0);not null;index:idx_perp_positions_market_size,priority:2"`
 	EntryPxTimesSizeSum                     types.Uint128       `gorm:"column:entry_px_times_size_sum;type:decimal(39,0);not null"`
 	AvgAcquireEntryPx                       types.Uint64        `gorm:"column:avg_acquire_entry_px;type:decimal(20,0);not null"`
 	UserLeverage                            int                 `gorm:"column:user_leverage;type:int;not null"`
@@ -39,7 +39,7 @@ func (s *PerpPosition) FromPerpPosition(
 	s.PositionAddress = positionAddress
 	s.Version = version
 	s.VersionTimestamp = versionTimestamp
-	s.Owner = owner
+	s.Owner = types.NormalizeAddress(owner)
 	s.IsCrossed = isCrossed
 	s.Market = value.Market.Inner
 	s.Size = value.Size
@@ -94,6 +94,8 @@ func UpsertPositions(conn *gorm.DB, positions []PerpPosition) error {
 
 func (p PerpPosition) toAssignmentColumns() []string {
 	return []string{
+		"version",
+		"version_timestamp",
 		"owner",
 		"market",
 		"size",
@@ -112,6 +114,8 @@ func (p PerpPosition) toAssignmentColumns() []string {
 
 func (p PerpPosition) toAssignments() map[string]any {
 	return map[string]any{
+		"version":                      p.Version,
+		"version_timestamp":            p.VersionTimestamp,
 		"owner":                        p.Owner,
 		"market":                       p.Market,
 		"size":                         p.Size,
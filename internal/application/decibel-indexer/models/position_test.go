@@ -0,0 +1,33 @@
+package models
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestPositionAssignmentsAdvanceVersion(t *testing.T) {
+	p := PerpPosition{Version: 42, VersionTimestamp: time.Unix(1700000000, 0)}
+
+	assignments := p.toAssignments()
+	if assignments["version"] != uint64(42) || !assignments["version_timestamp"].(time.Time).Equal(p.VersionTimestamp) {
+		t.Errorf("version assignments = %v/%v, want 42/%v", assignments["version"], assignments["version_timestamp"], p.VersionTimestamp)
+	}
+
+	columns := p.toAssignmentColumns()
+	keys := make([]string, 0, len(assignments))
+	for k := range assignments {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	sorted := append([]string(nil), columns...)
+	sort.Strings(sorted)
+	if len(sorted) != len(keys) {
+		t.Fatalf("assignment columns = %v, want %v", sorted, keys)
+	}
+	for i := range keys {
+		if sorted[i] != keys[i] {
+			t.Fatalf("assignment columns = %v, want %v", sorted, keys)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+)
+
+const (
+	TriggerKindTakeProfit = "tp"
+	TriggerKindStopLoss   = "sl"
+	TriggerKindReduceOnly = "reduce_only"
+)
+
+// PositionTrigger is a pending take-profit, stop-loss or reduce-only order of
+// a position. Prices and size are raw market units; a zero limit price is a
+// market order, and a zero size closes the full position or is unknown.
+type PositionTrigger struct {
+	PositionAddress  string        `gorm:"primaryKey;column:address;type:varchar(66);not null"`
+	Market           string        `gorm:"primaryKey;column:market;type:varchar(66);not null"`
+	IsCrossed        bool          `gorm:"primaryKey;column:is_crossed;type:bool;not null"`
+	OrderID          types.Uint128 `gorm:"primaryKey;column:order_id;type:decimal(39,0);not null"`
+	Version          uint64        `gorm:"column:version;type:numeric;not null"`
+	VersionTimestamp time.Time     `gorm:"column:version_timestamp;type:timestamp;not null"`
+	Owner            string        `gorm:"column:owner;type:varchar(66);not null;index:idx_position_triggers_owner"`
+	Kind             string        `gorm:"column:kind;type:varchar(16);not null"`
+	IsFullSize       bool          `gorm:"column:is_full_size;type:bool;not null"`
+	TriggerPrice     types.Uint64  `gorm:"column:trigger_price;type:decimal(20,0);not null"`
+	LimitPrice       types.Uint64  `gorm:"column:limit_price;type:decimal(20,0);not null"`
+	Size             types.Uint64  `gorm:"column:size;type:decimal(20,0);not null"`
+}
+
+func (s *PositionTrigger) TableName() string {
+	return "POSITION_TRIGGERS"
+}
+
+// TriggersFromPosition normalizes the pending orders of a position. The
+// position only keeps the keys of fixed-sized orders, so their sizes are
+// looked up by order id in sizes.
+func TriggersFromPosition(p PerpPosition, sizes map[string]types.Uint64) []PositionTrigger {
+	var triggers []PositionTrigger
+	add := func(kind string, isFullSize bool, key types.PendingTpSlKey) {
+		t := p.newTrigger(kind, key.OrderID.OrderID)
+		t.IsFullSize = isFullSize
+		t.TriggerPrice = key.PriceIndex.TriggerPrice
+		if len(key.PriceIndex.LimitPrice.Vec) > 0 {
+			t.LimitPrice = key.PriceIndex.LimitPrice.Vec[0]
+		}
+		if !isFullSize {
+			t.Size = sizes[key.OrderID.OrderID.String()]
+		}
+		triggers = append(triggers, t)
+	}
+	for _, side := range []struct {
+		kind string
+		reqs types.PendingTpSLs
+	}{
+		{TriggerKindTakeProfit, p.TpReqs},
+		{TriggerKindStopLoss, p.SlReqs},
+	} {
+		for _, key := range side.reqs.FullSized.Vec {
+			add(side.kind, true, key)
+		}
+		for _, key := range side.reqs.FixedSized {
+			add(side.kind, false, key)
+		}
+	}
+	for _, order := range p.ReduceOnlyOrders {
+		triggers = append(triggers, p.newTrigger(TriggerKindReduceOnly, order.OrderID))
+	}
+	return triggers
+}
+
+func (p PerpPosition) newTrigger(kind string, orderID types.Uint128) PositionTrigger {
+	return PositionTrigger{
+		PositionAddress:  p.PositionAddress,
+		Market:           p.Market,
+		IsCrossed:        p.IsCrossed,
+		OrderID:          orderID,
+		Version:          p.Version,
+		VersionTimestamp: p.VersionTimestamp,
+		Owner:            p.Owner,
+		Kind:             kind,
+	}
+}
+
+// ReplacePositionTriggers replaces the triggers of each position with the
+// given ones, unless a newer version of the position was already indexed.
+func ReplacePositionTriggers(conn *gorm.DB, positions []PerpPosition, triggers []PositionTrigger) error {
+	type positionKey struct {
+		address   string
+		market    string
+		isCrossed bool
+	}
+	byPosition := make(map[positionKey][]PositionTrigger)
+	for _, t := range triggers {
+		key := positionKey{t.PositionAddress, t.Market, t.IsCrossed}
+		byPosition[key] = append(byPosition[key], t)
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		for _, p := range positions {
+			where := tx.Where("address = ? AND market = ? AND is_crossed = ?", p.PositionAddress, p.Market, p.IsCrossed).Session(&gorm.Session{})
+
+			var newer int64
+			if err := where.Model(&PerpPosition{}).Where("version > ?", p.Version).Count(&newer).Error; err != nil {
+				return err
+			}
+			if newer > 0 {
+				continue
+			}
+			rows := byPosition[positionKey{p.PositionAddress, p.Market, p.IsCrossed}]
+			if len(rows) > 0 {
+				var stored []PositionTrigger
+				if err := where.Find(&stored).Error; err != nil {
+					return err
+				}
+				keepStoredSizes(rows, stored)
+			}
+			if err := where.Delete(&PositionTrigger{}).Error; err != nil {
+				return err
+			}
+			if len(rows) == 0 {
+				continue
+			}
+			if err := tx.Create(&rows).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// keepStoredSizes fills in the sizes of fixed-sized tp/sl orders the
+// transaction did not report from the stored triggers. Sizes are only
+// reported by the position update of the order's own market, while every
+// write of a crossed position rewrites the triggers of all its markets.
+func keepStoredSizes(triggers, stored []PositionTrigger) {
+	sizes := make(map[string]types.Uint64, len(stored))
+	for _, t := range stored {
+		if t.Size > 0 {
+			sizes[t.OrderID.String()] = t.Size
+		}
+	}
+	for i, t := range triggers {
+		if t.Kind != TriggerKindReduceOnly && !t.IsFullSize && t.Size == 0 {
+			triggers[i].Size = sizes[t.OrderID.String()]
+		}
+	}
+}
+
+// GetPositionTriggers returns the triggers of an owner's positions.
+func GetPositionTriggers(conn *gorm.DB, owner string) ([]PositionTrigger, error) {
+	var triggers []PositionTrigger
+	if err := conn.
+		Where("owner = ?", owner).
+		Order("market, kind, trigger_price").
+		Find(&triggers).Error; err != nil {
+		return nil, err
+	}
+	return triggers, nil
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+)
+
+func TestKeepStoredSizes(t *testing.T) {
+	orderID := func(id int64) types.Uint128 {
+		var u types.Uint128
+		if err := u.Scan(id); err != nil {
+			t.Fatalf("failed to set order id: %v", err)
+		}
+		return u
+	}
+	stored := []PositionTrigger{
+		{OrderID: orderID(1), Kind: TriggerKindTakeProfit, Size: 300},
+		{OrderID: orderID(2), Kind: TriggerKindStopLoss, Size: 400},
+	}
+	triggers := []PositionTrigger{
+		// not reported by the transaction
+		{OrderID: orderID(1), Kind: TriggerKindTakeProfit},
+		// reported with a new size
+		{OrderID: orderID(2), Kind: TriggerKindStopLoss, Size: 100},
+		{OrderID: orderID(3), Kind: TriggerKindStopLoss, IsFullSize: true},
+		{OrderID: orderID(4), Kind: TriggerKindReduceOnly},
+	}
+
+	keepStoredSizes(triggers, stored)
+	for i, want := range []types.Uint64{300, 100, 0, 0} {
+		if triggers[i].Size != want {
+			t.Errorf("trigger %d size = %d, want %d", i, triggers[i].Size, want)
+		}
+	}
+}
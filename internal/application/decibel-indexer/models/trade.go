@@ -0,0 +1,151 @@
+package models
+
+import (
+	"time"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Trade is one side of a fill. Every match emits a trade for both the maker
+// and the taker, at the same price and size.
+type Trade struct {
+	Version          uint64       `gorm:"primaryKey;column:version;type:numeric;not null;index:idx_trades_account_version,priority:2" json:"version"`
+	EventIndex       int          `gorm:"primaryKey;column:event_index;type:int;not null" json:"event_index"`
+	VersionTimestamp time.Time    `gorm:"column:version_timestamp;type:timestamp;not null;index:idx_trades_timestamp;index:idx_trades_market_timestamp,priority:2" json:"version_timestamp"`
+	Account          string       `gorm:"column:account;type:varchar(66);not null;index:idx_trades_account_version,priority:1" json:"account"`
+	Market           string       `gorm:"column:market;type:varchar(66);not null;index:idx_trades_market_timestamp,priority:1" json:"market"`
+	Action           string       `gorm:"column:action;type:varchar(16);not null" json:"action"`
+	Size             types.Uint64 `gorm:"column:size;type:decimal(20,0);not null" json:"size"`
+	Price            types.Uint64 `gorm:"column:price;type:decimal(20,0);not null" json:"price"`
+	IsProfit         bool         `gorm:"column:is_profit;type:bool;not null" json:"is_profit"`
+	RealizedPnl      types.Uint64 `gorm:"column:realized_pnl;type:decimal(20,0);not null" json:"realized_pnl"`
+	// IsFundingPositive is set when the trader paid the realized funding.
+	IsFundingPositive bool         `gorm:"column:is_funding_positive;type:bool;not null" json:"is_funding_positive"`
+	RealizedFunding   types.Uint64 `gorm:"column:realized_funding;type:decimal(20,0);not null" json:"realized_funding"`
+	IsRebate          bool         `gorm:"column:is_rebate;type:bool;not null" json:"is_rebate"`
+	Fee               types.Uint64 `gorm:"column:fee;type:decimal(20,0);not null" json:"fee"`
+	// PositionSize, PositionIsLong and Leverage are the state of the
+	// account's position in the market after the trade.
+	PositionSize   types.Uint64 `gorm:"column:position_size;type:decimal(20,0);not null" json:"position_size"`
+	PositionIsLong bool         `gorm:"column:position_is_long;type:bool;not null" json:"position_is_long"`
+	Leverage       int          `gorm:"column:leverage;type:int;not null" json:"leverage"`
+}
+
+func (s *Trade) FromTradeEvent(
+	version uint64,
+	eventIndex int,
+	versionTimestamp time.Time,
+	value types.TradeEvent,
+) {
+	s.Version = version
+	s.EventIndex = eventIndex
+	s.VersionTimestamp = versionTimestamp
+	s.Account = types.NormalizeAddress(value.Account)
+	s.Market = value.Market.Inner
+	s.Action = string(value.Action)
+	s.Size = value.Size
+	s.Price = value.Price
+	s.IsProfit = value.IsProfit
+	s.RealizedPnl = value.RealizedPnlAmount
+	s.IsFundingPositive = value.IsFundingPositive
+	s.RealizedFunding = value.RealizedFundingAmount
+	s.IsRebate = value.IsRebate
+	s.Fee = value.FeeAmount
+}
+
+// FromPositionUpdateEvent records the position the trade left behind.
+func (s *Trade) FromPositionUpdateEvent(value types.PositionUpdateEvent) {
+	s.PositionSize = value.Size
+	s.PositionIsLong = value.IsLong
+	s.Leverage = value.UserLeverage
+}
+
+// Net is the PnL the trade realized after fees and funding, signed in raw
+// collateral units.
+func (s Trade) Net() int64 {
+	net := int64(s.RealizedPnl)
+	if !s.IsProfit {
+		net = -net
+	}
+	if s.IsRebate {
+		net += int64(s.Fee)
+	} else {
+		net -= int64(s.Fee)
+	}
+	if s.IsFundingPositive {
+		net -= int64(s.RealizedFunding)
+	} else {
+		net += int64(s.RealizedFunding)
+	}
+	return net
+}
+
+func (s *Trade) TableName() string {
+	return "TRADES"
+}
+
+// InsertTrades stores the trades not indexed yet and returns them.
+func InsertTrades(conn *gorm.DB, trades []Trade) ([]Trade, error) {
+	fresh, err := unindexedEvents(conn, "TRADES", trades, func(t Trade) eventKey {
+		return eventKey{t.Version, t.EventIndex}
+	})
+	if err != nil || len(fresh) == 0 {
+		return nil, err
+	}
+	if err := conn.Clauses(clause.OnConflict{DoNothing: true}).Create(&fresh).Error; err != nil {
+		return nil, err
+	}
+	return fresh, nil
+}
+
+// GetAccountTrades returns every trade of an account, oldest first.
+func GetAccountTrades(conn *gorm.DB, account string) ([]Trade, error) {
+	var trades []Trade
+	if err := conn.
+		Where("account = ?", account).
+		Order("version, event_index").
+		Find(&trades).Error; err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+// GetAccountsTrades returns the trades of accounts by account, oldest first.
+func GetAccountsTrades(conn *gorm.DB, accounts []string) (map[string][]Trade, error) {
+	var trades []Trade
+	if err := conn.
+		Where("account IN ?", accounts).
+		Order("account, version, event_index").
+		Find(&trades).Error; err != nil {
+		return nil, err
+	}
+	byAccount := make(map[string][]Trade)
+	for _, t := range trades {
+		byAccount[t.Account] = append(byAccount[t.Account], t)
+	}
+	return byAccount, nil
+}
+
+// GetLastTradeVersions returns the version of the last trade of accounts by
+// account. Accounts without trades are left out.
+func GetLastTradeVersions(conn *gorm.DB, accounts []string) (map[string]uint64, error) {
+	var rows []struct {
+		Account string
+		Version uint64
+	}
+	if err := conn.
+		Model(&Trade{}).
+		Select("account, MAX(version) AS version").
+		Where("account IN ?", accounts).
+		Group("account").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	versions := make(map[string]uint64, len(rows))
+	for _, r := range rows {
+		versions[r.Account] = r.Version
+	}
+	return versions, nil
+}
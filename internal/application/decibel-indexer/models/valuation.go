@@ -0,0 +1,77 @@
+package models
+
+import (
+	"math"
+	"math/big"
+)
+
+// DefaultFundingIndexDecimals is the decimals of the funding index scale
+// unless configured otherwise. The accumulative index of perp_positions
+// starts at 2^127, so it can move both ways, and grows by the funding per
+// size unit in collateral units times 10^decimals. The contract does not
+// expose the scale, so it is configured rather than read from chain.
+const DefaultFundingIndexDecimals = 12
+
+// FundingIndexScale returns the scale that converts `size * funding index
+// delta` into collateral units, 10^decimals or the default if decimals is
+// not positive.
+func FundingIndexScale(decimals int) *big.Int {
+	if decimals <= 0 {
+		decimals = DefaultFundingIndexDecimals
+	}
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+}
+
+// PositionValue is an open position valued at a mark price.
+type PositionValue struct {
+	Size       float64
+	EntryPrice float64
+	Notional   float64
+	// PnL is the price PnL at mark, positive when in profit.
+	PnL float64
+	// Funding is the funding accrued but not settled yet, positive when the
+	// trader owes it.
+	Funding float64
+}
+
+// UnrealizedPnL is the PnL the position would realize if closed at mark.
+func (v PositionValue) UnrealizedPnL() float64 {
+	return v.PnL - v.Funding
+}
+
+// ValuePosition values a position at markPx. fundingIndex is the current
+// funding index of the market; without one, e.g. for a price from an
+// external venue, only the funding accrued before the last update counts.
+func ValuePosition(market Market, p PerpPosition, markPx float64, fundingIndex, fundingScale *big.Int) PositionValue {
+	v := PositionValue{Size: market.Size(p.Size)}
+	if p.Size > 0 {
+		entryPx, _ := new(big.Float).Quo(
+			new(big.Float).SetInt(p.EntryPxTimesSizeSum.BigInt()),
+			new(big.Float).SetInt(p.Size.BigInt()),
+		).Float64()
+		v.EntryPrice = entryPx / math.Pow10(market.PriceDecimals)
+	}
+	v.Notional = v.Size * markPx
+
+	v.PnL = (markPx - v.EntryPrice) * v.Size
+	if !p.IsLong {
+		v.PnL = -v.PnL
+	}
+
+	v.Funding = Collateral(p.UnrealizedFundingAmountBeforeLastUpdate.Amount)
+	if !p.UnrealizedFundingAmountBeforeLastUpdate.IsPositive {
+		v.Funding = -v.Funding
+	}
+	if fundingIndex != nil {
+		delta := new(big.Int).Sub(fundingIndex, p.FundingIndexAtLastUpdate.BigInt())
+		delta.Mul(delta, p.Size.BigInt())
+		delta.Quo(delta, fundingScale)
+		accrued, _ := new(big.Float).SetInt(delta).Float64()
+		accrued /= math.Pow10(CollateralDecimals)
+		if !p.IsLong {
+			accrued = -accrued
+		}
+		v.Funding += accrued
+	}
+	return v
+}
@@ -0,0 +1,189 @@
+package decibelindexer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aptos-labs/aptos-go-sdk/api"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"gorm.io/gorm"
+)
+
+const positionProcessorName = "positions"
+
+// positionProcessor indexes perp positions and their pending tp/sl and
+// reduce-only orders, and records the open interest of the markets they
+// changed.
+type positionProcessor struct {
+	db *gorm.DB
+	// skipOpenInterest leaves the open interest alone, e.g. in a backfill
+	skipOpenInterest bool
+}
+
+func newPositionProcessor(db *gorm.DB) *positionProcessor {
+	return &positionProcessor{db: db}
+}
+
+func (proc *positionProcessor) Name() string {
+	return positionProcessorName
+}
+
+func (proc *positionProcessor) ResourceTypes() []string {
+	return []string{crossedPosition, isolatedPosition}
+}
+
+func (proc *positionProcessor) EventTypes() []string {
+	return nil
+}
+
+func (proc *positionProcessor) Process(ctx context.Context, txs []*api.UserTransaction) error {
+	touched := make(map[string]bool)
+	var last *api.UserTransaction
+	for _, tx := range txs {
+		_, writeResources, _, _ := types.ExtractWriteSetChange(tx)
+		var exist bool
+		var objectCoreIndexs []int
+		crossedPositions := make(map[string]types.CrossedPosition)
+		isolatedPositions := make(map[string]types.IsolatedPosition)
+
+		for idx, writeResource := range writeResources {
+			switch writeResource.Data.Type {
+			case crossedPosition:
+				var crossedPosition types.CrossedPosition
+				if err := MapToStructJSON(writeResource.Data.Data, &crossedPosition); err != nil {
+					return err
+				}
+				crossedPositions[writeResource.Address.StringLong()] = crossedPosition
+				exist = true
+			case isolatedPosition:
+				var isolatedPosition types.IsolatedPosition
+				if err := MapToStructJSON(writeResource.Data.Data, &isolatedPosition); err != nil {
+					return err
+				}
+				isolatedPositions[writeResource.Address.StringLong()] = isolatedPosition
+				exist = true
+			case objectCore:
+				objectCoreIndexs = append(objectCoreIndexs, idx)
+			}
+		}
+		if !exist {
+			continue
+		}
+
+		addressOwnerMapping := make(map[string]string, len(objectCoreIndexs))
+		for _, objectCoreIndex := range objectCoreIndexs {
+			wr := writeResources[objectCoreIndex]
+			var objectCore types.ObjectCore
+			if err := MapToStructJSON(wr.Data.Data, &objectCore); err != nil {
+				return err
+			}
+			addressOwnerMapping[wr.Address.StringLong()] = objectCore.Owner
+		}
+
+		positions := make(map[string]map[string]models.PerpPosition)
+
+		for positionAddress, crossedPosition := range crossedPositions {
+			for _, position := range crossedPosition.Positions {
+				var row models.PerpPosition
+				row.FromPerpPosition(positionAddress, tx.Version, time.UnixMicro(int64(tx.Timestamp)), positionAddress, true, position)
+				if _, ok := positions[positionAddress]; !ok {
+					positions[positionAddress] = make(map[string]models.PerpPosition)
+				}
+				if old, ok := positions[positionAddress][position.Market.Inner]; ok {
+					if old.Version < tx.Version {
+						positions[positionAddress][position.Market.Inner] = row
+					}
+				} else {
+					positions[positionAddress][position.Market.Inner] = row
+				}
+			}
+		}
+
+		for positionAddress, isolatedPosition := range isolatedPositions {
+			owner, ok := addressOwnerMapping[positionAddress]
+			if !ok {
+				return errors.New("owner not found, " + positionAddress + ", " + strconv.FormatUint(tx.Version, 10))
+			}
+			var row models.PerpPosition
+			row.FromPerpPosition(positionAddress, tx.Version, time.UnixMicro(int64(tx.Timestamp)), owner, false, isolatedPosition.Position)
+			if _, ok := positions[positionAddress]; !ok {
+				positions[positionAddress] = make(map[string]models.PerpPosition)
+			}
+			if old, ok := positions[positionAddress][isolatedPosition.Position.Market.Inner]; ok {
+				if old.Version < tx.Version {
+					positions[positionAddress][isolatedPosition.Position.Market.Inner] = row
+				}
+			} else {
+				positions[positionAddress][isolatedPosition.Position.Market.Inner] = row
+			}
+		}
+		if len(positions) == 0 {
+			continue
+		}
+
+		positionArray := make([]models.PerpPosition, 0, len(positions))
+		for _, arr := range positions {
+			for _, p := range arr {
+				positionArray = append(positionArray, p)
+			}
+		}
+		sort.Slice(positionArray, func(i, j int) bool {
+			if positionArray[i].PositionAddress == positionArray[j].PositionAddress {
+				return positionArray[i].Market < positionArray[j].Market
+			}
+			return positionArray[i].PositionAddress < positionArray[j].PositionAddress
+		})
+		if err := models.UpsertPositions(proc.db.WithContext(ctx), positionArray); err != nil {
+			return err
+		}
+		for _, p := range positionArray {
+			touched[p.Market] = true
+		}
+		last = tx
+
+		sizes, err := triggerSizes(tx)
+		if err != nil {
+			return err
+		}
+		var triggers []models.PositionTrigger
+		for _, p := range positionArray {
+			triggers = append(triggers, models.TriggersFromPosition(p, sizes)...)
+		}
+		if err := models.ReplacePositionTriggers(proc.db.WithContext(ctx), positionArray, triggers); err != nil {
+			return err
+		}
+	}
+
+	if last == nil || proc.skipOpenInterest {
+		return nil
+	}
+	markets := make([]string, 0, len(touched))
+	for market := range touched {
+		markets = append(markets, market)
+	}
+	sort.Strings(markets)
+	return models.RecordOpenInterest(proc.db.WithContext(ctx), markets, last.Version, time.UnixMicro(int64(last.Timestamp)))
+}
+
+// triggerSizes returns the sizes of the fixed-sized tp/sl orders reported by
+// the position updates of a transaction, by order id.
+func triggerSizes(tx *api.UserTransaction) (map[string]types.Uint64, error) {
+	sizes := make(map[string]types.Uint64)
+	for _, event := range types.ExtractEvents(tx) {
+		if event.Type != positionUpdateEvent {
+			continue
+		}
+		var update types.PositionUpdateEvent
+		if err := MapToStructJSON(event.Data, &update); err != nil {
+			return nil, err
+		}
+		for _, order := range append(update.FixedSizedTps, update.FixedSizedSls...) {
+			sizes[order.OrderID.String()] = order.Size
+		}
+	}
+	return sizes, nil
+}
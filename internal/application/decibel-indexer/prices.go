@@ -0,0 +1,76 @@
+package decibelindexer
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/aptos-labs/aptos-go-sdk/api"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"github.com/mediocregopher/radix/v3"
+	"gorm.io/gorm"
+)
+
+// priceProcessor indexes the latest oracle and mark price of each market and
+// publishes a tick for every change.
+type priceProcessor struct {
+	db   *gorm.DB
+	pool *radix.Pool
+}
+
+func newPriceProcessor(db *gorm.DB, pool *radix.Pool) *priceProcessor {
+	return &priceProcessor{db: db, pool: pool}
+}
+
+func (proc *priceProcessor) Name() string {
+	return "prices"
+}
+
+func (proc *priceProcessor) ResourceTypes() []string {
+	return []string{marketPrice}
+}
+
+func (proc *priceProcessor) EventTypes() []string {
+	return nil
+}
+
+func (proc *priceProcessor) Process(ctx context.Context, txs []*api.UserTransaction) error {
+	prices := make(map[string]models.MarketPrice)
+	for _, tx := range txs {
+		_, writeResources, _, _ := types.ExtractWriteSetChange(tx)
+		for _, writeResource := range writeResources {
+			if writeResource.Data.Type != marketPrice {
+				continue
+			}
+			var price types.Price
+			if err := MapToStructJSON(writeResource.Data.Data, &price); err != nil {
+				return err
+			}
+			var row models.MarketPrice
+			row.FromPrice(writeResource.Address.StringLong(), tx.Version, time.UnixMicro(int64(tx.Timestamp)), price)
+			prices[row.Market] = row
+		}
+	}
+	if len(prices) == 0 {
+		return nil
+	}
+
+	priceArray := make([]models.MarketPrice, 0, len(prices))
+	for _, p := range prices {
+		priceArray = append(priceArray, p)
+	}
+	sort.Slice(priceArray, func(i, j int) bool {
+		return priceArray[i].Market < priceArray[j].Market
+	})
+	if err := models.UpsertMarketPrices(proc.db.WithContext(ctx), priceArray); err != nil {
+		return err
+	}
+	for _, p := range priceArray {
+		if err := publish(ctx, proc.pool, models.PriceTickChannel, p.Market); err != nil {
+			slog.Warn("failed to publish price tick", "market", p.Market, "error", err)
+		}
+	}
+	return nil
+}
@@ -1,15 +1,20 @@
 package decibelindexer
 
 import (
-	"errors"
+	"context"
+	"fmt"
 	"log/slog"
-	"sort"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aptos-labs/aptos-go-sdk/api"
 	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
 	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"github.com/cresendoo/decidash-backend/pkg/fullnode"
+	"github.com/cresendoo/decidash-backend/pkg/metrics"
+	"github.com/cresendoo/decidash-backend/pkg/xtrace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -17,128 +22,215 @@ const (
 	crossedPosition      = decibelContract + "::perp_positions::CrossedPosition"
 	isolatedPosition     = decibelContract + "::perp_positions::IsolatedPosition"
 	isolatedPositionRefs = decibelContract + "::perp_positions::IsolatedPositionRefs"
+	marketPrice          = decibelContract + "::price_management::Price"
+	tradeEvent           = decibelContract + "::perp_positions::TradeEvent"
+	positionUpdateEvent  = decibelContract + "::perp_positions::PositionUpdateEvent"
+	liquidationModule    = decibelContract + "::liquidation::"
+	balanceChangeEvent   = decibelContract + "::collateral_balance_sheet::CollateralBalanceChangeEvent"
 	objectCore           = "0x1::object::ObjectCore"
 )
 
-func (a *Application) Process(txs []*api.UserTransaction) error {
-	if len(txs) == 0 {
-		return nil
+// legacyProcessorName is the single checkpoint kept before processors had
+// their own. Processors without a checkpoint resume from it.
+const legacyProcessorName = "decibel-indexer"
+
+// Processor indexes one data set. It only receives the transactions that
+// write one of its resource types or emit one of its event types; a type
+// ending in "::" matches every type with that prefix.
+type Processor interface {
+	Name() string
+	ResourceTypes() []string
+	EventTypes() []string
+	Process(ctx context.Context, txs []*api.UserTransaction) error
+}
+
+type registry struct {
+	processors []Processor
+	byName     map[string]Processor
+}
+
+func newRegistry(processors ...Processor) (*registry, error) {
+	r := &registry{byName: make(map[string]Processor, len(processors))}
+	for _, p := range processors {
+		if _, ok := r.byName[p.Name()]; ok {
+			return nil, fmt.Errorf("duplicate processor %q", p.Name())
+		}
+		r.processors = append(r.processors, p)
+		r.byName[p.Name()] = p
 	}
+	return r, nil
+}
 
-	if err := a.ProcessPositions(txs); err != nil {
-		return err
+func (r *registry) Get(name string) (Processor, bool) {
+	p, ok := r.byName[name]
+	return p, ok
+}
+
+func (r *registry) Names() []string {
+	names := make([]string, 0, len(r.processors))
+	for _, p := range r.processors {
+		names = append(names, p.Name())
 	}
-	stx := txs[0]
-	etx := txs[len(txs)-1]
-	if err := models.UpsertIndexerState(a.db, models.IndexerState{
-		ProcessorName:          "decibel-indexer",
-		LastProcessedVersion:   etx.Version,
-		LastProcessedTimestamp: time.UnixMicro(int64(etx.Timestamp)),
-	}); err != nil {
-		return err
+	return names
+}
+
+// Enabled returns the processors in registration order, leaving out the ones
+// switched off in config. Processors missing from config are enabled.
+func (r *registry) Enabled(config map[string]bool) []Processor {
+	var processors []Processor
+	for _, p := range r.processors {
+		if enabled, ok := config[p.Name()]; ok && !enabled {
+			continue
+		}
+		processors = append(processors, p)
 	}
-	slog.Info("processed transactions", "start", stx.Version, "end", etx.Version, "count", len(txs))
-	return nil
+	return processors
 }
 
-func (a *Application) ProcessPositions(txs []*api.UserTransaction) error {
-	for _, tx := range txs {
+// handles reports whether tx carries any resource or event p is interested in.
+func handles(p Processor, tx *api.UserTransaction) bool {
+	if resourceTypes := p.ResourceTypes(); len(resourceTypes) > 0 {
 		_, writeResources, _, _ := types.ExtractWriteSetChange(tx)
-		var exist bool
-		var objectCoreIndexs []int
-		crossedPositions := make(map[string]types.CrossedPosition)
-		isolatedPositions := make(map[string]types.IsolatedPosition)
-
-		for idx, writeResource := range writeResources {
-			switch writeResource.Data.Type {
-			case crossedPosition:
-				var crossedPosition types.CrossedPosition
-				if err := MapToStructJSON(writeResource.Data.Data, &crossedPosition); err != nil {
-					return err
-				}
-				crossedPositions[writeResource.Address.StringLong()] = crossedPosition
-				exist = true
-			case isolatedPosition:
-				var isolatedPosition types.IsolatedPosition
-				if err := MapToStructJSON(writeResource.Data.Data, &isolatedPosition); err != nil {
-					return err
-				}
-				isolatedPositions[writeResource.Address.StringLong()] = isolatedPosition
-				exist = true
-			case objectCore:
-				objectCoreIndexs = append(objectCoreIndexs, idx)
+		for _, writeResource := range writeResources {
+			if matchesType(resourceTypes, writeResource.Data.Type) {
+				return true
 			}
 		}
-		if !exist {
-			continue
+	}
+	if eventTypes := p.EventTypes(); len(eventTypes) > 0 {
+		for _, event := range tx.Events {
+			if matchesType(eventTypes, event.Type) {
+				return true
+			}
 		}
+	}
+	return false
+}
 
-		addressOwnerMapping := make(map[string]string, len(objectCoreIndexs))
-		for _, objectCoreIndex := range objectCoreIndexs {
-			wr := writeResources[objectCoreIndex]
-			var objectCore types.ObjectCore
-			if err := MapToStructJSON(wr.Data.Data, &objectCore); err != nil {
-				return err
-			}
-			addressOwnerMapping[wr.Address.StringLong()] = objectCore.Owner
+// checkBatch rejects a batch whose versions do not strictly increase, which
+// would otherwise be indexed twice or out of order.
+func checkBatch(txs []*api.UserTransaction) error {
+	for i := 1; i < len(txs); i++ {
+		if txs[i].Version <= txs[i-1].Version {
+			metrics.ConsistencyErrors.WithLabelValues(fullnode.ConsistencyDuplicate).Inc()
+			return fmt.Errorf("batch out of order: version %d after %d", txs[i].Version, txs[i-1].Version)
 		}
+	}
+	return nil
+}
 
-		positions := make(map[string]map[string]models.PerpPosition)
-
-		for positionAddress, crossedPosition := range crossedPositions {
-			for _, position := range crossedPosition.Positions {
-				var row models.PerpPosition
-				row.FromPerpPosition(positionAddress, tx.Version, time.UnixMicro(int64(tx.Timestamp)), positionAddress, true, position)
-				if _, ok := positions[positionAddress]; !ok {
-					positions[positionAddress] = make(map[string]models.PerpPosition)
-				}
-				if old, ok := positions[positionAddress][position.Market.Inner]; ok {
-					if old.Version < tx.Version {
-						positions[positionAddress][position.Market.Inner] = row
-					}
-				} else {
-					positions[positionAddress][position.Market.Inner] = row
-				}
-			}
+// transactionFilter narrows the fetched transactions to those any of the
+// processors may handle; handles still decides per processor.
+func transactionFilter(processors ...Processor) fullnode.TransactionFilter {
+	var filter fullnode.TransactionFilter
+	for _, p := range processors {
+		filter.ResourceTypePrefixes = append(filter.ResourceTypePrefixes, p.ResourceTypes()...)
+		filter.EventTypePrefixes = append(filter.EventTypePrefixes, p.EventTypes()...)
+	}
+	return filter
+}
+
+func matchesType(patterns []string, typ string) bool {
+	for _, pattern := range patterns {
+		if pattern == typ || (strings.HasSuffix(pattern, "::") && strings.HasPrefix(typ, pattern)) {
+			return true
 		}
+	}
+	return false
+}
 
-		for positionAddress, isolatedPosition := range isolatedPositions {
-			owner, ok := addressOwnerMapping[positionAddress]
-			if !ok {
-				return errors.New("owner not found, " + positionAddress + ", " + strconv.FormatUint(tx.Version, 10))
-			}
-			var row models.PerpPosition
-			row.FromPerpPosition(positionAddress, tx.Version, time.UnixMicro(int64(tx.Timestamp)), owner, false, isolatedPosition.Position)
-			if _, ok := positions[positionAddress]; !ok {
-				positions[positionAddress] = make(map[string]models.PerpPosition)
-			}
-			if old, ok := positions[positionAddress][isolatedPosition.Position.Market.Inner]; ok {
-				if old.Version < tx.Version {
-					positions[positionAddress][isolatedPosition.Position.Market.Inner] = row
-				}
-			} else {
-				positions[positionAddress][isolatedPosition.Position.Market.Inner] = row
-			}
+// pendingTransactions returns the transactions from version on that p handles.
+func pendingTransactions(p Processor, txs []*api.UserTransaction, version uint64) []*api.UserTransaction {
+	var pending []*api.UserTransaction
+	for _, tx := range txs {
+		if tx.Version >= version && handles(p, tx) {
+			pending = append(pending, tx)
 		}
-		if len(positions) == 0 {
-			continue
+	}
+	return pending
+}
+
+// checkpoint returns the state a processor resumes from and whether one was
+// saved. Processors without a state of their own resume from the legacy
+// checkpoint, or else from the configured start version.
+func (a *Application) checkpoint(name string) (models.IndexerState, bool, error) {
+	for _, processorName := range []string{name, legacyProcessorName} {
+		state, err := models.GetIndexerState(a.db, processorName)
+		if err != nil {
+			return models.IndexerState{}, false, err
+		}
+		if state.ProcessorName != "" {
+			state.ProcessorName = name
+			return state, true, nil
 		}
+	}
+	return models.IndexerState{ProcessorName: name, LastProcessedVersion: a.cfg.StartVersion}, false, nil
+}
 
-		positionArray := make([]models.PerpPosition, 0, len(positions))
-		for _, arr := range positions {
-			for _, p := range arr {
-				positionArray = append(positionArray, p)
-			}
+// Process hands a batch to every enabled processor and moves each checkpoint
+// to the version and time the batch was scanned up to, even when it holds no
+// transactions. Transactions before a processor's checkpoint are skipped, so
+// processors that are behind catch up without redoing others.
+func (a *Application) Process(batch fullnode.Batch) error {
+	txs := batch.Transactions
+	if err := checkBatch(txs); err != nil {
+		return err
+	}
+	end, endTimestamp := batch.Version, batch.Timestamp
+	first := end
+	if len(txs) > 0 {
+		etx := txs[len(txs)-1]
+		first = txs[0].Version
+		if etx.Version >= end {
+			end, endTimestamp = etx.Version, etx.Timestamp
 		}
-		sort.Slice(positionArray, func(i, j int) bool {
-			if positionArray[i].PositionAddress == positionArray[j].PositionAddress {
-				return positionArray[i].Market < positionArray[j].Market
+	}
+
+	tracer := xtrace.Tracer("indexer")
+	// a batch is finished even when shutdown was requested meanwhile
+	ctx, span := tracer.Start(context.WithoutCancel(a.ctx), "indexer.batch", trace.WithAttributes(
+		attribute.Int64("indexer.start_version", int64(first)),
+		attribute.Int64("indexer.end_version", int64(end)),
+		attribute.Int("indexer.transactions", len(txs)),
+	))
+	var err error
+	defer func() { xtrace.End(span, err) }()
+
+	for _, p := range a.enabled {
+		state := a.states[p.Name()]
+		pending := pendingTransactions(p, txs, state.LastProcessedVersion)
+		if len(pending) > 0 {
+			start := time.Now()
+			processorCtx, processorSpan := tracer.Start(ctx, "indexer.process", trace.WithAttributes(
+				attribute.String("indexer.processor", p.Name()),
+				attribute.Int("indexer.transactions", len(pending)),
+			))
+			err = p.Process(processorCtx, pending)
+			xtrace.End(processorSpan, err)
+			if err != nil {
+				err = fmt.Errorf("processor %s: %w", p.Name(), err)
+				return err
 			}
-			return positionArray[i].PositionAddress < positionArray[j].PositionAddress
-		})
-		if err := models.UpsertPositions(a.db, positionArray); err != nil {
+			metrics.IndexerProcessDuration.WithLabelValues(p.Name()).Observe(time.Since(start).Seconds())
+			metrics.IndexerTransactions.WithLabelValues(p.Name()).Add(float64(len(pending)))
+		}
+		if end <= state.LastProcessedVersion && state.ProcessorName != "" {
+			continue
+		}
+		state.ProcessorName = p.Name()
+		state.LastProcessedVersion = end
+		if endTimestamp > 0 {
+			state.LastProcessedTimestamp = time.UnixMicro(int64(endTimestamp))
+		}
+		if err = models.UpsertIndexerState(a.db.WithContext(ctx), state); err != nil {
 			return err
 		}
+		a.states[p.Name()] = state
+		a.health.observe(state, len(pending), time.Now())
+	}
+	metrics.IndexerBatches.Inc()
+	if len(txs) > 0 {
+		slog.Info("processed transactions", "start", first, "end", end, "count", len(txs))
 	}
 	return nil
 }
@@ -0,0 +1,358 @@
+package decibelindexer
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/aptos-labs/aptos-go-sdk/api"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+)
+
+const (
+	fixtureClosingAccount = "0x57bf3e3938f00f4fc079f67e3af5caa3a3fe7d1942a23253ecd3ad958ae9e6b7"
+	fixtureOpeningAccount = "0x47182c30c91a9d43bd6e528b25af98d032f1494b8c5c19c869a997f056d19ec5"
+	fixtureMarket         = "0xe6de4f6ec47f1bc2ab73920e9f202953e60482e1c1a90e7eef3ee45c8aafee36"
+)
+
+func loadTransaction(t *testing.T) *api.UserTransaction {
+	t.Helper()
+	raw, err := os.ReadFile("types/testdata/tx_32667225.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var tx api.UserTransaction
+	if err := json.Unmarshal(raw, &tx); err != nil {
+		t.Fatalf("failed to unmarshal transaction: %v", err)
+	}
+	return &tx
+}
+
+func TestExtractLiquidationsIgnoresOrders(t *testing.T) {
+	tx := loadTransaction(t)
+
+	liquidations, err := extractLiquidations(tx, "")
+	if err != nil {
+		t.Fatalf("extractLiquidations: %v", err)
+	}
+	if len(liquidations) != 0 {
+		t.Fatalf("expected no liquidations for a regular order, got %d", len(liquidations))
+	}
+}
+
+func TestExtractLiquidationsMarket(t *testing.T) {
+	tx := loadTransaction(t)
+	payload := tx.Payload.Inner.(*api.TransactionPayloadEntryFunction)
+	payload.Function = liquidationModule + "liquidate_position"
+	payload.Arguments = []any{fixtureClosingAccount}
+
+	liquidations, err := extractLiquidations(tx, "")
+	if err != nil {
+		t.Fatalf("extractLiquidations: %v", err)
+	}
+	if len(liquidations) != 1 {
+		t.Fatalf("expected 1 liquidation, got %d", len(liquidations))
+	}
+	l := liquidations[0]
+	if l.Account != fixtureClosingAccount {
+		t.Errorf("account = %s, want %s", l.Account, fixtureClosingAccount)
+	}
+	if l.Kind != models.LiquidationKindMarket {
+		t.Errorf("kind = %s, want %s", l.Kind, models.LiquidationKindMarket)
+	}
+	if !l.IsLong {
+		t.Errorf("expected a long position")
+	}
+	if l.Size != 2000000 || l.Price != 532817162 {
+		t.Errorf("size/price = %d/%d, want 2000000/532817162", l.Size, l.Price)
+	}
+	if l.Loss != 0 {
+		t.Errorf("loss = %d, want 0 for a profitable close", l.Loss)
+	}
+	if l.Fee != 1918141 {
+		t.Errorf("fee = %d, want 1918141", l.Fee)
+	}
+	if l.Version != 32667225 {
+		t.Errorf("version = %d, want 32667225", l.Version)
+	}
+}
+
+func TestExtractLiquidationsDetails(t *testing.T) {
+	tx := loadTransaction(t)
+	payload := tx.Payload.Inner.(*api.TransactionPayloadEntryFunction)
+	payload.Function = liquidationModule + "liquidate_position"
+	payload.Arguments = []any{fixtureClosingAccount}
+	tx.Events = append(tx.Events, &api.Event{
+		Type: liquidationModule + "LiquidationEvent",
+		Data: map[string]any{
+			"user":                             fixtureClosingAccount,
+			"market":                           map[string]any{"inner": fixtureMarket},
+			"liquidation_margin":               "5000000",
+			"backstop_liquidator_covered_loss": "1200000",
+		},
+	})
+
+	liquidations, err := extractLiquidations(tx, "")
+	if err != nil {
+		t.Fatalf("extractLiquidations: %v", err)
+	}
+	if len(liquidations) != 1 {
+		t.Fatalf("expected 1 liquidation, got %d", len(liquidations))
+	}
+	if l := liquidations[0]; l.Margin != 5000000 || l.CoveredLoss != 1200000 {
+		t.Errorf("margin/covered loss = %d/%d, want 5000000/1200000", l.Margin, l.CoveredLoss)
+	}
+}
+
+func TestExtractLiquidationsBackstop(t *testing.T) {
+	tx := loadTransaction(t)
+	tx.Events = append(tx.Events, &api.Event{
+		Type: liquidationModule + "LiquidationEvent",
+		Data: map[string]any{
+			"account": fixtureClosingAccount,
+			"market":  map[string]any{"inner": fixtureMarket},
+		},
+	})
+	// a trader closing against the backstop without being liquidated
+	tx.Events = append(tx.Events, &api.Event{
+		Type: tradeEvent,
+		Data: map[string]any{
+			"account":                 "0xc0ffee",
+			"market":                  map[string]any{"inner": fixtureMarket},
+			"action":                  map[string]any{"__variant__": "CloseShort"},
+			"size":                    "1000000",
+			"price":                   "532817162",
+			"realized_pnl_amount":     "0",
+			"realized_funding_amount": "0",
+			"fee_amount":              "0",
+		},
+	})
+
+	liquidations, err := extractLiquidations(tx, fixtureOpeningAccount)
+	if err != nil {
+		t.Fatalf("extractLiquidations: %v", err)
+	}
+	if len(liquidations) != 1 {
+		t.Fatalf("expected 1 liquidation, got %d", len(liquidations))
+	}
+	if liquidations[0].Kind != models.LiquidationKindBackstop {
+		t.Errorf("kind = %s, want %s", liquidations[0].Kind, models.LiquidationKindBackstop)
+	}
+	if liquidations[0].Account != fixtureClosingAccount {
+		t.Errorf("account = %s, want %s", liquidations[0].Account, fixtureClosingAccount)
+	}
+}
+
+func TestExtractFeeDistributions(t *testing.T) {
+	tx := loadTransaction(t)
+	if rows, err := extractFeeDistributions(tx); err != nil || len(rows) != 0 {
+		t.Fatalf("expected no fee distributions, got %d (%v)", len(rows), err)
+	}
+
+	tx.Events = append(tx.Events, &api.Event{
+		Type: decibelContract + "::perp_positions::FeeDistributionEvent",
+		Data: map[string]any{
+			"fee_distribution": map[string]any{
+				"position_address":    fixtureClosingAccount,
+				"position_fee_delta":  map[string]any{"is_positive": false, "amount": "1918141"},
+				"treasury_fee_delta":  map[string]any{"is_positive": true, "amount": "1500000"},
+				"backstop_vault_fees": "200000",
+				"builder_or_referrer_fees": map[string]any{
+					"vec": []any{map[string]any{"address": "0xb", "fees": "218141"}},
+				},
+			},
+		},
+	})
+
+	rows, err := extractFeeDistributions(tx)
+	if err != nil {
+		t.Fatalf("extractFeeDistributions: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 fee distribution, got %d", len(rows))
+	}
+	row := rows[0]
+	if row.Market != fixtureMarket {
+		t.Errorf("market = %s, want the market of the preceding trade", row.Market)
+	}
+	if row.EventIndex != len(tx.Events)-1 {
+		t.Errorf("event index = %d, want %d", row.EventIndex, len(tx.Events)-1)
+	}
+	if row.PositionFeeDelta != -1918141 || row.TreasuryFeeDelta != 1500000 {
+		t.Errorf("deltas = %d/%d, want -1918141/1500000", row.PositionFeeDelta, row.TreasuryFeeDelta)
+	}
+	if row.BackstopVaultFees != 200000 {
+		t.Errorf("backstop fees = %d, want 200000", row.BackstopVaultFees)
+	}
+	if row.Builder != types.NormalizeAddress("0xb") || row.BuilderFees != 218141 {
+		t.Errorf("builder = %s/%d, want 0x0…0b/218141", row.Builder, row.BuilderFees)
+	}
+}
+
+func TestExtractTrades(t *testing.T) {
+	tx := loadTransaction(t)
+	trades, err := extractTrades(tx)
+	if err != nil {
+		t.Fatalf("extractTrades: %v", err)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("expected a trade for each side of the fill, got %d", len(trades))
+	}
+	closing, opening := trades[0], trades[1]
+	if closing.Account != fixtureClosingAccount || closing.Action != string(types.ActionCloseLong) || !closing.IsRebate {
+		t.Errorf("closing trade = %+v", closing)
+	}
+	if opening.Account != fixtureOpeningAccount || opening.Action != string(types.ActionOpenLong) || opening.Fee != 319690 {
+		t.Errorf("opening trade = %+v", opening)
+	}
+	if closing.Price != 532817162 || opening.Price != closing.Price || opening.Size != 2000000 || opening.Size != closing.Size {
+		t.Errorf("sides disagree on the fill: %d@%d and %d@%d", closing.Size, closing.Price, opening.Size, opening.Price)
+	}
+	if closing.PositionSize != 0 || opening.PositionSize != 8520021029 || !opening.PositionIsLong || opening.Leverage != 10 {
+		t.Errorf("positions after the fill = %d and %d long=%v x%d", closing.PositionSize, opening.PositionSize, opening.PositionIsLong, opening.Leverage)
+	}
+	if closing.Version != 32667225 || opening.EventIndex <= closing.EventIndex {
+		t.Errorf("unexpected keys %d/%d and %d/%d", closing.Version, closing.EventIndex, opening.Version, opening.EventIndex)
+	}
+}
+
+func TestExtractBalanceChanges(t *testing.T) {
+	tx := loadTransaction(t)
+	changes, err := extractBalanceChanges(tx)
+	if err != nil {
+		t.Fatalf("extractBalanceChanges: %v", err)
+	}
+	if len(changes) != 5 {
+		t.Fatalf("expected 5 balance changes, got %d", len(changes))
+	}
+	pnl, margin := changes[0], changes[1]
+	if pnl.Account != fixtureClosingAccount || pnl.Market == "" || pnl.ChangeType != string(types.CollateralChangePnL) || pnl.Delta != 66636190 {
+		t.Errorf("pnl change = %+v", pnl)
+	}
+	if margin.Market != "" || margin.ChangeType != string(types.CollateralChangeMargin) || margin.Delta != -1113469273 || margin.BalanceAfter != 1918141 {
+		t.Errorf("cross margin change = %+v", margin)
+	}
+}
+
+func TestTriggersFromPosition(t *testing.T) {
+	var position models.PerpPosition
+	if err := json.Unmarshal([]byte(`{
+		"PositionAddress": "0xp",
+		"Market": "0xm",
+		"Owner": "0xo",
+		"TpReqs": {"full_sized": {"vec": [{"price_index": {"trigger_price": "110000000", "position_address": "0xp", "limit_price": {"vec": []}, "is_full_size": true}, "order_id": {"order_id": "1"}}]}, "fixed_sized": []},
+		"SlReqs": {"full_sized": {"vec": []}, "fixed_sized": [{"price_index": {"trigger_price": "90000000", "position_address": "0xp", "limit_price": {"vec": ["89000000"]}, "is_full_size": false}, "order_id": {"order_id": "2"}}]},
+		"ReduceOnlyOrders": [{"order_id": "3"}]
+	}`), &position); err != nil {
+		t.Fatalf("failed to build position: %v", err)
+	}
+
+	triggers := models.TriggersFromPosition(position, map[string]types.Uint64{"2": 500})
+	if len(triggers) != 3 {
+		t.Fatalf("expected 3 triggers, got %d", len(triggers))
+	}
+	tp, sl, ro := triggers[0], triggers[1], triggers[2]
+	if tp.Kind != models.TriggerKindTakeProfit || !tp.IsFullSize || tp.TriggerPrice != 110000000 || tp.LimitPrice != 0 || tp.Size != 0 {
+		t.Errorf("unexpected take profit: %+v", tp)
+	}
+	if sl.Kind != models.TriggerKindStopLoss || sl.IsFullSize || sl.TriggerPrice != 90000000 || sl.LimitPrice != 89000000 || sl.Size != 500 {
+		t.Errorf("unexpected stop loss: %+v", sl)
+	}
+	if ro.Kind != models.TriggerKindReduceOnly || ro.OrderID.String() != "3" {
+		t.Errorf("unexpected reduce only order: %+v", ro)
+	}
+	for _, trigger := range triggers {
+		if trigger.PositionAddress != "0xp" || trigger.Market != "0xm" || trigger.Owner != "0xo" {
+			t.Errorf("trigger not keyed by its position: %+v", trigger)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	if _, err := newRegistry(newFeeProcessor(nil), newFeeProcessor(nil)); err == nil {
+		t.Fatal("expected an error for duplicate processor names")
+	}
+
+	r, err := newRegistry(newPositionProcessor(nil), newFundingProcessor(nil), newFeeProcessor(nil))
+	if err != nil {
+		t.Fatalf("newRegistry: %v", err)
+	}
+	if _, ok := r.Get("funding"); !ok {
+		t.Error("funding processor not registered")
+	}
+
+	enabled := r.Enabled(map[string]bool{"funding": false, "positions": true})
+	if len(enabled) != 2 || enabled[0].Name() != "positions" || enabled[1].Name() != "fees" {
+		names := make([]string, 0, len(enabled))
+		for _, p := range enabled {
+			names = append(names, p.Name())
+		}
+		t.Errorf("enabled = %v, want [positions fees]", names)
+	}
+}
+
+func TestNewProcessorsSkipOpenInterestInBackfill(t *testing.T) {
+	for _, backfill := range []bool{false, true} {
+		r, err := newProcessors(nil, nil, &Config{}, backfill)
+		if err != nil {
+			t.Fatalf("newProcessors: %v", err)
+		}
+		p, _ := r.Get(positionProcessorName)
+		if got := p.(*positionProcessor).skipOpenInterest; got != backfill {
+			t.Errorf("backfill %v: skipOpenInterest = %v, want %v", backfill, got, backfill)
+		}
+	}
+}
+
+func TestPendingTransactions(t *testing.T) {
+	tx := loadTransaction(t)
+	txs := []*api.UserTransaction{tx}
+
+	for _, p := range []Processor{
+		newPositionProcessor(nil),
+		newFundingProcessor(nil),
+		newLiquidationProcessor(nil, nil, ""),
+		newFeeProcessor(nil),
+	} {
+		if got := pendingTransactions(p, txs, tx.Version); len(got) != 1 {
+			t.Errorf("%s: expected the fixture to be handled", p.Name())
+		}
+		if got := pendingTransactions(p, txs, tx.Version+1); len(got) != 0 {
+			t.Errorf("%s: expected transactions before the checkpoint to be skipped", p.Name())
+		}
+	}
+
+	// the fixture is an order placement and does not touch market prices
+	if got := pendingTransactions(newPriceProcessor(nil, nil), txs, 0); len(got) != 0 {
+		t.Errorf("prices: expected the fixture to be filtered out")
+	}
+}
+
+func TestTransactionFilter(t *testing.T) {
+	tx := loadTransaction(t)
+
+	filter := transactionFilter(newPositionProcessor(nil), newFundingProcessor(nil))
+	if !filter.Match(tx) {
+		t.Error("expected the fixture to pass the positions and funding filter")
+	}
+	if transactionFilter(newPriceProcessor(nil, nil)).Match(tx) {
+		t.Error("expected the fixture to be filtered out for prices")
+	}
+}
+
+func TestCheckBatch(t *testing.T) {
+	tx := loadTransaction(t)
+	next := *tx
+	next.Version++
+
+	if err := checkBatch([]*api.UserTransaction{tx, &next}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checkBatch([]*api.UserTransaction{tx, tx}); err == nil {
+		t.Error("expected a duplicate version to be rejected")
+	}
+	if err := checkBatch([]*api.UserTransaction{&next, tx}); err == nil {
+		t.Error("expected versions out of order to be rejected")
+	}
+}
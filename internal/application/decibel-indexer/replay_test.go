@@ -0,0 +1,134 @@
+package decibelindexer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/pkg/fullnode"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// The replay test indexes the recorded transactions of testdata/replay into
+// the Postgres database of DECIBEL_TEST_DB and compares every table with
+// testdata/golden. Run it with -update to rewrite the golden files after an
+// intended change of a processor's output. The database is wiped.
+//
+//	DECIBEL_TEST_DB=postgres://localhost/decibel_test go test -run TestReplay -update
+var updateGolden = flag.Bool("update", false, "rewrite the golden files of the replay test")
+
+var replayTables = []struct {
+	name  string
+	rows  func() any
+	order string
+}{
+	{"PERP_POSITIONS", func() any { return &[]models.PerpPosition{} }, "address, market, is_crossed"},
+	{"POSITION_TRIGGERS", func() any { return &[]models.PositionTrigger{} }, "address, market, is_crossed, order_id"},
+	{"MARKET_PRICES", func() any { return &[]models.MarketPrice{} }, "market"},
+	{"MARKET_FUNDING", func() any { return &[]models.MarketFunding{} }, "market, hour"},
+	{"FUNDING_PAYMENTS", func() any { return &[]models.FundingPayment{} }, "version, event_index"},
+	{"LIQUIDATIONS", func() any { return &[]models.Liquidation{} }, "version, event_index"},
+	{"FEE_DISTRIBUTIONS", func() any { return &[]models.FeeDistribution{} }, "version, event_index"},
+	{"FEE_REVENUE", func() any { return &[]models.FeeRevenue{} }, "day, market, builder"},
+	{"TRADES", func() any { return &[]models.Trade{} }, "version, event_index"},
+	{"CANDLES", func() any { return &[]models.Candle{} }, "market, resolution, open_time"},
+	{"TRADER_PNL", func() any { return &[]models.TraderPnl{} }, "account, hour"},
+	{"BALANCE_CHANGES", func() any { return &[]models.BalanceChange{} }, "version, event_index"},
+	{"ACCOUNT_BALANCES", func() any { return &[]models.AccountBalance{} }, "account, market, hour"},
+	{"OPEN_INTEREST", func() any { return &[]models.OpenInterest{} }, "market, time"},
+}
+
+func TestReplay(t *testing.T) {
+	dsn := os.Getenv("DECIBEL_TEST_DB")
+	if dsn == "" {
+		t.Skip("DECIBEL_TEST_DB is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	if err := migrate(db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	tables := []string{`"INDEXER_STATE"`}
+	for _, table := range replayTables {
+		tables = append(tables, `"`+table.name+`"`)
+	}
+	if err := db.Exec("TRUNCATE TABLE " + strings.Join(tables, ", ")).Error; err != nil {
+		t.Fatalf("failed to truncate: %v", err)
+	}
+
+	processors, err := newProcessors(db, nil, &Config{}, false)
+	if err != nil {
+		t.Fatalf("newProcessors: %v", err)
+	}
+	app := &Application{
+		ctx:        context.Background(),
+		cfg:        &Config{},
+		db:         db,
+		processors: processors,
+		enabled:    processors.Enabled(nil),
+		states:     make(map[string]models.IndexerState),
+	}
+
+	files, err := fullnode.RecordFiles("testdata/replay")
+	if err != nil {
+		t.Fatalf("RecordFiles: %v", err)
+	}
+	stream, err := fullnode.NewFileStream(files, 0, 100, fullnode.WithFilter(transactionFilter(app.enabled...)))
+	if err != nil {
+		t.Fatalf("NewFileStream: %v", err)
+	}
+	defer stream.Close()
+	for {
+		batch, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if err := app.Process(batch); err != nil {
+			t.Fatalf("Process: %v", err)
+		}
+	}
+
+	for _, table := range replayTables {
+		rows := table.rows()
+		if err := db.Table(table.name).Order(table.order).Find(rows).Error; err != nil {
+			t.Fatalf("failed to read %s: %v", table.name, err)
+		}
+		got, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			t.Fatalf("failed to marshal %s: %v", table.name, err)
+		}
+		got = append(got, '\n')
+
+		golden := filepath.Join("testdata", "golden", table.name+".json")
+		if *updateGolden {
+			if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(golden, got, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("failed to read golden file, run with -update to create it: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s differs from %s:\n%s", table.name, golden, got)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package decibelindexer
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/aptos-labs/aptos-go-sdk/api"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/models"
+	"github.com/cresendoo/decidash-backend/internal/application/decibel-indexer/types"
+	"github.com/mediocregopher/radix/v3"
+	"gorm.io/gorm"
+)
+
+// tradeProcessor indexes trades with the candles and hourly trader PnL
+// aggregated from them, and publishes the latest candles to the live feed.
+type tradeProcessor struct {
+	db   *gorm.DB
+	pool *radix.Pool
+}
+
+func newTradeProcessor(db *gorm.DB, pool *radix.Pool) *tradeProcessor {
+	return &tradeProcessor{db: db, pool: pool}
+}
+
+func (proc *tradeProcessor) Name() string {
+	return "trades"
+}
+
+func (proc *tradeProcessor) ResourceTypes() []string {
+	return nil
+}
+
+func (proc *tradeProcessor) EventTypes() []string {
+	return []string{tradeEvent, positionUpdateEvent}
+}
+
+func (proc *tradeProcessor) Process(ctx context.Context, txs []*api.UserTransaction) error {
+	var trades []models.Trade
+	for _, tx := range txs {
+		rows, err := extractTrades(tx)
+		if err != nil {
+			return err
+		}
+		trades = append(trades, rows...)
+	}
+	if len(trades) == 0 {
+		return nil
+	}
+
+	// candles only merge trades that were not indexed before, so both are
+	// written together
+	if err := proc.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		fresh, err := models.InsertTrades(tx, trades)
+		if err != nil {
+			return err
+		}
+		return models.MergeCandles(tx, fresh)
+	}); err != nil {
+		return err
+	}
+	from := trades[0].VersionTimestamp
+	to := trades[len(trades)-1].VersionTimestamp.Add(time.Microsecond)
+	if err := models.RefreshTraderPnl(proc.db.WithContext(ctx), from, to); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	var markets []string
+	for _, t := range trades {
+		if !seen[t.Market] {
+			seen[t.Market] = true
+			markets = append(markets, t.Market)
+		}
+	}
+	candles, err := models.GetLatestCandles(proc.db.WithContext(ctx), markets)
+	if err != nil {
+		return err
+	}
+	for _, c := range candles {
+		b, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		if err := publish(ctx, proc.pool, models.CandleChannel, string(b)); err != nil {
+			slog.Warn("failed to publish candle", "market", c.Market, "resolution", c.Resolution, "error", err)
+		}
+	}
+	return nil
+}
+
+// extractTrades finds the trades of a transaction, each with the position
+// update the contract emits right after it.
+func extractTrades(tx *api.UserTransaction) ([]models.Trade, error) {
+	timestamp := time.UnixMicro(int64(tx.Timestamp))
+	var trades []models.Trade
+	// trades waiting for their position update by account and market
+	pending := make(map[[2]string][]int)
+	for _, event := range types.ExtractEvents(tx) {
+		switch event.Type {
+		case tradeEvent:
+			var t types.TradeEvent
+			if err := MapToStructJSON(event.Data, &t); err != nil {
+				return nil, err
+			}
+			var trade models.Trade
+			trade.FromTradeEvent(tx.Version, event.EventIndex, timestamp, t)
+			key := [2]string{t.Account, t.Market.Inner}
+			pending[key] = append(pending[key], len(trades))
+			trades = append(trades, trade)
+		case positionUpdateEvent:
+			var update types.PositionUpdateEvent
+			if err := MapToStructJSON(event.Data, &update); err != nil {
+				return nil, err
+			}
+			key := [2]string{update.User, update.Market.Inner}
+			for _, i := range pending[key] {
+				trades[i].FromPositionUpdateEvent(update)
+			}
+			delete(pending, key)
+		}
+	}
+	return trades, nil
+}
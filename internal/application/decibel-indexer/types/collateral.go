@@ -0,0 +1,47 @@
+package types
+
+// CollateralBalanceType is the balance sheet collateral is held in: the
+// account's cross margin, or the margin of an isolated position in Market.
+type CollateralBalanceType struct {
+	Variant string `json:"__variant__"`
+	Account string `json:"account"`
+	Market  Object `json:"market"`
+}
+
+const (
+	CollateralBalanceCross    = "Cross"
+	CollateralBalanceIsolated = "Isolated"
+)
+
+// CollateralChangeType is the reason of a collateral balance change, e.g.
+// PnL, Fee or Margin for transfers between the cross and isolated sheets.
+type CollateralChangeType string
+
+const (
+	CollateralChangePnL    CollateralChangeType = "PnL"
+	CollateralChangeFee    CollateralChangeType = "Fee"
+	CollateralChangeMargin CollateralChangeType = "Margin"
+)
+
+// UnmarshalJSON accepts both a plain string and the move enum
+// representation, e.g. {"__variant__":"PnL"}.
+func (c *CollateralChangeType) UnmarshalJSON(data []byte) error {
+	s, err := unmarshalVariant(data)
+	if err != nil {
+		return err
+	}
+	*c = CollateralChangeType(s)
+	return nil
+}
+
+type CollateralBalance struct {
+	Value Uint64 `json:"value"`
+}
+
+type CollateralBalanceChangeEvent struct {
+	BalanceType     CollateralBalanceType `json:"balance_type"`
+	ChangeType      CollateralChangeType  `json:"change_type"`
+	Delta           Uint64                `json:"delta"`
+	IsDeltaPositive bool                  `json:"is_delta_positive"`
+	BalanceAfter    CollateralBalance     `json:"balance_after"`
+}
@@ -0,0 +1,8 @@
+package types
+
+type Price struct {
+	OraclePx          Uint64            `json:"oracle_px"`
+	MarkPx            Uint64            `json:"mark_px"`
+	AccumulativeIndex AccumulativeIndex `json:"accumulative_index"`
+	LastUpdated       Uint64            `json:"last_updated"`
+}
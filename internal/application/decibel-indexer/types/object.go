@@ -1,5 +1,17 @@
 package types
 
+import "github.com/aptos-labs/aptos-go-sdk"
+
+// NormalizeAddress returns the long form of an account address, or an empty
+// string if s is not an address.
+func NormalizeAddress(s string) string {
+	var address aptos.AccountAddress
+	if err := address.ParseStringRelaxed(s); err != nil {
+		return ""
+	}
+	return address.StringLong()
+}
+
 type ObjectCore struct {
 	AllowUngatedTransfer bool   `json:"allow_ungated_transfer"`
 	GuidCreationNum      Uint64 `json:"guid_creation_num"`
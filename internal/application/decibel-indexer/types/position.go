@@ -1,5 +1,7 @@
 package types
 
+import "encoding/json"
+
 type Option[T any] struct {
 	Vec []T `json:"vec"`
 }
@@ -158,11 +160,49 @@ const (
 	ActionNet        Action = "Net"
 )
 
+// UnmarshalJSON accepts both a plain string and the move enum
+// representation, e.g. {"__variant__":"OpenLong"}.
+func (a *Action) UnmarshalJSON(data []byte) error {
+	s, err := unmarshalVariant(data)
+	if err != nil {
+		return err
+	}
+	*a = Action(s)
+	return nil
+}
+
+// unmarshalVariant decodes the name of a move enum variant given either as
+// a plain string or as {"__variant__":"Name"}.
+func unmarshalVariant(data []byte) (string, error) {
+	var variant struct {
+		Variant string `json:"__variant__"`
+	}
+	if err := json.Unmarshal(data, &variant); err == nil {
+		return variant.Variant, nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
 type ReduceOnlyValidationResult struct {
 	Variant string                            `json:"variant"`
 	Fields  *ReduceOnlyValidationResultFields `json:"fields,omitempty"`
 }
 
+// LiquidationEvent is the part of a liquidation module event that names the
+// liquidated position, with the liquidation margin of the account status and
+// the backstop liquidator covered loss of the position update result.
+type LiquidationEvent struct {
+	Account                       string `json:"account"`
+	User                          string `json:"user"`
+	Market                        Object `json:"market"`
+	LiquidationMargin             Uint64 `json:"liquidation_margin"`
+	BackstopLiquidatorCoveredLoss Uint64 `json:"backstop_liquidator_covered_loss"`
+}
+
 type ReduceOnlyValidationResultFields struct {
 	Size Uint64 `json:"size"`
 }
@@ -78,3 +78,62 @@ func TestParsePerpPositionsFromTransaction(t *testing.T) {
 		t.Errorf("expected isolated position size to be zero, got %d", isolated.Position.Size.Uint64())
 	}
 }
+
+func TestParseTradeEventAction(t *testing.T) {
+	raw := []byte(`{
+		"account": "0x57bf3e3938f00f4fc079f67e3af5caa3a3fe7d1942a23253ecd3ad958ae9e6b7",
+		"action": {"__variant__": "CloseLong"},
+		"fee_amount": "1918141",
+		"is_funding_positive": true,
+		"is_profit": true,
+		"is_rebate": true,
+		"market": {"inner": "0xe6de4f6ec47f1bc2ab73920e9f202953e60482e1c1a90e7eef3ee45c8aafee36"},
+		"price": "532817162",
+		"realized_funding_amount": "58940",
+		"realized_pnl_amount": "66636190",
+		"size": "2000000"
+	}`)
+
+	var event TradeEvent
+	if err := json.Unmarshal(raw, &event); err != nil {
+		t.Fatalf("failed to parse TradeEvent: %v", err)
+	}
+	if event.Action != ActionCloseLong {
+		t.Errorf("unexpected action: %s", event.Action)
+	}
+	if event.RealizedFundingAmount.Uint64() != 58940 {
+		t.Errorf("unexpected realized funding: %d", event.RealizedFundingAmount.Uint64())
+	}
+
+	var action Action
+	if err := json.Unmarshal([]byte(`"OpenShort"`), &action); err != nil {
+		t.Fatalf("failed to parse plain action: %v", err)
+	}
+	if action != ActionOpenShort {
+		t.Errorf("unexpected plain action: %s", action)
+	}
+}
+
+func TestParseCollateralBalanceChangeEvent(t *testing.T) {
+	raw := []byte(`{
+		"balance_after": {"value": "1115387414"},
+		"balance_type": {"__variant__": "Isolated", "account": "0x57bf3e3938f00f4fc079f67e3af5caa3a3fe7d1942a23253ecd3ad958ae9e6b7", "market": {"inner": "0xe6de4f6ec47f1bc2ab73920e9f202953e60482e1c1a90e7eef3ee45c8aafee36"}},
+		"change_type": {"__variant__": "PnL"},
+		"delta": "66636190",
+		"is_delta_positive": true
+	}`)
+
+	var event CollateralBalanceChangeEvent
+	if err := json.Unmarshal(raw, &event); err != nil {
+		t.Fatalf("failed to parse CollateralBalanceChangeEvent: %v", err)
+	}
+	if event.BalanceType.Variant != CollateralBalanceIsolated || event.BalanceType.Market.Inner == "" {
+		t.Errorf("unexpected balance type: %+v", event.BalanceType)
+	}
+	if event.ChangeType != CollateralChangePnL {
+		t.Errorf("unexpected change type: %s", event.ChangeType)
+	}
+	if event.Delta.Uint64() != 66636190 || event.BalanceAfter.Value.Uint64() != 1115387414 {
+		t.Errorf("unexpected delta/balance: %d/%d", event.Delta.Uint64(), event.BalanceAfter.Value.Uint64())
+	}
+}
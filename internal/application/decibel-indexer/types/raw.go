@@ -89,3 +89,27 @@ func ExtractWriteSetChange(tx *api.UserTransaction) (
 	}
 	return writeTableItems, writeResources, deleteResources, deleteTableItems
 }
+
+func ExtractEvents(tx *api.UserTransaction) []*Event {
+	events := make([]*Event, 0, len(tx.Events))
+	timestamp := time.UnixMicro(int64(tx.Timestamp))
+	for idx, event := range tx.Events {
+		events = append(events, &Event{
+			Version:    tx.Version,
+			Timestamp:  timestamp,
+			EventIndex: idx,
+			Event:      event,
+		})
+	}
+	return events
+}
+
+// EntryFunction returns the entry function payload of a transaction, or nil
+// for other payloads.
+func EntryFunction(tx *api.UserTransaction) *api.TransactionPayloadEntryFunction {
+	if tx.Payload == nil || tx.Payload.Type != api.TransactionPayloadVariantEntryFunction {
+		return nil
+	}
+	payload, _ := tx.Payload.Inner.(*api.TransactionPayloadEntryFunction)
+	return payload
+}
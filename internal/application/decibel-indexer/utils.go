@@ -2,7 +2,11 @@ package decibelindexer
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+
+	"github.com/cresendoo/decidash-backend/pkg/xredis"
+	"github.com/mediocregopher/radix/v3"
 )
 
 func MapToStructJSON[T any](m map[string]any, out *T) error {
@@ -14,3 +18,12 @@ func MapToStructJSON[T any](m map[string]any, out *T) error {
 	dec.UseNumber()
 	return dec.Decode(out)
 }
+
+// publish publishes message to the live feed. Without a pool, as in the
+// replay test, there is nobody to notify.
+func publish(ctx context.Context, pool *radix.Pool, channel string, message string) error {
+	if pool == nil {
+		return nil
+	}
+	return xredis.Publish(ctx, pool, channel, message)
+}
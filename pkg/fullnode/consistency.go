@@ -0,0 +1,33 @@
+package fullnode
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	ConsistencyGap         = "gap"
+	ConsistencyDuplicate   = "duplicate"
+	ConsistencyAccumulator = "accumulator"
+
+	// a page that fails to fetch or verify is refetched with exponential
+	// backoff before the stream gives up
+	maxRefetches = 5
+	refetchDelay = 500 * time.Millisecond
+)
+
+// ConsistencyError reports a page of transactions that cannot be trusted:
+// a gap or duplicate in its versions, or a transaction the node no longer
+// agrees on.
+type ConsistencyError struct {
+	Kind     string
+	Expected uint64
+	Got      uint64
+}
+
+func (e *ConsistencyError) Error() string {
+	if e.Kind == ConsistencyAccumulator {
+		return fmt.Sprintf("accumulator mismatch at version %d", e.Expected)
+	}
+	return fmt.Sprintf("version %s: expected %d, got %d", e.Kind, e.Expected, e.Got)
+}
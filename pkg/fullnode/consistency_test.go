@@ -0,0 +1,187 @@
+package fullnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckContinuity(t *testing.T) {
+	versions := func(vs ...uint64) []transactionInfo {
+		infos := make([]transactionInfo, len(vs))
+		for i, v := range vs {
+			infos[i].Version = v
+		}
+		return infos
+	}
+	start := uint64(10)
+
+	tests := []struct {
+		name  string
+		start *uint64
+		infos []transactionInfo
+		kind  string
+	}{
+		{"contiguous", &start, versions(10, 11, 12), ""},
+		{"without start", nil, versions(20, 21), ""},
+		{"skipped start", &start, versions(11, 12), ConsistencyGap},
+		{"gap", &start, versions(10, 12), ConsistencyGap},
+		{"duplicate", &start, versions(10, 11, 11), ConsistencyDuplicate},
+		{"before start", &start, versions(9, 10), ConsistencyDuplicate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkContinuity(tt.start, tt.infos)
+			if tt.kind == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			var inconsistency *ConsistencyError
+			if !errors.As(err, &inconsistency) || inconsistency.Kind != tt.kind {
+				t.Errorf("error = %v, want a %s", err, tt.kind)
+			}
+		})
+	}
+}
+
+func testPage(versions ...uint64) string {
+	raws := make([]string, len(versions))
+	for i, v := range versions {
+		raws[i] = testTransaction(v, testSender, "0x1::aptos_account::transfer", "0x1::fungible_asset::Deposit", "0x1::account::Account")
+	}
+	return "[" + strings.Join(raws, ",") + "]"
+}
+
+func TestStreamRefetchesInconsistentPages(t *testing.T) {
+	var pages atomic.Int32
+	client := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v1/transactions/by_version/"):
+			w.Write([]byte(testTransaction(12, testSender, "0x1::aptos_account::transfer", "0x1::fungible_asset::Deposit", "0x1::account::Account")))
+		case r.URL.Query().Get("start") != "10":
+			w.Write([]byte("[]"))
+		case pages.Add(1) == 1:
+			// the node skipped version 11
+			w.Write([]byte(testPage(10, 12)))
+		default:
+			w.Write([]byte(testPage(10, 11, 12)))
+		}
+	})
+
+	stream, err := client.NewStream(10, 100, WithVerifyAccumulator())
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	defer stream.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		batch, err := stream.Recv()
+		if err != nil {
+			t.Errorf("Recv: %v", err)
+			return
+		}
+		txs := batch.Transactions
+		// a partial page must be streamed before waiting for more
+		if len(txs) != 3 || txs[0].Version != 10 || txs[2].Version != 12 {
+			t.Errorf("expected versions 10..12 after refetching, got %d transactions", len(txs))
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for transactions")
+	}
+	if pages.Load() < 2 {
+		t.Error("expected the inconsistent page to be refetched")
+	}
+}
+
+func TestVerifyAccumulator(t *testing.T) {
+	client := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version":"12","hash":"0x2","accumulator_root_hash":"0x1"}`)
+	})
+
+	if err := client.verifyAccumulator(transactionInfo{Version: 12, Hash: "0x2", AccumulatorRootHash: "0x1"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err := client.verifyAccumulator(transactionInfo{Version: 12, Hash: "0x3", AccumulatorRootHash: "0x1"})
+	var inconsistency *ConsistencyError
+	if !errors.As(err, &inconsistency) || inconsistency.Kind != ConsistencyAccumulator {
+		t.Errorf("error = %v, want an accumulator mismatch", err)
+	}
+}
+
+func TestStreamRecordsVerifiedPages(t *testing.T) {
+	client := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("start") != "10" {
+			w.Write([]byte("[]"))
+			return
+		}
+		w.Write([]byte(testPage(10, 11, 12)))
+	})
+	var checks atomic.Int32
+	verifier := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		hash := "0x1"
+		if checks.Add(1) == 1 {
+			// the second node does not know the page yet
+			hash = "0x2"
+		}
+		fmt.Fprintf(w, `{"version":"12","hash":%q,"accumulator_root_hash":"0x1"}`, hash)
+	})
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, 10)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+
+	stream, err := client.NewStream(10, 100,
+		WithFilter(TransactionFilter{EventTypePrefixes: []string{testPackage + "::"}}),
+		WithRecorder(recorder),
+		WithVerifyNode(verifier),
+	)
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	stream.Close()
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if checks.Load() < 2 {
+		t.Error("expected the page to be verified against the second node again")
+	}
+
+	// the page is recorded once, with the transactions the filter left out
+	files, err := RecordFiles(dir)
+	if err != nil {
+		t.Fatalf("RecordFiles: %v", err)
+	}
+	var versions []uint64
+	for _, file := range files {
+		err := readRecordFile(file, func(raw []byte) (bool, error) {
+			var info transactionInfo
+			if err := json.Unmarshal(raw, &info); err != nil {
+				return false, err
+			}
+			versions = append(versions, info.Version)
+			return true, nil
+		})
+		if err != nil {
+			t.Fatalf("readRecordFile: %v", err)
+		}
+	}
+	if len(versions) != 3 || versions[0] != 10 || versions[2] != 12 {
+		t.Errorf("recorded versions = %v, want [10 11 12]", versions)
+	}
+}
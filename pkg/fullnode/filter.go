@@ -0,0 +1,229 @@
+package fullnode
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/aptos-labs/aptos-go-sdk/api"
+	indexerv1 "github.com/ice-coldbell/aptos-indexer-grpc-go/aptos/indexer/v1"
+	transactionv1 "github.com/ice-coldbell/aptos-indexer-grpc-go/aptos/transaction/v1"
+)
+
+// TransactionFilter selects user transactions. A transaction matches when it
+// satisfies any of the criteria; a filter without criteria matches every
+// transaction.
+//
+// Modules are written as <address>::<module>, types and type prefixes as
+// <address>::<module>::<name> or any leading part of it, e.g. the package
+// address followed by "::".
+type TransactionFilter struct {
+	Senders              []string
+	EntryFunctionModules []string
+	EventTypePrefixes    []string
+	ResourceTypePrefixes []string
+}
+
+func (f TransactionFilter) IsEmpty() bool {
+	return len(f.Senders) == 0 &&
+		len(f.EntryFunctionModules) == 0 &&
+		len(f.EventTypePrefixes) == 0 &&
+		len(f.ResourceTypePrefixes) == 0
+}
+
+// Match reports whether a decoded transaction satisfies the filter.
+func (f TransactionFilter) Match(tx *api.UserTransaction) bool {
+	if f.IsEmpty() {
+		return true
+	}
+	if tx.Sender != nil {
+		for _, sender := range f.Senders {
+			if sameAddress(tx.Sender.String(), sender) {
+				return true
+			}
+		}
+	}
+	if len(f.EntryFunctionModules) > 0 && tx.Payload != nil && tx.Payload.Type == api.TransactionPayloadVariantEntryFunction {
+		if payload, ok := tx.Payload.Inner.(*api.TransactionPayloadEntryFunction); ok {
+			for _, module := range f.EntryFunctionModules {
+				if hasTypePrefix(payload.Function, module+"::") {
+					return true
+				}
+			}
+		}
+	}
+	for _, event := range tx.Events {
+		for _, prefix := range f.EventTypePrefixes {
+			if hasTypePrefix(event.Type, prefix) {
+				return true
+			}
+		}
+	}
+	if len(f.ResourceTypePrefixes) > 0 {
+		for _, change := range tx.Changes {
+			if change.Type != api.WriteSetChangeVariantWriteResource {
+				continue
+			}
+			wr, ok := change.Inner.(*api.WriteSetChangeWriteResource)
+			if !ok || wr.Data == nil {
+				continue
+			}
+			for _, prefix := range f.ResourceTypePrefixes {
+				if hasTypePrefix(wr.Data.Type, prefix) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// matchProto reports whether a transaction streamed by the gRPC services
+// satisfies the filter. Only user transactions can match a filter with
+// criteria.
+func (f TransactionFilter) matchProto(tx *transactionv1.Transaction) bool {
+	if f.IsEmpty() {
+		return true
+	}
+	user := tx.GetUser()
+	if user == nil {
+		return false
+	}
+	request := user.GetRequest()
+	for _, sender := range f.Senders {
+		if sameAddress(request.GetSender(), sender) {
+			return true
+		}
+	}
+	if module := request.GetPayload().GetEntryFunctionPayload().GetFunction().GetModule(); module != nil {
+		function := module.GetAddress() + "::" + module.GetName() + "::"
+		for _, m := range f.EntryFunctionModules {
+			if hasTypePrefix(function, m+"::") {
+				return true
+			}
+		}
+	}
+	for _, event := range user.GetEvents() {
+		for _, prefix := range f.EventTypePrefixes {
+			if hasTypePrefix(event.GetTypeStr(), prefix) {
+				return true
+			}
+		}
+	}
+	for _, change := range tx.GetInfo().GetChanges() {
+		resource := change.GetWriteResource()
+		if resource == nil {
+			continue
+		}
+		for _, prefix := range f.ResourceTypePrefixes {
+			if hasTypePrefix(resource.GetTypeStr(), prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mayMatch cheaply rules out raw JSON transactions that cannot match, so they
+// are never decoded. Addresses are compared without leading zeros, which
+// appear in the raw JSON in either their short or long form.
+func (f TransactionFilter) mayMatch(raw []byte) bool {
+	if f.IsEmpty() {
+		return true
+	}
+	for _, criteria := range [][]string{f.Senders, f.EntryFunctionModules, f.EventTypePrefixes, f.ResourceTypePrefixes} {
+		for _, c := range criteria {
+			if bytes.Contains(raw, []byte(trimAddress(c))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Proto converts the filter for the indexer gRPC data service. Write set
+// changes cannot be filtered server side, so a filter with resource type
+// prefixes returns nil and must be applied with Match instead.
+func (f TransactionFilter) Proto() *indexerv1.BooleanTransactionFilter {
+	if f.IsEmpty() || len(f.ResourceTypePrefixes) > 0 {
+		return nil
+	}
+
+	var filters []*indexerv1.BooleanTransactionFilter
+	for _, sender := range f.Senders {
+		filters = append(filters, apiFilter(&indexerv1.APIFilter{
+			Filter: &indexerv1.APIFilter_UserTransactionFilter{
+				UserTransactionFilter: &indexerv1.UserTransactionFilter{Sender: ptr(sender)},
+			},
+		}))
+	}
+	for _, module := range f.EntryFunctionModules {
+		parts := splitType(module)
+		entryFunction := &indexerv1.EntryFunctionFilter{Address: parts[0], ModuleName: parts[1]}
+		filters = append(filters, apiFilter(&indexerv1.APIFilter{
+			Filter: &indexerv1.APIFilter_UserTransactionFilter{
+				UserTransactionFilter: &indexerv1.UserTransactionFilter{
+					PayloadFilter: &indexerv1.UserTransactionPayloadFilter{EntryFunctionFilter: entryFunction},
+				},
+			},
+		}))
+	}
+	for _, prefix := range f.EventTypePrefixes {
+		parts := splitType(prefix)
+		filters = append(filters, apiFilter(&indexerv1.APIFilter{
+			Filter: &indexerv1.APIFilter_EventFilter{
+				EventFilter: &indexerv1.EventFilter{
+					StructType: &indexerv1.MoveStructTagFilter{Address: parts[0], Module: parts[1], Name: parts[2]},
+				},
+			},
+		}))
+	}
+
+	if len(filters) == 1 {
+		return filters[0]
+	}
+	return &indexerv1.BooleanTransactionFilter{
+		Filter: &indexerv1.BooleanTransactionFilter_LogicalOr{
+			LogicalOr: &indexerv1.LogicalOrFilters{Filters: filters},
+		},
+	}
+}
+
+func apiFilter(filter *indexerv1.APIFilter) *indexerv1.BooleanTransactionFilter {
+	return &indexerv1.BooleanTransactionFilter{
+		Filter: &indexerv1.BooleanTransactionFilter_ApiFilter{ApiFilter: filter},
+	}
+}
+
+// splitType splits <address>::<module>::<name> into its non-empty parts.
+func splitType(typ string) [3]*string {
+	var parts [3]*string
+	for i, part := range strings.SplitN(typ, "::", 3) {
+		if part != "" {
+			parts[i] = ptr(part)
+		}
+	}
+	return parts
+}
+
+// hasTypePrefix reports whether typ starts with prefix, ignoring the leading
+// zeros of the address.
+func hasTypePrefix(typ, prefix string) bool {
+	return strings.HasPrefix(trimAddress(typ), trimAddress(prefix))
+}
+
+func sameAddress(a, b string) bool {
+	return trimAddress(a) == trimAddress(b)
+}
+
+func trimAddress(s string) string {
+	s = strings.TrimPrefix(s, "0x")
+	trimmed := strings.TrimLeft(s, "0")
+	if trimmed == "" || strings.HasPrefix(trimmed, ":") {
+		return "0" + trimmed
+	}
+	return trimmed
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
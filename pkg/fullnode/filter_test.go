@@ -0,0 +1,165 @@
+package fullnode
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aptos-labs/aptos-go-sdk/api"
+)
+
+const (
+	testPackage = "0xb8a5788314451ce4d2fbbad32e1bad88d4184b73943b7fe5166eab93cf1a5a95"
+	testSender  = "0x53442c9ad0ba9b107d031371c63de56492916e40167e2a5c2cc5cbbc09699e6"
+)
+
+func testTransaction(version uint64, sender, function, eventType, resourceType string) string {
+	return fmt.Sprintf(`{
+		"type": "user_transaction", "version": "%d", "hash": "0x1", "state_change_hash": "0x1",
+		"event_root_hash": "0x1", "gas_used": "1", "success": true, "vm_status": "ok",
+		"accumulator_root_hash": "0x1", "sequence_number": "1", "max_gas_amount": "1",
+		"gas_unit_price": "1", "expiration_timestamp_secs": "1", "timestamp": "%d",
+		"sender": %q,
+		"payload": {"type": "entry_function_payload", "function": %q, "type_arguments": [], "arguments": []},
+		"events": [{"guid": {"creation_number": "0", "account_address": "0x0"}, "sequence_number": "0", "type": %q, "data": {}}],
+		"changes": [{"type": "write_resource", "address": "0x1", "state_key_hash": "0x1", "data": {"type": %q, "data": {}}}]
+	}`, version, version*1000, sender, function, eventType, resourceType)
+}
+
+func decodeTestTransaction(t *testing.T, raw string) *api.UserTransaction {
+	t.Helper()
+	var tx api.CommittedTransaction
+	if err := json.Unmarshal([]byte(raw), &tx); err != nil {
+		t.Fatalf("failed to unmarshal transaction: %v", err)
+	}
+	userTx, err := tx.UserTransaction()
+	if err != nil {
+		t.Fatalf("failed to convert transaction: %v", err)
+	}
+	return userTx
+}
+
+func TestTransactionFilterMatch(t *testing.T) {
+	decibel := testTransaction(1, testSender,
+		testPackage+"::dex_accounts::place_order_to_subaccount",
+		testPackage+"::perp_positions::TradeEvent",
+		testPackage+"::perp_positions::CrossedPosition",
+	)
+	transfer := testTransaction(2, "0x2", "0x1::aptos_account::transfer", "0x1::fungible_asset::Deposit", "0x1::account::Account")
+
+	tests := []struct {
+		name    string
+		filter  TransactionFilter
+		decibel bool
+	}{
+		{"empty", TransactionFilter{}, true},
+		{"sender", TransactionFilter{Senders: []string{testSender}}, true},
+		{"sender long form", TransactionFilter{Senders: []string{"0x0" + testSender[2:]}}, true},
+		{"entry function module", TransactionFilter{EntryFunctionModules: []string{testPackage + "::dex_accounts"}}, true},
+		{"other module", TransactionFilter{EntryFunctionModules: []string{testPackage + "::dex"}}, false},
+		{"event type", TransactionFilter{EventTypePrefixes: []string{testPackage + "::perp_positions::TradeEvent"}}, true},
+		{"package events", TransactionFilter{EventTypePrefixes: []string{testPackage + "::"}}, true},
+		{"resource type", TransactionFilter{ResourceTypePrefixes: []string{testPackage + "::perp_positions::"}}, true},
+		{"other resource", TransactionFilter{ResourceTypePrefixes: []string{testPackage + "::price_management::"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(decodeTestTransaction(t, decibel)); got != tt.decibel {
+				t.Errorf("Match(decibel) = %v, want %v", got, tt.decibel)
+			}
+			if got := tt.filter.mayMatch([]byte(decibel)); tt.decibel && !got {
+				t.Errorf("mayMatch(decibel) ruled out a matching transaction")
+			}
+			if tt.filter.IsEmpty() {
+				return
+			}
+			if tt.filter.Match(decodeTestTransaction(t, transfer)) {
+				t.Errorf("Match(transfer) = true, want false")
+			}
+			if tt.filter.mayMatch([]byte(transfer)) {
+				t.Errorf("mayMatch(transfer) = true, want false")
+			}
+		})
+	}
+}
+
+func TestTransactionFilterProto(t *testing.T) {
+	if (TransactionFilter{}).Proto() != nil {
+		t.Error("expected no proto filter for an empty filter")
+	}
+	if (TransactionFilter{ResourceTypePrefixes: []string{testPackage + "::"}}).Proto() != nil {
+		t.Error("expected no proto filter when resource types are filtered")
+	}
+
+	single := TransactionFilter{EventTypePrefixes: []string{testPackage + "::perp_positions::"}}.Proto()
+	event := single.GetApiFilter().GetEventFilter().GetStructType()
+	if event.GetAddress() != testPackage || event.GetModule() != "perp_positions" || event.Name != nil {
+		t.Errorf("unexpected event filter: %v", event)
+	}
+
+	filter := TransactionFilter{
+		Senders:              []string{testSender},
+		EntryFunctionModules: []string{testPackage + "::dex_accounts"},
+		EventTypePrefixes:    []string{testPackage + "::"},
+	}.Proto()
+	or := filter.GetLogicalOr().GetFilters()
+	if len(or) != 3 {
+		t.Fatalf("expected 3 alternatives, got %d", len(or))
+	}
+	if or[0].GetApiFilter().GetUserTransactionFilter().GetSender() != testSender {
+		t.Errorf("unexpected sender filter: %v", or[0])
+	}
+	entryFunction := or[1].GetApiFilter().GetUserTransactionFilter().GetPayloadFilter().GetEntryFunctionFilter()
+	if entryFunction.GetAddress() != testPackage || entryFunction.GetModuleName() != "dex_accounts" || entryFunction.Function != nil {
+		t.Errorf("unexpected entry function filter: %v", entryFunction)
+	}
+	if or[2].GetApiFilter().GetEventFilter().GetStructType().Module != nil {
+		t.Errorf("expected a package wide event filter: %v", or[2])
+	}
+}
+
+func TestGetTransactionsFiltered(t *testing.T) {
+	body := "[" + testTransaction(10, "0x2", "0x1::aptos_account::transfer", "0x1::fungible_asset::Deposit", "0x1::account::Account") + "," +
+		testTransaction(11, testSender, testPackage+"::dex_accounts::place_order_to_subaccount", testPackage+"::perp_positions::TradeEvent", "0x1::account::Account") + "," +
+		testTransaction(12, "0x3", "0x1::aptos_account::transfer", "0x1::fungible_asset::Deposit", "0x1::account::Account") + "]"
+	client := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+
+	start, limit := uint64(10), uint64(3)
+	page, err := client.getTransactions(&start, &limit, StreamOptions{Filter: TransactionFilter{EventTypePrefixes: []string{testPackage + "::"}}})
+	if err != nil {
+		t.Fatalf("getTransactions: %v", err)
+	}
+	if page.count != 3 || page.last.Version != 12 {
+		t.Errorf("count/last version = %d/%d, want 3/12", page.count, page.last.Version)
+	}
+	if len(page.txs) != 1 || page.txs[0].Version != 11 {
+		t.Errorf("expected only version 11 to pass the filter, got %d transactions", len(page.txs))
+	}
+}
+
+func TestStreamSendsPagesWithoutMatches(t *testing.T) {
+	client := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("start") != "10" {
+			w.Write([]byte("[]"))
+			return
+		}
+		w.Write([]byte(testPage(10, 11, 12)))
+	})
+
+	stream, err := client.NewStream(10, 100, WithFilter(TransactionFilter{EventTypePrefixes: []string{testPackage + "::"}}))
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	defer stream.Close()
+
+	batch, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if len(batch.Transactions) != 0 || batch.Version != 12 || batch.Timestamp != 12000 {
+		t.Errorf("batch = %d transactions up to %d at %d, want none up to 12 at 12000", len(batch.Transactions), batch.Version, batch.Timestamp)
+	}
+}
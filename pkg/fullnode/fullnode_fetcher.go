@@ -3,6 +3,7 @@ package fullnode
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"math"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/aptos-labs/aptos-go-sdk/api"
+	"github.com/cresendoo/decidash-backend/pkg/metrics"
 )
 
 type FullnodeFetcher struct {
@@ -25,16 +27,22 @@ type FullnodeFetcher struct {
 type FullnodeRpcStream struct {
 	ctx    context.Context
 	cancel context.CancelFunc
-	txChan chan []*api.UserTransaction
+	txChan chan Batch
 	wg     sync.WaitGroup
+	err    error
 }
 
-func (s *FullnodeRpcStream) Recv() ([]*api.UserTransaction, error) {
-	txs, ok := <-s.txChan
+// Recv returns the next batch. Once the stream stops it returns the error
+// that stopped it, or io.EOF if it was closed.
+func (s *FullnodeRpcStream) Recv() (Batch, error) {
+	batch, ok := <-s.txChan
 	if !ok {
-		return nil, io.EOF
+		if s.err != nil {
+			return Batch{}, s.err
+		}
+		return Batch{}, io.EOF
 	}
-	return txs, nil
+	return batch, nil
 }
 
 func (s *FullnodeRpcStream) Close() {
@@ -59,47 +67,85 @@ func NewFullnodeRpcClient(serverAddr string, apiKey string) (*FullnodeFetcher, e
 func (c *FullnodeFetcher) NewStream(
 	startVersion uint64,
 	limit uint64,
+	options ...StreamOption,
 ) (*FullnodeRpcStream, error) {
+	var opts StreamOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	stream := &FullnodeRpcStream{
 		ctx:    ctx,
 		cancel: cancel,
-		txChan: make(chan []*api.UserTransaction, 128),
+		txChan: make(chan Batch, 128),
 	}
 
 	limit = uint64(math.Min(float64(limit), 100))
 
 	delay := time.Millisecond * 25
+	verifier := c
+	if opts.Verifier != nil {
+		verifier = opts.Verifier
+	}
 
 	stream.wg.Add(1)
 	go func() {
 		defer stream.wg.Done()
 		defer close(stream.txChan)
 
+		var retries int
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			default:
-				txs, count, lastVersion, err := c.getTransactions(&startVersion, &limit)
-				if err != nil {
-					slog.Error("failed to get transactions", "error", err)
-					return
-				}
-				startVersion = lastVersion + 1
-				if count == 0 || count < int(limit) {
-					time.Sleep(delay)
-					continue
+			}
+
+			// a page is recorded only once it passed every check, so a
+			// refetched page is not recorded twice
+			page, err := c.getTransactions(&startVersion, &limit, opts)
+			if err == nil && opts.VerifyAccumulator && page.count > 0 {
+				err = verifier.verifyAccumulator(page.last)
+			}
+			if err == nil && opts.Recorder != nil {
+				err = page.record(opts.Recorder)
+			}
+			if err != nil {
+				var inconsistency *ConsistencyError
+				if errors.As(err, &inconsistency) {
+					metrics.ConsistencyErrors.WithLabelValues(inconsistency.Kind).Inc()
+					slog.Error("inconsistent transactions from node, refetching", "start", startVersion, "error", err)
+				} else {
+					metrics.FetchErrors.WithLabelValues("fullnode").Inc()
+					slog.Warn("failed to get transactions, refetching", "start", startVersion, "error", err)
 				}
-				if len(txs) == 0 {
-					continue
+				if retries++; retries > maxRefetches {
+					slog.Error("giving up on transactions", "start", startVersion, "error", err)
+					stream.err = err
+					return
 				}
-
-				select {
-				case <-ctx.Done():
+				if !sleepContext(ctx, refetchDelay<<(retries-1)) {
 					return
-				case stream.txChan <- txs:
 				}
+				continue
+			}
+			retries = 0
+
+			if page.count == 0 {
+				sleepContext(ctx, delay)
+				continue
+			}
+			startVersion = page.last.Version + 1
+			// pages without matches are sent too, so consumers keep up
+			// with the scanned version in quiet periods
+			select {
+			case <-ctx.Done():
+				return
+			case stream.txChan <- Batch{Transactions: page.txs, Version: page.last.Version, Timestamp: page.last.Timestamp}:
+			}
+			if page.count < int(limit) {
+				sleepContext(ctx, delay)
 			}
 		}
 	}()
@@ -107,10 +153,43 @@ func (c *FullnodeFetcher) NewStream(
 	return stream, nil
 }
 
+// transactionPage is one response of the transactions endpoint. count, last
+// and raws cover every transaction of the page, including those filtered out.
+type transactionPage struct {
+	txs   []*api.UserTransaction
+	count int
+	last  transactionInfo
+	raws  []json.RawMessage
+	infos []transactionInfo
+}
+
+// record writes the raw stream of the page before filtering, so a replay
+// can run under any filter.
+func (p transactionPage) record(recorder *Recorder) error {
+	for i, raw := range p.raws {
+		if err := recorder.Record(p.infos[i].Version, raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type transactionInfo struct {
+	Version             uint64 `json:"version,string"`
+	Hash                string `json:"hash"`
+	AccumulatorRootHash string `json:"accumulator_root_hash"`
+	// Timestamp is 0 for the genesis transaction, which has none.
+	Timestamp uint64 `json:"timestamp,string"`
+}
+
+// getTransactions fetches a page of transactions. The versions of the page
+// must be contiguous from startVersion, otherwise it returns a
+// ConsistencyError and the page must be refetched.
 func (c *FullnodeFetcher) getTransactions(
 	startVersion *uint64,
 	limit *uint64,
-) ([]*api.UserTransaction, int, uint64, error) {
+	opts StreamOptions,
+) (transactionPage, error) {
 	requestURI := c.baseUrl.JoinPath("/transactions")
 	params := url.Values{}
 	if startVersion != nil {
@@ -121,39 +200,90 @@ func (c *FullnodeFetcher) getTransactions(
 	}
 	requestURI.RawQuery = params.Encode()
 
-	req, err := http.NewRequest("GET", requestURI.String(), nil)
-	if err != nil {
-		return nil, 0, *startVersion, err
+	var raws []json.RawMessage
+	if err := c.getJSON(requestURI, &raws); err != nil {
+		return transactionPage{}, err
 	}
-	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if len(raws) == 0 {
+		return transactionPage{}, nil
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, 0, *startVersion, err
+	infos := make([]transactionInfo, len(raws))
+	for i, raw := range raws {
+		if err := json.Unmarshal(raw, &infos[i]); err != nil {
+			return transactionPage{}, err
+		}
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, 0, *startVersion, err
+	if err := checkContinuity(startVersion, infos); err != nil {
+		return transactionPage{}, err
 	}
 
-	var txs []*api.CommittedTransaction
-	if err := json.Unmarshal(body, &txs); err != nil {
-		return nil, 0, *startVersion, err
+	page := transactionPage{count: len(raws), last: infos[len(infos)-1], raws: raws, infos: infos}
+	for _, raw := range raws {
+		if !opts.Filter.mayMatch(raw) {
+			continue
+		}
+		var tx api.CommittedTransaction
+		if err := json.Unmarshal(raw, &tx); err != nil {
+			return transactionPage{}, err
+		}
+		if tx.Type != api.TransactionVariantUser {
+			continue
+		}
+		userTx, err := tx.UserTransaction()
+		if err != nil {
+			continue
+		}
+		if !opts.Filter.Match(userTx) {
+			continue
+		}
+		page.txs = append(page.txs, userTx)
 	}
+	return page, nil
+}
 
-	var userTxs []*api.UserTransaction
-	for _, tx := range txs {
-		if tx.Type == api.TransactionVariantUser {
-			userTx, err := tx.UserTransaction()
-			if err != nil {
-				continue
-			}
-			userTxs = append(userTxs, userTx)
+// checkContinuity verifies that the versions of a page follow each other
+// without gaps or duplicates, starting at startVersion if given.
+func checkContinuity(startVersion *uint64, infos []transactionInfo) error {
+	expected := infos[0].Version
+	if startVersion != nil {
+		expected = *startVersion
+	}
+	for _, info := range infos {
+		switch {
+		case info.Version > expected:
+			return &ConsistencyError{Kind: ConsistencyGap, Expected: expected, Got: info.Version}
+		case info.Version < expected:
+			return &ConsistencyError{Kind: ConsistencyDuplicate, Expected: expected, Got: info.Version}
 		}
+		expected++
+	}
+	return nil
+}
+
+// verifyAccumulator fetches the last transaction of a page by version and
+// checks that c agrees on its hash and accumulator root hash, i.e. the page
+// was not served from a ledger that was rolled back. Since the accumulator
+// root covers every earlier version, agreeing on the last one is enough.
+func (c *FullnodeFetcher) verifyAccumulator(last transactionInfo) error {
+	var info transactionInfo
+	requestURI := c.baseUrl.JoinPath("/transactions/by_version", strconv.FormatUint(last.Version, 10))
+	if err := c.getJSON(requestURI, &info); err != nil {
+		return err
+	}
+	if info.Hash != last.Hash || info.AccumulatorRootHash != last.AccumulatorRootHash {
+		return &ConsistencyError{Kind: ConsistencyAccumulator, Expected: last.Version, Got: info.Version}
+	}
+	return nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
 	}
-	return userTxs, len(txs), txs[len(txs)-1].Version(), nil
 }
@@ -18,12 +18,12 @@ func TestFullnodeRpcClient(t *testing.T) {
 		t.Fatalf("failed to create fullnode rpc client: %v", err)
 	}
 
-	txs, _, _, err := client.getTransactions(nil, nil)
+	page, err := client.getTransactions(nil, nil, StreamOptions{})
 	if err != nil {
 		t.Fatalf("failed to get fullnode rpc client: %v", err)
 	}
 
-	t.Log(len(txs))
+	t.Log(len(page.txs))
 }
 
 func TestFullnodeRpcStream(t *testing.T) {
@@ -44,7 +44,7 @@ func TestFullnodeRpcStream(t *testing.T) {
 	}()
 
 	for {
-		txs, err := stream.Recv()
+		batch, err := stream.Recv()
 		if err != nil {
 			if err == io.EOF {
 				return
@@ -52,6 +52,7 @@ func TestFullnodeRpcStream(t *testing.T) {
 			t.Fatalf("failed to read txs: %v", err)
 			return
 		}
+		txs := batch.Transactions
 		fmt.Println(len(txs))
 		fmt.Println(txs[0].Version)
 		fmt.Println(txs[len(txs)-1].Version)
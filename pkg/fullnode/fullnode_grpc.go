@@ -19,6 +19,19 @@ type fullnodeGrpcClient struct {
 }
 
 func NewFullnodeClient(serverAddr string, apiKey string, useTLS bool) (*fullnodeGrpcClient, error) {
+	conn, err := dial(serverAddr, useTLS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fullnodeGrpcClient{
+		fullnodeClient: fullnodev1.NewFullnodeDataClient(conn),
+		conn:           conn,
+		apiKey:         apiKey,
+	}, nil
+}
+
+func dial(serverAddr string, useTLS bool) (*grpc.ClientConn, error) {
 	var creds credentials.TransportCredentials
 	if useTLS {
 		creds = credentials.NewTLS(nil)
@@ -39,15 +52,19 @@ func NewFullnodeClient(serverAddr string, apiKey string, useTLS bool) (*fullnode
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to gRPC server: %w", err)
 	}
-
-	return &fullnodeGrpcClient{
-		fullnodeClient: fullnodev1.NewFullnodeDataClient(conn),
-		conn:           conn,
-		apiKey:         apiKey,
-	}, nil
+	return conn, nil
 }
 
-func (c *fullnodeGrpcClient) NewStream(ctx context.Context, startVersion uint64, count uint64) (grpc.ServerStreamingClient[fullnodev1.TransactionsFromNodeResponse], error) {
+// NewStream streams count transactions from startVersion, or every following
+// transaction if count is 0. The fullnode service cannot filter server side,
+// so the transactions not matching filter are dropped from each response as
+// it is received; the status responses still report every version streamed.
+func (c *fullnodeGrpcClient) NewStream(
+	ctx context.Context,
+	startVersion uint64,
+	count uint64,
+	filter TransactionFilter,
+) (grpc.ServerStreamingClient[fullnodev1.TransactionsFromNodeResponse], error) {
 	ctx = c.createAuthContext(ctx)
 
 	request := &fullnodev1.GetTransactionsFromNodeRequest{
@@ -62,7 +79,34 @@ func (c *fullnodeGrpcClient) NewStream(ctx context.Context, startVersion uint64,
 	if err != nil {
 		return nil, err
 	}
-	return stream, nil
+	if filter.IsEmpty() {
+		return stream, nil
+	}
+	return &filteredStream{ServerStreamingClient: stream, filter: filter}, nil
+}
+
+// filteredStream drops the transactions not matching filter from the
+// responses of a fullnode stream.
+type filteredStream struct {
+	grpc.ServerStreamingClient[fullnodev1.TransactionsFromNodeResponse]
+	filter TransactionFilter
+}
+
+func (s *filteredStream) Recv() (*fullnodev1.TransactionsFromNodeResponse, error) {
+	resp, err := s.ServerStreamingClient.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if data := resp.GetData(); data != nil {
+		matched := data.Transactions[:0]
+		for _, tx := range data.Transactions {
+			if s.filter.matchProto(tx) {
+				matched = append(matched, tx)
+			}
+		}
+		data.Transactions = matched
+	}
+	return resp, nil
 }
 
 func (c *fullnodeGrpcClient) Close() error {
@@ -70,8 +114,12 @@ func (c *fullnodeGrpcClient) Close() error {
 }
 
 func (c *fullnodeGrpcClient) createAuthContext(ctx context.Context) context.Context {
-	if c.apiKey != "" {
-		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.apiKey)
+	return authContext(ctx, c.apiKey)
+}
+
+func authContext(ctx context.Context, apiKey string) context.Context {
+	if apiKey != "" {
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+apiKey)
 	}
 	return ctx
 }
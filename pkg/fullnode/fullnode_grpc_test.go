@@ -0,0 +1,98 @@
+package fullnode
+
+import (
+	"testing"
+
+	fullnodev1 "github.com/ice-coldbell/aptos-indexer-grpc-go/aptos/fullnode/v1"
+	transactionv1 "github.com/ice-coldbell/aptos-indexer-grpc-go/aptos/transaction/v1"
+	"google.golang.org/grpc"
+)
+
+func testProtoTransaction(version uint64, sender, module, eventType, resourceType string) *transactionv1.Transaction {
+	return &transactionv1.Transaction{
+		Version: version,
+		TxnData: &transactionv1.Transaction_User{User: &transactionv1.UserTransaction{
+			Request: &transactionv1.UserTransactionRequest{
+				Sender: sender,
+				Payload: &transactionv1.TransactionPayload{
+					Payload: &transactionv1.TransactionPayload_EntryFunctionPayload{EntryFunctionPayload: &transactionv1.EntryFunctionPayload{
+						Function: &transactionv1.EntryFunctionId{
+							Module: &transactionv1.MoveModuleId{Address: testPackage, Name: module},
+							Name:   "run",
+						},
+					}},
+				},
+			},
+			Events: []*transactionv1.Event{{TypeStr: eventType}},
+		}},
+		Info: &transactionv1.TransactionInfo{
+			Changes: []*transactionv1.WriteSetChange{{
+				Change: &transactionv1.WriteSetChange_WriteResource{WriteResource: &transactionv1.WriteResource{TypeStr: resourceType}},
+			}},
+		},
+	}
+}
+
+type testNodeStream struct {
+	grpc.ServerStreamingClient[fullnodev1.TransactionsFromNodeResponse]
+	responses []*fullnodev1.TransactionsFromNodeResponse
+}
+
+func (s *testNodeStream) Recv() (*fullnodev1.TransactionsFromNodeResponse, error) {
+	resp := s.responses[0]
+	s.responses = s.responses[1:]
+	return resp, nil
+}
+
+func TestTransactionFilterMatchProto(t *testing.T) {
+	other := testProtoTransaction(1, "0x2", "aptos_account", "0x1::fungible_asset::Deposit", "0x1::account::Account")
+	tests := []struct {
+		name   string
+		filter TransactionFilter
+		tx     *transactionv1.Transaction
+		want   bool
+	}{
+		{name: "empty filter", tx: other, want: true},
+		{name: "sender", filter: TransactionFilter{Senders: []string{testSender}}, tx: testProtoTransaction(1, testSender, "aptos_account", "", ""), want: true},
+		{name: "entry module", filter: TransactionFilter{EntryFunctionModules: []string{testPackage + "::dex_accounts"}}, tx: testProtoTransaction(1, "0x2", "dex_accounts", "", ""), want: true},
+		{name: "event", filter: TransactionFilter{EventTypePrefixes: []string{testPackage + "::perp_positions::"}}, tx: testProtoTransaction(1, "0x2", "x", testPackage+"::perp_positions::TradeEvent", ""), want: true},
+		{name: "resource", filter: TransactionFilter{ResourceTypePrefixes: []string{testPackage + "::"}}, tx: testProtoTransaction(1, "0x2", "x", "", testPackage+"::perp_market::PerpMarket"), want: true},
+		{name: "no criteria met", filter: TransactionFilter{Senders: []string{testSender}, EventTypePrefixes: []string{testPackage + "::"}}, tx: other},
+		{name: "not a user transaction", filter: TransactionFilter{Senders: []string{testSender}}, tx: &transactionv1.Transaction{Version: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matchProto(tt.tx); got != tt.want {
+				t.Errorf("matchProto = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilteredStream(t *testing.T) {
+	status := &fullnodev1.TransactionsFromNodeResponse{Response: &fullnodev1.TransactionsFromNodeResponse_Status{
+		Status: &fullnodev1.StreamStatus{StartVersion: 10},
+	}}
+	data := &fullnodev1.TransactionsFromNodeResponse{Response: &fullnodev1.TransactionsFromNodeResponse_Data{
+		Data: &fullnodev1.TransactionsOutput{Transactions: []*transactionv1.Transaction{
+			testProtoTransaction(10, "0x2", "aptos_account", "0x1::fungible_asset::Deposit", "0x1::account::Account"),
+			testProtoTransaction(11, "0x2", "dex_accounts", testPackage+"::perp_positions::TradeEvent", "0x1::account::Account"),
+		}},
+	}}
+	stream := &filteredStream{
+		ServerStreamingClient: &testNodeStream{responses: []*fullnodev1.TransactionsFromNodeResponse{status, data}},
+		filter:                TransactionFilter{EventTypePrefixes: []string{testPackage + "::"}},
+	}
+
+	resp, err := stream.Recv()
+	if err != nil || resp.GetStatus().GetStartVersion() != 10 {
+		t.Fatalf("Recv = %v, %v, want the status response", resp, err)
+	}
+	resp, err = stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if txs := resp.GetTransactions(); len(txs) != 1 || txs[0].GetVersion() != 11 {
+		t.Errorf("transactions = %v, want only version 11", txs)
+	}
+}
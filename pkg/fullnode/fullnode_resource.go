@@ -0,0 +1,79 @@
+package fullnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ErrNotFound is returned when the node has no such account or resource.
+var ErrNotFound = errors.New("not found")
+
+type LedgerInfo struct {
+	ChainID         uint8  `json:"chain_id"`
+	LedgerVersion   uint64 `json:"ledger_version,string"`
+	LedgerTimestamp uint64 `json:"ledger_timestamp,string"` // microseconds
+}
+
+type AccountResource struct {
+	Type string         `json:"type"`
+	Data map[string]any `json:"data"`
+}
+
+// GetLedgerInfo returns the latest ledger version of the node.
+func (c *FullnodeFetcher) GetLedgerInfo() (LedgerInfo, error) {
+	var info LedgerInfo
+	if err := c.getJSON(c.baseUrl.JoinPath("/"), &info); err != nil {
+		return LedgerInfo{}, err
+	}
+	return info, nil
+}
+
+// GetAccountResource reads a resource of an account at ledgerVersion, or at
+// the latest version if ledgerVersion is 0. It returns ErrNotFound if the
+// account does not hold the resource.
+func (c *FullnodeFetcher) GetAccountResource(address, resourceType string, ledgerVersion uint64) (AccountResource, error) {
+	requestURI := c.baseUrl.JoinPath("/accounts", address, "resource", resourceType)
+	if ledgerVersion > 0 {
+		params := url.Values{}
+		params.Set("ledger_version", strconv.FormatUint(ledgerVersion, 10))
+		requestURI.RawQuery = params.Encode()
+	}
+	var resource AccountResource
+	if err := c.getJSON(requestURI, &resource); err != nil {
+		return AccountResource{}, err
+	}
+	return resource, nil
+}
+
+func (c *FullnodeFetcher) getJSON(requestURI *url.URL, out any) error {
+	req, err := http.NewRequest("GET", requestURI.String(), nil)
+	if err != nil {
+		return err
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+	return json.Unmarshal(body, out)
+}
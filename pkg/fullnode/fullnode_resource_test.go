@@ -0,0 +1,67 @@
+package fullnode
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestFetcher(t *testing.T, handler http.HandlerFunc) *FullnodeFetcher {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := NewFullnodeRpcClient(server.URL+"/v1", "key")
+	if err != nil {
+		t.Fatalf("failed to create fullnode rpc client: %v", err)
+	}
+	return client
+}
+
+func TestGetLedgerInfo(t *testing.T) {
+	client := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1" && r.URL.Path != "/v1/" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"chain_id":4,"epoch":"10","ledger_version":"32667225","ledger_timestamp":"1760000000000000"}`))
+	})
+
+	info, err := client.GetLedgerInfo()
+	if err != nil {
+		t.Fatalf("GetLedgerInfo: %v", err)
+	}
+	if info.ChainID != 4 || info.LedgerVersion != 32667225 || info.LedgerTimestamp != 1760000000000000 {
+		t.Errorf("unexpected ledger info: %+v", info)
+	}
+}
+
+func TestGetAccountResource(t *testing.T) {
+	const resourceType = "0x1::object::ObjectCore"
+	client := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		switch r.URL.Path {
+		case "/v1/accounts/0x1/resource/" + resourceType:
+			if got := r.URL.Query().Get("ledger_version"); got != "100" {
+				t.Errorf("ledger_version = %q, want 100", got)
+			}
+			w.Write([]byte(`{"type":"0x1::object::ObjectCore","data":{"owner":"0x2"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code":"resource_not_found"}`))
+		}
+	})
+
+	resource, err := client.GetAccountResource("0x1", resourceType, 100)
+	if err != nil {
+		t.Fatalf("GetAccountResource: %v", err)
+	}
+	if resource.Type != resourceType || resource.Data["owner"] != "0x2" {
+		t.Errorf("unexpected resource: %+v", resource)
+	}
+
+	if _, err := client.GetAccountResource("0x3", resourceType, 100); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
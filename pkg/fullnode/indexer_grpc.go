@@ -0,0 +1,61 @@
+package fullnode
+
+import (
+	"context"
+
+	indexerv1 "github.com/ice-coldbell/aptos-indexer-grpc-go/aptos/indexer/v1"
+	"google.golang.org/grpc"
+)
+
+// indexerGrpcClient streams transactions from the indexer gRPC data service,
+// which unlike the fullnode service can filter them server side.
+type indexerGrpcClient struct {
+	rawDataClient indexerv1.RawDataClient
+
+	conn   *grpc.ClientConn
+	apiKey string
+}
+
+func NewIndexerClient(serverAddr string, apiKey string, useTLS bool) (*indexerGrpcClient, error) {
+	conn, err := dial(serverAddr, useTLS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &indexerGrpcClient{
+		rawDataClient: indexerv1.NewRawDataClient(conn),
+		conn:          conn,
+		apiKey:        apiKey,
+	}, nil
+}
+
+// NewStream streams count transactions from startVersion, or every following
+// transaction if count is 0. Filters with resource type prefixes cannot be
+// applied by the data service and stream every transaction.
+func (c *indexerGrpcClient) NewStream(
+	ctx context.Context,
+	startVersion uint64,
+	count uint64,
+	filter TransactionFilter,
+) (grpc.ServerStreamingClient[indexerv1.TransactionsResponse], error) {
+	ctx = authContext(ctx, c.apiKey)
+
+	request := &indexerv1.GetTransactionsRequest{
+		StartingVersion:   &startVersion,
+		TransactionsCount: &count,
+		TransactionFilter: filter.Proto(),
+	}
+	if count == 0 {
+		request.TransactionsCount = nil
+	}
+
+	stream, err := c.rawDataClient.GetTransactions(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (c *indexerGrpcClient) Close() error {
+	return c.conn.Close()
+}
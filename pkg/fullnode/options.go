@@ -0,0 +1,49 @@
+package fullnode
+
+type StreamOptions struct {
+	Filter            TransactionFilter
+	Recorder          *Recorder
+	VerifyAccumulator bool
+	// Verifier is the node pages are verified against, the streaming node
+	// itself if nil.
+	Verifier *FullnodeFetcher
+}
+
+type StreamOption func(*StreamOptions)
+
+// WithFilter only streams the transactions matching filter. Versions are
+// still consumed in order, so a batch may hold fewer transactions than
+// requested or none at all; its Version still moves forward.
+func WithFilter(filter TransactionFilter) StreamOption {
+	return func(o *StreamOptions) {
+		o.Filter = filter
+	}
+}
+
+// WithRecorder records the raw JSON of every scanned transaction, including
+// those the filter leaves out, so the stream can be replayed later with
+// NewFileStream under any filter.
+func WithRecorder(recorder *Recorder) StreamOption {
+	return func(o *StreamOptions) {
+		o.Recorder = recorder
+	}
+}
+
+// WithVerifyAccumulator checks the last transaction of every page against
+// the node's accumulator before the page is streamed, at the cost of one
+// more request per page.
+func WithVerifyAccumulator() StreamOption {
+	return func(o *StreamOptions) {
+		o.VerifyAccumulator = true
+	}
+}
+
+// WithVerifyNode checks every page against the accumulator of a second
+// node, which catches a streaming node that serves a forked or rolled back
+// ledger consistently.
+func WithVerifyNode(node *FullnodeFetcher) StreamOption {
+	return func(o *StreamOptions) {
+		o.VerifyAccumulator = true
+		o.Verifier = node
+	}
+}
@@ -0,0 +1,93 @@
+package fullnode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// RecordFileExt is the extension of the files written by Recorder.
+const RecordFileExt = ".jsonl.gz"
+
+// Recorder writes raw transactions into gzip compressed JSONL files, one
+// transaction per line. A file is rotated after maxPerFile transactions and
+// named after the first version it holds, so the files of a directory replay
+// in name order.
+type Recorder struct {
+	dir        string
+	maxPerFile int
+
+	mu    sync.Mutex
+	file  *os.File
+	gz    *gzip.Writer
+	count int
+}
+
+func NewRecorder(dir string, maxPerFile int) (*Recorder, error) {
+	if maxPerFile <= 0 {
+		return nil, fmt.Errorf("invalid transactions per file %d", maxPerFile)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Recorder{dir: dir, maxPerFile: maxPerFile}, nil
+}
+
+// Record appends the raw JSON of the transaction at version.
+func (r *Recorder) Record(version uint64, raw json.RawMessage) error {
+	var line bytes.Buffer
+	if err := json.Compact(&line, raw); err != nil {
+		return err
+	}
+	line.WriteByte('\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.gz == nil {
+		if err := r.open(version); err != nil {
+			return err
+		}
+	}
+	if _, err := r.gz.Write(line.Bytes()); err != nil {
+		return err
+	}
+	r.count++
+	if r.count >= r.maxPerFile {
+		return r.closeFile()
+	}
+	return nil
+}
+
+// Close flushes and closes the current file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closeFile()
+}
+
+func (r *Recorder) open(version uint64) error {
+	file, err := os.Create(filepath.Join(r.dir, fmt.Sprintf("%020d%s", version, RecordFileExt)))
+	if err != nil {
+		return err
+	}
+	r.file = file
+	r.gz = gzip.NewWriter(file)
+	r.count = 0
+	return nil
+}
+
+func (r *Recorder) closeFile() error {
+	if r.gz == nil {
+		return nil
+	}
+	err := r.gz.Close()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	r.file, r.gz = nil, nil
+	return err
+}
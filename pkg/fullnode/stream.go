@@ -0,0 +1,207 @@
+package fullnode
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aptos-labs/aptos-go-sdk/api"
+)
+
+// Stream delivers batches of user transactions in version order. Recv
+// returns io.EOF once the stream is closed or exhausted.
+type Stream interface {
+	Recv() (Batch, error)
+	Close()
+}
+
+// Batch is the next part of a stream. Version is the last version scanned
+// for it, which the filter may have left out, so a batch without
+// transactions still tells how far the stream got.
+type Batch struct {
+	Transactions []*api.UserTransaction
+	Version      uint64
+	// Timestamp is the time of Version in microseconds, 0 if unknown.
+	Timestamp uint64
+}
+
+var (
+	_ Stream = (*FullnodeRpcStream)(nil)
+	_ Stream = (*FileStream)(nil)
+)
+
+// FileStream replays transactions written by Recorder.
+type FileStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	txChan chan Batch
+	wg     sync.WaitGroup
+	err    error
+}
+
+func (s *FileStream) Recv() (Batch, error) {
+	batch, ok := <-s.txChan
+	if !ok {
+		if s.err != nil {
+			return Batch{}, s.err
+		}
+		return Batch{}, io.EOF
+	}
+	return batch, nil
+}
+
+func (s *FileStream) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// RecordFiles returns the recorded files of dir in replay order.
+func RecordFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+RecordFileExt))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// NewFileStream replays the transactions of files from startVersion in
+// batches of up to limit scanned transactions. Files are read in the given
+// order; plain .jsonl files are read uncompressed.
+func NewFileStream(
+	files []string,
+	startVersion uint64,
+	limit uint64,
+	options ...StreamOption,
+) (*FileStream, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no files to replay")
+	}
+	if limit == 0 {
+		limit = 100
+	}
+	var opts StreamOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &FileStream{
+		ctx:    ctx,
+		cancel: cancel,
+		txChan: make(chan Batch, 128),
+	}
+
+	stream.wg.Add(1)
+	go func() {
+		defer stream.wg.Done()
+		defer close(stream.txChan)
+
+		var batch Batch
+		var scanned uint64
+		// next skips the versions of overlapping files that were already
+		// replayed
+		next := startVersion
+		send := func() bool {
+			if scanned == 0 {
+				return true
+			}
+			select {
+			case <-ctx.Done():
+				return false
+			case stream.txChan <- batch:
+				batch, scanned = Batch{}, 0
+				return true
+			}
+		}
+		for _, file := range files {
+			if ctx.Err() != nil {
+				return
+			}
+			err := readRecordFile(file, func(raw []byte) (bool, error) {
+				var info transactionInfo
+				if err := json.Unmarshal(raw, &info); err != nil {
+					return false, err
+				}
+				if info.Version < next {
+					return true, nil
+				}
+				next = info.Version + 1
+				batch.Version, batch.Timestamp = info.Version, info.Timestamp
+				scanned++
+				if opts.Filter.mayMatch(raw) {
+					var tx api.CommittedTransaction
+					if err := json.Unmarshal(raw, &tx); err != nil {
+						return false, err
+					}
+					if tx.Type == api.TransactionVariantUser {
+						if userTx, err := tx.UserTransaction(); err == nil && opts.Filter.Match(userTx) {
+							batch.Transactions = append(batch.Transactions, userTx)
+						}
+					}
+				}
+				if scanned < limit {
+					return true, nil
+				}
+				return send(), nil
+			})
+			if err != nil {
+				slog.Error("failed to replay transactions", "file", file, "error", err)
+				stream.err = err
+				return
+			}
+		}
+		send()
+	}()
+
+	return stream, nil
+}
+
+// readRecordFile calls fn with every line of file until fn returns false.
+func readRecordFile(file string, fn func(raw []byte) (bool, error)) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(file, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			ok, fnErr := fn(line)
+			if fnErr != nil {
+				return fnErr
+			}
+			if !ok {
+				return nil
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
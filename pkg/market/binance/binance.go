@@ -3,34 +3,71 @@ package binance
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"log/slog"
+	"net/http"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/cresendoo/decidash-backend/pkg/market"
+	"github.com/cresendoo/decidash-backend/pkg/metrics"
 	"github.com/cresendoo/decidash-backend/pkg/xwebsocket"
 )
 
+// Client is one connection to the Binance USDⓈ-M futures combined stream.
+// Clients share no state, so several can run in one process.
 type Client struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 	logger *slog.Logger
-	
-	conn   *xwebsocket.WebsocketClient
-	subscribed map[string]bool
+
+	conn      *xwebsocket.WebsocketClient
 	requestID atomic.Int64
-	orderbook OrderbookMap
+	orderbook *OrderbookMap
+	options   ClientOptions
+
+	// subMu guards subscribed and pending; sendMu serialises requests to
+	// pace them under the connection's message limit.
+	subMu       sync.Mutex
+	subscribed  map[string]bool
+	pending     map[int64]chan error
+	sendMu      sync.Mutex
+	lastRequest time.Time
+
+	depthMu sync.Mutex
+	depth   map[string]*depthSync
+
+	connected   atomic.Bool
+	lastMessage atomic.Int64 // unix nanoseconds
+
+	providerMu    sync.RWMutex
+	symbols       map[string]bool
+	markPrices    map[string]MarkPrice
+	tradeHandlers []func(market.Trade)
 }
 
-func NewClient(rootCtx context.Context) (*Client, error) {
-	ctx, cancel := context.WithCancel(rootCtx)	
+func NewClient(rootCtx context.Context, options ...ClientOption) (*Client, error) {
+	ctx, cancel := context.WithCancel(rootCtx)
 	client := Client{
-		ctx:    ctx,
-		cancel: cancel,
-		logger: slog.With("name", "market.binance"),
+		ctx:        ctx,
+		cancel:     cancel,
+		logger:     slog.With("name", "market.binance"),
+		orderbook:  NewOrderbookMap(),
 		subscribed: make(map[string]bool),
-		orderbook: NewOrderbookMap(),
+		pending:    make(map[int64]chan error),
+		depth:      make(map[string]*depthSync),
+		symbols:    make(map[string]bool),
+		markPrices: make(map[string]MarkPrice),
+		options: ClientOptions{
+			WebsocketURL: websocketURL,
+			RestURL:      restURL,
+			HTTPClient:   &http.Client{Timeout: 10 * time.Second},
+			AckTimeout:   defaultAckTimeout,
+		},
+	}
+	for _, option := range options {
+		option(&client.options)
 	}
 	return &client, nil
 }
@@ -39,6 +76,7 @@ func (c *Client) Start() error {
 	if err := c.connect(); err != nil {
 		return err
 	}
+	c.wg.Add(1)
 	go c.readLoop()
 	return nil
 }
@@ -49,43 +87,26 @@ func (c *Client) Stop() error {
 	return nil
 }
 
-func (c *Client) Subscribe(param string) error {
-	if c.subscribed[param] {
-		return nil
-	}
-	c.subscribed[param] = true
-	requestID := c.requestID.Add(1)
-	return c.conn.Send(
-		[]byte(fmt.Sprintf(`{"method":"SUBSCRIBE","params":["%s"],"id":%d}`, param, requestID)),
-	)
-}
-
 func (c *Client) GetOrderbook(symbol string) (Orderbook, bool) {
-	mu.RLock()
-	orderbook, ok := c.orderbook[symbol]
-	orderbook = orderbook.DeepCopy()
-	mu.RUnlock()
-	if !ok {
-		return Orderbook{}, false
-	}
-	return orderbook, true
+	return c.orderbook.Get(symbol)
 }
 
 func (c *Client) connect() error {
 	conn, err := xwebsocket.New(
 		c.ctx,
-		websocketURL,
+		c.options.WebsocketURL,
 		xwebsocket.WithOnReconnect(c.onReconnect),
+		xwebsocket.WithOnClose(func() { c.connected.Store(false) }),
 	)
 	if err != nil {
 		return err
 	}
 	c.conn = conn
+	c.connected.Store(true)
 	return nil
 }
 
 func (c *Client) readLoop() {
-	c.wg.Add(1)
 	defer c.wg.Done()
 	defer c.logger.Debug("readLoop closed")
 
@@ -104,19 +125,43 @@ func (c *Client) readLoop() {
 }
 
 func (c *Client) onMessage(message []byte) {
-	var response BookDepthResponse
+	metrics.WebsocketMessages.WithLabelValues("binance").Inc()
+	c.lastMessage.Store(time.Now().UnixNano())
+	var response Response[json.RawMessage]
 	if err := json.Unmarshal(message, &response); err != nil {
 		c.logger.Error("failed to unmarshal message", "error", err)
 		return
 	}
-	c.orderbook.Update(response)
+	if response.Stream == "" {
+		// subscription acks and errors carry no stream
+		c.onAck(message)
+		return
+	}
+	if err := c.route(response.Stream, response.Data); err != nil {
+		c.logger.Error("failed to handle message", "stream", response.Stream, "error", err)
+	}
 }
 
-func (c *Client) onReconnect() {
-	for param := range c.subscribed {
-		if err := c.Subscribe(param); err != nil {
-			c.logger.Error("failed to subscribe", "error", err)
+func (c *Client) notifyOrderbook(symbol string) {
+	if c.options.OnOrderbookUpdate != nil {
+		if orderbook, ok := c.GetOrderbook(symbol); ok {
+			c.options.OnOrderbookUpdate(orderbook)
 		}
 	}
+}
+
+func (c *Client) onReconnect() {
+	metrics.WebsocketReconnects.WithLabelValues("binance").Inc()
+	c.connected.Store(true)
+	c.resetDepth()
+	// called from the websocket's read loop, which has to keep running to
+	// receive the acks
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		if err := c.resubscribe(); err != nil {
+			c.logger.Error("failed to resubscribe", "error", err)
+		}
+	}()
 	c.logger.Info("reconnected")
-}
\ No newline at end of file
+}
@@ -0,0 +1,191 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/pkg/metrics"
+)
+
+const (
+	depthUpdateEvent = "depthUpdate"
+	// depthSnapshotLimit is the number of levels per side requested for a
+	// snapshot.
+	depthSnapshotLimit = 1000
+	// maxBufferedDepth bounds the events kept while a snapshot is fetched.
+	// Dropping older ones is safe: a snapshot that is too old for the
+	// remaining events is detected and fetched again.
+	maxBufferedDepth      = 1000
+	snapshotRetryDelay    = time.Second
+	maxSnapshotRetryDelay = 30 * time.Second
+)
+
+// DepthSnapshot is the REST depth snapshot of a symbol.
+type DepthSnapshot struct {
+	LastUpdateID int64      `json:"lastUpdateId"`
+	Bids         [][]string `json:"bids"`
+	Asks         [][]string `json:"asks"`
+}
+
+// depthSync tracks where the local book of one symbol is relative to the
+// diff-depth stream, following Binance's procedure to manage a local book:
+// events are buffered until a snapshot is loaded, events older than the
+// snapshot are dropped, the first applied event must straddle the snapshot
+// and every later one must continue the previous (pu == previous u).
+// A gap puts the symbol back to buffering and fetches a new snapshot.
+type depthSync struct {
+	synced   bool
+	fetching bool
+	// first is set until the first event after a snapshot was applied.
+	first        bool
+	lastUpdateID int64
+	buffer       []BookDepthResponse
+}
+
+// onDepth feeds one diff-depth event into the sync state of its symbol and
+// reports whether the local book changed.
+func (c *Client) onDepth(event BookDepthResponse) bool {
+	c.depthMu.Lock()
+	defer c.depthMu.Unlock()
+	state, ok := c.depth[event.Symbol]
+	if !ok {
+		state = &depthSync{}
+		c.depth[event.Symbol] = state
+	}
+	return c.applyDepth(event.Symbol, state, event)
+}
+
+func (c *Client) applyDepth(symbol string, state *depthSync, event BookDepthResponse) bool {
+	if !state.synced {
+		state.buffer = append(state.buffer, event)
+		if len(state.buffer) > maxBufferedDepth {
+			state.buffer = state.buffer[len(state.buffer)-maxBufferedDepth:]
+		}
+		if !state.fetching {
+			state.fetching = true
+			c.wg.Add(1)
+			go c.syncDepth(symbol, state)
+		}
+		return false
+	}
+	if event.FinalUpdateID < state.lastUpdateID {
+		return false
+	}
+	if state.first {
+		if event.FirstUpdateID > state.lastUpdateID {
+			c.resyncDepth(symbol, state, event, "snapshot older than stream")
+			return false
+		}
+	} else if event.FinalUpdateIDInLastStream != state.lastUpdateID {
+		c.resyncDepth(symbol, state, event, "gap in depth stream")
+		return false
+	}
+	c.orderbook.Update(event)
+	state.first = false
+	state.lastUpdateID = event.FinalUpdateID
+	return true
+}
+
+func (c *Client) resyncDepth(symbol string, state *depthSync, event BookDepthResponse, reason string) {
+	metrics.OrderbookResyncs.WithLabelValues("binance").Inc()
+	c.logger.Warn("resyncing orderbook", "symbol", symbol, "reason", reason,
+		"expected", state.lastUpdateID, "first", event.FirstUpdateID, "previous", event.FinalUpdateIDInLastStream)
+	state.synced = false
+	c.applyDepth(symbol, state, event)
+}
+
+// resetDepth forces every symbol to be synced again from a new snapshot,
+// since events may have been missed while the stream was down.
+func (c *Client) resetDepth() {
+	c.depthMu.Lock()
+	defer c.depthMu.Unlock()
+	for _, state := range c.depth {
+		state.synced = false
+	}
+}
+
+// forgetDepth drops the sync state and book of an unsubscribed symbol.
+func (c *Client) forgetDepth(symbol string) {
+	c.depthMu.Lock()
+	defer c.depthMu.Unlock()
+	delete(c.depth, symbol)
+	c.orderbook.Delete(symbol)
+}
+
+// syncDepth loads snapshots of symbol until the buffered events line up
+// with one of them.
+func (c *Client) syncDepth(symbol string, state *depthSync) {
+	defer c.wg.Done()
+	delay := snapshotRetryDelay
+	for {
+		snapshot, err := c.fetchDepthSnapshot(c.ctx, symbol)
+		if err != nil {
+			if c.ctx.Err() != nil {
+				return
+			}
+			c.logger.Error("failed to fetch depth snapshot", "symbol", symbol, "error", err, "next_retry", delay)
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, maxSnapshotRetryDelay)
+			continue
+		}
+
+		c.depthMu.Lock()
+		if c.depth[symbol] != state {
+			// unsubscribed meanwhile
+			c.depthMu.Unlock()
+			return
+		}
+		c.orderbook.Reset(symbol, snapshot)
+		buffered := state.buffer
+		state.buffer = nil
+		state.synced = true
+		state.first = true
+		state.lastUpdateID = snapshot.LastUpdateID
+		for _, event := range buffered {
+			c.applyDepth(symbol, state, event)
+		}
+		synced, lastUpdateID := state.synced, state.lastUpdateID
+		if synced {
+			state.fetching = false
+		}
+		c.depthMu.Unlock()
+
+		if synced {
+			c.logger.Info("synced orderbook", "symbol", symbol, "last_update_id", lastUpdateID)
+			c.notifyOrderbook(symbol)
+			return
+		}
+	}
+}
+
+func (c *Client) fetchDepthSnapshot(ctx context.Context, symbol string) (DepthSnapshot, error) {
+	query := url.Values{
+		"symbol": {symbol},
+		"limit":  {fmt.Sprint(depthSnapshotLimit)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.options.RestURL+"/fapi/v1/depth?"+query.Encode(), nil)
+	if err != nil {
+		return DepthSnapshot{}, err
+	}
+	res, err := c.options.HTTPClient.Do(req)
+	if err != nil {
+		return DepthSnapshot{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return DepthSnapshot{}, fmt.Errorf("depth snapshot of %s: %s", symbol, res.Status)
+	}
+	var snapshot DepthSnapshot
+	if err := json.NewDecoder(res.Body).Decode(&snapshot); err != nil {
+		return DepthSnapshot{}, err
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,73 @@
+package binance
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func waitOrderbook(t *testing.T, updates <-chan Orderbook, bids, asks [][]string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	var last Orderbook
+	for {
+		select {
+		case last = <-updates:
+			if reflect.DeepEqual(levelStrings(last.Bids), bids) && reflect.DeepEqual(levelStrings(last.Asks), asks) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("orderbook = %v/%v, want %v/%v", levelStrings(last.Bids), levelStrings(last.Asks), bids, asks)
+		}
+	}
+}
+
+func TestDepthSync(t *testing.T) {
+	standIn := newStandIn(t)
+	updates := make(chan Orderbook, 64)
+	client := standIn.client(t, WithOnOrderbookUpdate(func(orderbook Orderbook) { updates <- orderbook }))
+	defer standIn.close(client)
+
+	// buffered while the snapshot is fetched: the first is older than the
+	// snapshot and dropped, the second straddles it
+	standIn.send(90, 100, 89, `[["100","5"]]`, `[]`)
+	standIn.send(101, 110, 100, `[["99","0"]]`, `[["101","2"]]`)
+	standIn.snapshots <- DepthSnapshot{
+		LastUpdateID: 105,
+		Bids:         [][]string{{"100", "1"}, {"99", "2"}},
+		Asks:         [][]string{{"101", "1"}, {"102", "3"}},
+	}
+	standIn.send(111, 120, 110, `[["98","4"]]`, `[]`)
+	waitOrderbook(t, updates,
+		[][]string{{"100", "1"}, {"98", "4"}},
+		[][]string{{"101", "2"}, {"102", "3"}},
+	)
+
+	// pu 125 does not continue u 120, so the book is loaded again
+	standIn.send(126, 135, 125, `[["97","1"]]`, `[]`)
+	standIn.snapshots <- DepthSnapshot{
+		LastUpdateID: 130,
+		Bids:         [][]string{{"100", "7"}},
+		Asks:         [][]string{{"101", "1"}},
+	}
+	waitOrderbook(t, updates,
+		[][]string{{"100", "7"}, {"97", "1"}},
+		[][]string{{"101", "1"}},
+	)
+	if got := standIn.requests.Load(); got != 2 {
+		t.Errorf("snapshot requests = %d, want 2", got)
+	}
+}
+
+func TestApplyDepthSnapshotTooOld(t *testing.T) {
+	client := &Client{orderbook: NewOrderbookMap(), logger: slog.Default()}
+	state := &depthSync{synced: true, first: true, fetching: true, lastUpdateID: 100}
+	event := BookDepthResponse{Symbol: "ETHUSDC", FirstUpdateID: 120, FinalUpdateID: 130, FinalUpdateIDInLastStream: 110}
+	if client.applyDepth("ETHUSDC", state, event) {
+		t.Fatal("applied an event newer than the snapshot")
+	}
+	if state.synced || len(state.buffer) != 1 {
+		t.Fatalf("synced = %v, buffered = %d, want a resync with the event buffered", state.synced, len(state.buffer))
+	}
+}
@@ -0,0 +1,78 @@
+package binance
+
+import (
+	"net/http"
+	"time"
+)
+
+type ClientOptions struct {
+	OnOrderbookUpdate func(orderbook Orderbook)
+	OnAggTrade        func(trade AggTrade)
+	OnMarkPrice       func(markPrice MarkPrice)
+	OnKline           func(kline Kline)
+	WebsocketURL      string
+	RestURL           string
+	HTTPClient        *http.Client
+	AckTimeout        time.Duration
+}
+
+type ClientOption func(*ClientOptions)
+
+// WithOnOrderbookUpdate registers a callback invoked with a copy of the
+// orderbook after every applied depth update.
+func WithOnOrderbookUpdate(onOrderbookUpdate func(orderbook Orderbook)) ClientOption {
+	return func(o *ClientOptions) {
+		o.OnOrderbookUpdate = onOrderbookUpdate
+	}
+}
+
+// WithOnAggTrade registers a callback invoked with every aggregated trade.
+func WithOnAggTrade(onAggTrade func(trade AggTrade)) ClientOption {
+	return func(o *ClientOptions) {
+		o.OnAggTrade = onAggTrade
+	}
+}
+
+// WithOnMarkPrice registers a callback invoked with every mark price update.
+func WithOnMarkPrice(onMarkPrice func(markPrice MarkPrice)) ClientOption {
+	return func(o *ClientOptions) {
+		o.OnMarkPrice = onMarkPrice
+	}
+}
+
+// WithOnKline registers a callback invoked with every kline update, open
+// or closed.
+func WithOnKline(onKline func(kline Kline)) ClientOption {
+	return func(o *ClientOptions) {
+		o.OnKline = onKline
+	}
+}
+
+// WithWebsocketURL overrides the combined stream endpoint.
+func WithWebsocketURL(url string) ClientOption {
+	return func(o *ClientOptions) {
+		o.WebsocketURL = url
+	}
+}
+
+// WithRestURL overrides the REST base URL depth snapshots are fetched from.
+func WithRestURL(url string) ClientOption {
+	return func(o *ClientOptions) {
+		o.RestURL = url
+	}
+}
+
+// WithHTTPClient sets the client used for depth snapshots.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(o *ClientOptions) {
+		o.HTTPClient = client
+	}
+}
+
+// WithAckTimeout sets how long a SUBSCRIBE or UNSUBSCRIBE request waits for
+// its acknowledgement.
+func WithAckTimeout(timeout time.Duration) ClientOption {
+	return func(o *ClientOptions) {
+		o.AckTimeout = timeout
+	}
+}
@@ -2,150 +2,183 @@ package binance
 
 import (
 	"encoding/json"
-	"math"
-	"sort"
-	"strconv"
 	"sync"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/pkg/market"
 )
 
+// Orderbook is the local book of a symbol. Levels are keyed by exact
+// decimal prices and kept sorted, bids and asks best first.
 type Orderbook struct {
-	Symbol string
+	Symbol     string
 	IndexPrice float64
-	Bids map[float64]float64
-	Asks map[float64]float64
+	UpdatedAt  time.Time
+	market.Book
 }
+
 type OrderbookResponse struct {
-	Symbol string `json:"s"`
-	Bids [][]string `json:"b"`
-	Asks [][]string `json:"a"`
+	Symbol string     `json:"s"`
+	Bids   [][]string `json:"b"`
+	Asks   [][]string `json:"a"`
 }
 
+// MarshalJSON writes bids by descending and asks by ascending price.
 func (o Orderbook) MarshalJSON() ([]byte, error) {
-	bids := make([][]string, 0, len(o.Bids))
-	for price, quantity := range o.Bids {
-		bids = append(bids, []string{strconv.FormatFloat(price, 'f', -1, 64), strconv.FormatFloat(quantity, 'f', -1, 64)})
-	}
-	asks := make([][]string, 0, len(o.Asks))
-	for price, quantity := range o.Asks {
-		asks = append(asks, []string{strconv.FormatFloat(price, 'f', -1, 64), strconv.FormatFloat(quantity, 'f', -1, 64)})
-	}
-	sort.Slice(bids, func(i, j int) bool {
-		return bids[i][0] < bids[j][0]
-	})
-	sort.Slice(asks, func(i, j int) bool {
-		return asks[i][0] < asks[j][0]
-	})
 	return json.Marshal(OrderbookResponse{
 		Symbol: o.Symbol,
-		Bids: bids,
-		Asks: asks,
+		Bids:   levelStrings(o.Bids),
+		Asks:   levelStrings(o.Asks),
 	})
 }
 
+func levelStrings(levels *market.Levels) [][]string {
+	if levels == nil {
+		return [][]string{}
+	}
+	result := make([][]string, 0, levels.Len())
+	levels.Each(func(level market.Level) bool {
+		result = append(result, []string{level.Price.String(), level.Quantity.String()})
+		return true
+	})
+	return result
+}
+
 func (o Orderbook) DeepCopy() Orderbook {
 	copied := Orderbook{
 		Symbol:     o.Symbol,
 		IndexPrice: o.IndexPrice,
-		Bids:       make(map[float64]float64, len(o.Bids)),
-		Asks:       make(map[float64]float64, len(o.Asks)),
+		UpdatedAt:  o.UpdatedAt,
 	}
-	for price, quantity := range o.Bids {
-		copied.Bids[price] = quantity
-	}
-	for price, quantity := range o.Asks {
-		copied.Asks[price] = quantity
+	if o.Bids != nil {
+		copied.Book = o.Book.Clone()
 	}
 	return copied
 }
 
-var mu sync.RWMutex
-type OrderbookMap map[string]Orderbook
+// OrderbookMap holds the books of one client by symbol.
+type OrderbookMap struct {
+	mu    sync.RWMutex
+	books map[string]Orderbook
+}
 
-func NewOrderbookMap() OrderbookMap {
-	return make(OrderbookMap)
+func NewOrderbookMap() *OrderbookMap {
+	return &OrderbookMap{books: make(map[string]Orderbook)}
 }
 
-func (m OrderbookMap) Update(response BookDepthResponse) {
-	mu.Lock()
-	defer mu.Unlock()
-	orderbook, ok := m[response.Symbol]
+// Get returns a copy of the book of symbol.
+func (m *OrderbookMap) Get(symbol string) (Orderbook, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	orderbook, ok := m.books[symbol]
+	if !ok {
+		return Orderbook{}, false
+	}
+	return orderbook.DeepCopy(), true
+}
+
+// IndexPrice returns the index price of symbol and when it was updated
+// without copying the book.
+func (m *OrderbookMap) IndexPrice(symbol string) (float64, time.Time, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	orderbook, ok := m.books[symbol]
+	return orderbook.IndexPrice, orderbook.UpdatedAt, ok
+}
+
+func (m *OrderbookMap) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.books)
+}
+
+func (m *OrderbookMap) Update(response BookDepthResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	orderbook, ok := m.books[response.Symbol]
 	if !ok {
 		orderbook = Orderbook{
 			Symbol: response.Symbol,
-			Bids: make(map[float64]float64),
-			Asks: make(map[float64]float64),
+			Book:   market.NewBook(),
 		}
 	}
-	for _, bid := range response.Bids {
-		price, err := strconv.ParseFloat(bid[0], 64)
-		if err != nil {
-			continue
-		}
-		quantity, err := strconv.ParseFloat(bid[1], 64)
-		if err != nil {
-			continue
-		}
-		if quantity == 0 {
-			delete(orderbook.Bids, price)
-			continue
-		}
-		orderbook.Bids[price] = quantity
+	applyLevels(orderbook.Bids, response.Bids)
+	applyLevels(orderbook.Asks, response.Asks)
+	orderbook.IndexPrice = indexPrice(orderbook.Book)
+	orderbook.UpdatedAt = response.EventTime
+	m.books[response.Symbol] = orderbook
+}
+
+// Reset replaces the book of symbol with a REST depth snapshot.
+func (m *OrderbookMap) Reset(symbol string, snapshot DepthSnapshot) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	orderbook := Orderbook{
+		Symbol: symbol,
+		Book:   market.NewBook(),
 	}
-	for _, ask := range response.Asks {
-		price, err := strconv.ParseFloat(ask[0], 64)
-		if err != nil {
+	applyLevels(orderbook.Bids, snapshot.Bids)
+	applyLevels(orderbook.Asks, snapshot.Asks)
+	orderbook.IndexPrice = indexPrice(orderbook.Book)
+	orderbook.UpdatedAt = time.Now()
+	m.books[symbol] = orderbook
+}
+
+// Delete drops the book of symbol.
+func (m *OrderbookMap) Delete(symbol string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.books, symbol)
+}
+
+// applyLevels sets the [price, quantity] levels on one side of a book,
+// removing those with a zero quantity.
+func applyLevels(side *market.Levels, levels [][]string) {
+	for _, level := range levels {
+		if len(level) < 2 {
 			continue
 		}
-		quantity, err := strconv.ParseFloat(ask[1], 64)
+		price, err := market.ParseDecimal(level[0])
 		if err != nil {
 			continue
 		}
-		if quantity == 0 {
-			delete(orderbook.Asks, price)
+		quantity, err := market.ParseDecimal(level[1])
+		if err != nil {
 			continue
 		}
-		orderbook.Asks[price] = quantity
+		side.Set(price, quantity)
 	}
-	orderbook.IndexPrice = (orderbook.MaxBid() + orderbook.MinAsk()) / 2
-	m[response.Symbol] = orderbook
 }
 
-func (o *Orderbook) MinBid() float64 {
-	minBid := math.MaxFloat64
-	for price := range o.Bids {
-		if price < minBid {
-			minBid = price
-		}
+// indexPrice is the mid price, or 0 while a side of the book is empty.
+func indexPrice(book market.Book) float64 {
+	mid, ok := book.Mid()
+	if !ok {
+		return 0
 	}
-	return minBid
+	return mid.Float64()
+}
+
+// MinBid returns the lowest bid price, or 0 if there are no bids.
+func (o *Orderbook) MinBid() float64 {
+	level, _ := o.Bids.Worst()
+	return level.Price.Float64()
 }
 
+// MaxBid returns the best bid price, or 0 if there are no bids.
 func (o *Orderbook) MaxBid() float64 {
-	maxBid := math.SmallestNonzeroFloat64
-	for price := range o.Bids {
-		if price > maxBid {
-			maxBid = price
-		}
-	}
-	return maxBid
+	level, _ := o.Bids.Best()
+	return level.Price.Float64()
 }
 
+// MinAsk returns the best ask price, or 0 if there are no asks.
 func (o *Orderbook) MinAsk() float64 {
-	minAsk := math.MaxFloat64
-	for price := range o.Asks {
-		if price < minAsk {
-			minAsk = price
-		}
-	}
-	return minAsk
+	level, _ := o.Asks.Best()
+	return level.Price.Float64()
 }
 
+// MaxAsk returns the highest ask price, or 0 if there are no asks.
 func (o *Orderbook) MaxAsk() float64 {
-	maxAsk := math.SmallestNonzeroFloat64
-	for price := range o.Asks {
-		if price > maxAsk {
-			maxAsk = price
-		}
-	}
-	return maxAsk
-}
\ No newline at end of file
+	level, _ := o.Asks.Worst()
+	return level.Price.Float64()
+}
@@ -0,0 +1,25 @@
+package binance
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOrderbookMarshalJSON(t *testing.T) {
+	orderbook := NewOrderbookMap()
+	orderbook.Reset("ETHUSDC", DepthSnapshot{
+		Bids: [][]string{{"99", "1"}, {"100", "2"}, {"9.5", "3"}},
+		Asks: [][]string{{"1000", "1"}, {"101", "2"}},
+	})
+	raw, err := json.Marshal(orderbook.books["ETHUSDC"])
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	want := `{"s":"ETHUSDC","b":[["100","2"],["99","1"],["9.5","3"]],"a":[["101","2"],["1000","1"]]}`
+	if string(raw) != want {
+		t.Errorf("got %s, want %s", raw, want)
+	}
+	if got := orderbook.books["ETHUSDC"].IndexPrice; got != 100.5 {
+		t.Errorf("IndexPrice = %v, want 100.5", got)
+	}
+}
@@ -0,0 +1,97 @@
+package binance
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/pkg/market"
+)
+
+// ProviderName is the venue name the client registers under.
+const ProviderName = "binance"
+
+var _ market.Provider = (*Client)(nil)
+
+func (c *Client) Name() string {
+	return ProviderName
+}
+
+// SubscribeSymbols subscribes to the depth, trade and mark price streams
+// of every symbol.
+func (c *Client) SubscribeSymbols(symbols ...string) error {
+	params := make([]string, 0, 3*len(symbols))
+	for _, symbol := range symbols {
+		lower := strings.ToLower(symbol)
+		params = append(params,
+			BookDepthStreamsParam(lower, "500ms"),
+			AggTradeStreamsParam(lower),
+			MarkPriceStreamsParam(lower, "1s"),
+		)
+	}
+	if err := c.Subscribe(params...); err != nil {
+		return err
+	}
+	c.providerMu.Lock()
+	for _, symbol := range symbols {
+		c.symbols[strings.ToUpper(symbol)] = true
+	}
+	c.providerMu.Unlock()
+	return nil
+}
+
+func (c *Client) Orderbook(symbol string) (market.Book, bool) {
+	orderbook, ok := c.GetOrderbook(strings.ToUpper(symbol))
+	if !ok {
+		return market.Book{}, false
+	}
+	return orderbook.Book, true
+}
+
+// Price returns the streamed mark price with its funding rate, falling
+// back to the mid price of the local book until the first update.
+func (c *Client) Price(symbol string) (market.Price, bool) {
+	symbol = strings.ToUpper(symbol)
+	if markPrice, ok := c.GetMarkPrice(symbol); ok {
+		return market.Price{
+			Symbol:      symbol,
+			Mark:        markPrice.MarkPrice,
+			Index:       markPrice.IndexPrice,
+			FundingRate: markPrice.FundingRate,
+			Time:        markPrice.Time,
+		}, true
+	}
+	indexPrice, updatedAt, ok := c.orderbook.IndexPrice(symbol)
+	if !ok || indexPrice == 0 {
+		return market.Price{}, false
+	}
+	return market.Price{
+		Symbol: symbol,
+		Mark:   indexPrice,
+		Index:  indexPrice,
+		Time:   updatedAt,
+	}, true
+}
+
+func (c *Client) OnTrade(handler func(market.Trade)) {
+	c.providerMu.Lock()
+	defer c.providerMu.Unlock()
+	c.tradeHandlers = append(c.tradeHandlers, handler)
+}
+
+func (c *Client) Health() market.Health {
+	health := market.Health{Connected: c.connected.Load()}
+	if last := c.lastMessage.Load(); last > 0 {
+		health.LastMessage = time.Unix(0, last)
+	}
+	c.providerMu.RLock()
+	health.Symbols = len(c.symbols)
+	c.providerMu.RUnlock()
+	c.depthMu.Lock()
+	for _, state := range c.depth {
+		if state.synced {
+			health.Synced++
+		}
+	}
+	c.depthMu.Unlock()
+	return health
+}
@@ -0,0 +1,126 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// standIn serves the depth snapshot endpoint and a combined stream that
+// sends whatever the test pushes to events and replies to requests.
+type standIn struct {
+	server    *httptest.Server
+	snapshots chan DepthSnapshot
+	events    chan string
+	requests  atomic.Int32
+	done      chan struct{}
+
+	mu       sync.Mutex
+	received []SubscribeParams
+	// reply returns the reply to a request, or "" to leave it unanswered;
+	// by default every request succeeds.
+	reply func(request SubscribeParams) string
+}
+
+func newStandIn(t *testing.T) *standIn {
+	s := &standIn{
+		snapshots: make(chan DepthSnapshot),
+		events:    make(chan string, 16),
+		done:      make(chan struct{}),
+		reply: func(request SubscribeParams) string {
+			return fmt.Sprintf(`{"result":null,"id":%d}`, request.ID)
+		},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /fapi/v1/depth", func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		select {
+		case snapshot := <-s.snapshots:
+			json.NewEncoder(w).Encode(snapshot)
+		case <-r.Context().Done():
+		}
+	})
+	mux.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		replies := make(chan string, 16)
+		go func() {
+			for {
+				_, message, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+				var request SubscribeParams
+				if err := json.Unmarshal(message, &request); err != nil {
+					continue
+				}
+				s.mu.Lock()
+				s.received = append(s.received, request)
+				reply := s.reply(request)
+				s.mu.Unlock()
+				if reply != "" {
+					replies <- reply
+				}
+			}
+		}()
+		for {
+			select {
+			case event := <-s.events:
+				conn.WriteMessage(websocket.TextMessage, []byte(event))
+			case reply := <-replies:
+				conn.WriteMessage(websocket.TextMessage, []byte(reply))
+			case <-s.done:
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+		}
+	})
+	s.server = httptest.NewServer(mux)
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// client returns a started client connected to the stand-in.
+func (s *standIn) client(t *testing.T, options ...ClientOption) *Client {
+	t.Helper()
+	options = append([]ClientOption{
+		WithWebsocketURL("ws" + strings.TrimPrefix(s.server.URL, "http") + "/stream"),
+		WithRestURL(s.server.URL),
+	}, options...)
+	client, err := NewClient(t.Context(), options...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if err := client.Start(); err != nil {
+		t.Fatalf("failed to start client: %v", err)
+	}
+	return client
+}
+
+func (s *standIn) close(client *Client) {
+	close(s.done)
+	client.Stop()
+}
+
+func (s *standIn) requestsReceived() []SubscribeParams {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SubscribeParams(nil), s.received...)
+}
+
+func (s *standIn) send(first, final, previous int64, bids, asks string) {
+	s.events <- fmt.Sprintf(
+		`{"stream":"ethusdc@depth@500ms","data":{"e":"depthUpdate","E":1,"T":1,"s":"ETHUSDC","U":%d,"u":%d,"pu":%d,"b":%s,"a":%s}}`,
+		first, final, previous, bids, asks,
+	)
+}
@@ -0,0 +1,300 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/pkg/market"
+)
+
+// Stream name suffixes, e.g. btcusdt@aggTrade or btcusdt@kline_1m.
+const (
+	depthStream     = "@depth"
+	aggTradeStream  = "@aggTrade"
+	markPriceStream = "@markPrice"
+	klineStream     = "@kline_"
+)
+
+func AggTradeStreamsParam(symbol string) string {
+	return symbol + aggTradeStream // btcusdt@aggTrade
+}
+
+// MarkPriceStreamsParam returns the mark price stream, updated every 1s or
+// every 3s when interval is empty.
+func MarkPriceStreamsParam(symbol string, interval string) string {
+	if interval == "" {
+		return symbol + markPriceStream // btcusdt@markPrice
+	}
+	return fmt.Sprintf("%s%s@%s", symbol, markPriceStream, interval) // btcusdt@markPrice@1s
+}
+
+func KlineStreamsParam(symbol string, interval string) string {
+	return symbol + klineStream + interval // btcusdt@kline_1m
+}
+
+// AggTrade is a trade aggregated over the fills of one taker order at one
+// price.
+type AggTrade struct {
+	Symbol       string
+	ID           int64
+	Price        market.Decimal
+	Quantity     market.Decimal
+	FirstTradeID int64
+	LastTradeID  int64
+	Time         time.Time
+	BuyerMaker   bool
+}
+
+type RawAggTrade struct {
+	Event        string `json:"e"` // Event type
+	EventTime    int64  `json:"E"` // Event time
+	Symbol       string `json:"s"` // Symbol
+	ID           int64  `json:"a"` // Aggregate trade ID
+	Price        string `json:"p"` // Price
+	Quantity     string `json:"q"` // Quantity
+	FirstTradeID int64  `json:"f"` // First trade ID
+	LastTradeID  int64  `json:"l"` // Last trade ID
+	TradeTime    int64  `json:"T"` // Trade time
+	BuyerMaker   bool   `json:"m"` // Is the buyer the market maker?
+}
+
+func (r RawAggTrade) Parse() (AggTrade, error) {
+	price, err := market.ParseDecimal(r.Price)
+	if err != nil {
+		return AggTrade{}, err
+	}
+	quantity, err := market.ParseDecimal(r.Quantity)
+	if err != nil {
+		return AggTrade{}, err
+	}
+	return AggTrade{
+		Symbol:       r.Symbol,
+		ID:           r.ID,
+		Price:        price,
+		Quantity:     quantity,
+		FirstTradeID: r.FirstTradeID,
+		LastTradeID:  r.LastTradeID,
+		Time:         time.UnixMilli(r.TradeTime),
+		BuyerMaker:   r.BuyerMaker,
+	}, nil
+}
+
+// MarkPrice is the mark price and funding of a perpetual.
+type MarkPrice struct {
+	Symbol               string
+	MarkPrice            float64
+	IndexPrice           float64
+	EstimatedSettlePrice float64
+	FundingRate          float64
+	NextFundingTime      time.Time
+	Time                 time.Time
+}
+
+type RawMarkPrice struct {
+	Event                string `json:"e"` // Event type
+	EventTime            int64  `json:"E"` // Event time
+	Symbol               string `json:"s"` // Symbol
+	MarkPrice            string `json:"p"` // Mark price
+	IndexPrice           string `json:"i"` // Index price
+	EstimatedSettlePrice string `json:"P"` // Estimated settle price
+	FundingRate          string `json:"r"` // Funding rate
+	NextFundingTime      int64  `json:"T"` // Next funding time
+}
+
+func (r RawMarkPrice) Parse() (MarkPrice, error) {
+	values, err := parseFloats(r.MarkPrice, r.IndexPrice, r.EstimatedSettlePrice, r.FundingRate)
+	if err != nil {
+		return MarkPrice{}, err
+	}
+	return MarkPrice{
+		Symbol:               r.Symbol,
+		MarkPrice:            values[0],
+		IndexPrice:           values[1],
+		EstimatedSettlePrice: values[2],
+		FundingRate:          values[3],
+		NextFundingTime:      time.UnixMilli(r.NextFundingTime),
+		Time:                 time.UnixMilli(r.EventTime),
+	}, nil
+}
+
+// Kline is a candle, sent on every trade until it is closed.
+type Kline struct {
+	Symbol      string
+	Interval    string
+	OpenTime    time.Time
+	CloseTime   time.Time
+	Open        float64
+	High        float64
+	Low         float64
+	Close       float64
+	Volume      float64
+	QuoteVolume float64
+	Trades      int64
+	Closed      bool
+}
+
+type RawKline struct {
+	Event     string `json:"e"` // Event type
+	EventTime int64  `json:"E"` // Event time
+	Symbol    string `json:"s"` // Symbol
+	Kline     struct {
+		OpenTime     int64  `json:"t"` // Kline start time
+		CloseTime    int64  `json:"T"` // Kline close time
+		Interval     string `json:"i"` // Interval
+		FirstTradeID int64  `json:"f"` // First trade ID
+		LastTradeID  int64  `json:"L"` // Last trade ID
+		Open         string `json:"o"` // Open price
+		Close        string `json:"c"` // Close price
+		High         string `json:"h"` // High price
+		Low          string `json:"l"` // Low price
+		Volume       string `json:"v"` // Base asset volume
+		Trades       int64  `json:"n"` // Number of trades
+		Closed       bool   `json:"x"` // Is this kline closed?
+		QuoteVolume  string `json:"q"` // Quote asset volume
+		// declared so that encoding/json, which matches keys case
+		// insensitively, does not decode them into v and q
+		TakerBuyVolume      string `json:"V"` // Taker buy base asset volume
+		TakerBuyQuoteVolume string `json:"Q"` // Taker buy quote asset volume
+	} `json:"k"`
+}
+
+func (r RawKline) Parse() (Kline, error) {
+	k := r.Kline
+	values, err := parseFloats(k.Open, k.High, k.Low, k.Close, k.Volume, k.QuoteVolume)
+	if err != nil {
+		return Kline{}, err
+	}
+	return Kline{
+		Symbol:      r.Symbol,
+		Interval:    k.Interval,
+		OpenTime:    time.UnixMilli(k.OpenTime),
+		CloseTime:   time.UnixMilli(k.CloseTime),
+		Open:        values[0],
+		High:        values[1],
+		Low:         values[2],
+		Close:       values[3],
+		Volume:      values[4],
+		QuoteVolume: values[5],
+		Trades:      k.Trades,
+		Closed:      k.Closed,
+	}, nil
+}
+
+func parseFloats(values ...string) ([]float64, error) {
+	parsed := make([]float64, len(values))
+	for i, value := range values {
+		if value == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = v
+	}
+	return parsed, nil
+}
+
+// route decodes the data of a combined stream message by its stream name
+// and hands it to the matching handler.
+func (c *Client) route(stream string, data json.RawMessage) error {
+	switch {
+	case strings.Contains(stream, depthStream):
+		var raw RawBookDepthResponse
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		if c.onDepth(raw.Response()) {
+			c.notifyOrderbook(raw.Symbol)
+		}
+	case strings.HasSuffix(stream, aggTradeStream):
+		var raw RawAggTrade
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		trade, err := raw.Parse()
+		if err != nil {
+			return err
+		}
+		c.onAggTrade(trade)
+	case strings.Contains(stream, markPriceStream):
+		var raw RawMarkPrice
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		markPrice, err := raw.Parse()
+		if err != nil {
+			return err
+		}
+		c.onMarkPrice(markPrice)
+	case strings.Contains(stream, klineStream):
+		var raw RawKline
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		kline, err := raw.Parse()
+		if err != nil {
+			return err
+		}
+		if c.options.OnKline != nil {
+			c.options.OnKline(kline)
+		}
+	default:
+		c.logger.Debug("ignored message of unknown stream", "stream", stream)
+	}
+	return nil
+}
+
+func (c *Client) onAggTrade(trade AggTrade) {
+	if c.options.OnAggTrade != nil {
+		c.options.OnAggTrade(trade)
+	}
+	c.providerMu.RLock()
+	handlers := c.tradeHandlers
+	c.providerMu.RUnlock()
+	for _, handler := range handlers {
+		handler(market.Trade{
+			Symbol:     trade.Symbol,
+			Price:      trade.Price,
+			Quantity:   trade.Quantity,
+			BuyerMaker: trade.BuyerMaker,
+			Time:       trade.Time,
+		})
+	}
+}
+
+func (c *Client) onMarkPrice(markPrice MarkPrice) {
+	c.providerMu.Lock()
+	c.markPrices[markPrice.Symbol] = markPrice
+	c.providerMu.Unlock()
+	if c.options.OnMarkPrice != nil {
+		c.options.OnMarkPrice(markPrice)
+	}
+}
+
+// GetMarkPrice returns the latest mark price of symbol.
+func (c *Client) GetMarkPrice(symbol string) (MarkPrice, bool) {
+	c.providerMu.RLock()
+	defer c.providerMu.RUnlock()
+	markPrice, ok := c.markPrices[strings.ToUpper(symbol)]
+	return markPrice, ok
+}
+
+// SubscribeTrades subscribes to the aggregated trades of symbol.
+func (c *Client) SubscribeTrades(symbol string) error {
+	return c.Subscribe(AggTradeStreamsParam(strings.ToLower(symbol)))
+}
+
+// SubscribeMarkPrice subscribes to the mark price and funding rate of
+// symbol, updated every second.
+func (c *Client) SubscribeMarkPrice(symbol string) error {
+	return c.Subscribe(MarkPriceStreamsParam(strings.ToLower(symbol), "1s"))
+}
+
+// SubscribeKlines subscribes to the candles of symbol, e.g. interval 1m.
+func (c *Client) SubscribeKlines(symbol string, interval string) error {
+	return c.Subscribe(KlineStreamsParam(strings.ToLower(symbol), interval))
+}
@@ -0,0 +1,73 @@
+package binance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cresendoo/decidash-backend/pkg/market"
+)
+
+func TestStreamsParam(t *testing.T) {
+	for got, want := range map[string]string{
+		AggTradeStreamsParam("btcusdt"):        "btcusdt@aggTrade",
+		MarkPriceStreamsParam("btcusdt", "1s"): "btcusdt@markPrice@1s",
+		MarkPriceStreamsParam("btcusdt", ""):   "btcusdt@markPrice",
+		KlineStreamsParam("btcusdt", "1m"):     "btcusdt@kline_1m",
+	} {
+		if got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
+
+func TestRoute(t *testing.T) {
+	var (
+		aggTrades  []AggTrade
+		markPrices []MarkPrice
+		klines     []Kline
+		trades     []market.Trade
+	)
+	client, err := NewClient(
+		context.Background(),
+		WithOnAggTrade(func(trade AggTrade) { aggTrades = append(aggTrades, trade) }),
+		WithOnMarkPrice(func(markPrice MarkPrice) { markPrices = append(markPrices, markPrice) }),
+		WithOnKline(func(kline Kline) { klines = append(klines, kline) }),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	client.OnTrade(func(trade market.Trade) { trades = append(trades, trade) })
+
+	for _, message := range []string{
+		`{"result":null,"id":1}`,
+		`{"stream":"btcusdt@aggTrade","data":{"e":"aggTrade","E":1700000000100,"s":"BTCUSDT","a":5933014,"p":"43500.10","q":"0.025","f":100,"l":105,"T":1700000000000,"m":true}}`,
+		`{"stream":"btcusdt@markPrice@1s","data":{"e":"markPriceUpdate","E":1700000001000,"s":"BTCUSDT","p":"43510.5","i":"43505.25","P":"43490.1","r":"0.00010000","T":1700006400000}}`,
+		`{"stream":"btcusdt@kline_1m","data":{"e":"kline","E":1700000002000,"s":"BTCUSDT","k":{"t":1699999980000,"T":1700000039999,"s":"BTCUSDT","i":"1m","f":100,"L":200,"o":"43400","c":"43500.1","h":"43550","l":"43390","v":"12.5","n":100,"x":false,"q":"543210.5","V":"6","Q":"260000","B":"0"}}}`,
+		`{"stream":"btcusdt@bookTicker","data":{"e":"bookTicker","s":"BTCUSDT"}}`,
+	} {
+		client.onMessage([]byte(message))
+	}
+
+	if len(aggTrades) != 1 || aggTrades[0].Price != market.MustDecimal("43500.1") || !aggTrades[0].BuyerMaker ||
+		!aggTrades[0].Time.Equal(time.UnixMilli(1700000000000)) {
+		t.Errorf("aggTrades = %+v", aggTrades)
+	}
+	if len(trades) != 1 || trades[0].Quantity != market.MustDecimal("0.025") {
+		t.Errorf("trades = %+v", trades)
+	}
+	if len(markPrices) != 1 || markPrices[0].FundingRate != 0.0001 || markPrices[0].IndexPrice != 43505.25 {
+		t.Errorf("markPrices = %+v", markPrices)
+	}
+	if len(klines) != 1 || klines[0].Interval != "1m" || klines[0].Close != 43500.1 || klines[0].Volume != 12.5 || klines[0].Closed {
+		t.Errorf("klines = %+v", klines)
+	}
+	if client.orderbook.Len() != 0 {
+		t.Errorf("non-depth messages touched the orderbook: %v", client.orderbook.books)
+	}
+
+	price, ok := client.Price("btcusdt")
+	if !ok || price.Mark != 43510.5 || price.FundingRate != 0.0001 {
+		t.Errorf("Price() = %+v, %v", price, ok)
+	}
+}
@@ -0,0 +1,181 @@
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxStreams is the number of streams one connection may listen to.
+	maxStreams = 1024
+	// maxParamsPerRequest bounds the streams of one SUBSCRIBE or
+	// UNSUBSCRIBE request.
+	maxParamsPerRequest = 200
+	// requestInterval keeps requests well under the limit of 10 incoming
+	// messages per second, which also counts pings and pongs.
+	requestInterval   = 200 * time.Millisecond
+	defaultAckTimeout = 10 * time.Second
+)
+
+var ErrNotStarted = errors.New("binance: client not started")
+
+// ackResponse is the reply to a request, {"result":null,"id":1} on success.
+type ackResponse struct {
+	ID    *int64 `json:"id"`
+	Error *struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"error"`
+}
+
+// Subscribe subscribes to streams that are not subscribed yet, in batches
+// that each wait for Binance to acknowledge them.
+func (c *Client) Subscribe(params ...string) error {
+	c.subMu.Lock()
+	added := make([]string, 0, len(params))
+	for _, param := range params {
+		if !c.subscribed[param] && !slices.Contains(added, param) {
+			added = append(added, param)
+		}
+	}
+	if len(c.subscribed)+len(added) > maxStreams {
+		c.subMu.Unlock()
+		return fmt.Errorf("binance: subscribing to %d streams exceeds the limit of %d", len(c.subscribed)+len(added), maxStreams)
+	}
+	for _, param := range added {
+		c.subscribed[param] = true
+	}
+	c.subMu.Unlock()
+
+	for start := 0; start < len(added); start += maxParamsPerRequest {
+		batch := added[start:min(start+maxParamsPerRequest, len(added))]
+		if err := c.request("SUBSCRIBE", batch); err != nil {
+			c.subMu.Lock()
+			for _, param := range added[start:] {
+				delete(c.subscribed, param)
+			}
+			c.subMu.Unlock()
+			return err
+		}
+	}
+	return nil
+}
+
+// Unsubscribe unsubscribes from streams and forgets the books of depth
+// streams.
+func (c *Client) Unsubscribe(params ...string) error {
+	c.subMu.Lock()
+	removed := make([]string, 0, len(params))
+	for _, param := range params {
+		if c.subscribed[param] {
+			delete(c.subscribed, param)
+			removed = append(removed, param)
+		}
+	}
+	c.subMu.Unlock()
+
+	for _, param := range removed {
+		if symbol, _, ok := strings.Cut(param, depthStream); ok {
+			c.forgetDepth(strings.ToUpper(symbol))
+		}
+	}
+	for start := 0; start < len(removed); start += maxParamsPerRequest {
+		if err := c.request("UNSUBSCRIBE", removed[start:min(start+maxParamsPerRequest, len(removed))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscriptions returns the subscribed streams, sorted.
+func (c *Client) Subscriptions() []string {
+	c.subMu.Lock()
+	defer c.subMu.Unlock()
+	params := make([]string, 0, len(c.subscribed))
+	for param := range c.subscribed {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+	return params
+}
+
+// resubscribe sends every subscription again on a new connection.
+func (c *Client) resubscribe() error {
+	params := c.Subscriptions()
+	for start := 0; start < len(params); start += maxParamsPerRequest {
+		if err := c.request("SUBSCRIBE", params[start:min(start+maxParamsPerRequest, len(params))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// request sends one request and waits for its ack.
+func (c *Client) request(method string, params []string) error {
+	if c.conn == nil {
+		return ErrNotStarted
+	}
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if wait := time.Until(c.lastRequest.Add(requestInterval)); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		}
+	}
+
+	id := c.requestID.Add(1)
+	message, err := json.Marshal(SubscribeParams{Method: method, Params: params, ID: id})
+	if err != nil {
+		return err
+	}
+	ack := make(chan error, 1)
+	c.subMu.Lock()
+	c.pending[id] = ack
+	c.subMu.Unlock()
+	defer func() {
+		c.subMu.Lock()
+		delete(c.pending, id)
+		c.subMu.Unlock()
+	}()
+
+	c.lastRequest = time.Now()
+	if err := c.conn.Send(message); err != nil {
+		return err
+	}
+	select {
+	case err := <-ack:
+		return err
+	case <-time.After(c.options.AckTimeout):
+		return fmt.Errorf("binance: %s request %d not acknowledged within %s", method, id, c.options.AckTimeout)
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	}
+}
+
+// onAck completes the pending request a reply belongs to.
+func (c *Client) onAck(message []byte) {
+	var response ackResponse
+	if err := json.Unmarshal(message, &response); err != nil || response.ID == nil {
+		c.logger.Warn("unexpected message", "body", string(message))
+		return
+	}
+	var err error
+	if response.Error != nil {
+		err = fmt.Errorf("binance: request %d failed: %d %s", *response.ID, response.Error.Code, response.Error.Msg)
+	}
+	c.subMu.Lock()
+	ack, ok := c.pending[*response.ID]
+	c.subMu.Unlock()
+	if ok {
+		ack <- err
+	} else if err != nil {
+		c.logger.Error("late reply to request", "error", err)
+	}
+}
@@ -0,0 +1,99 @@
+package binance
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubscribeBatches(t *testing.T) {
+	standIn := newStandIn(t)
+	client := standIn.client(t)
+	defer standIn.close(client)
+
+	params := make([]string, 0, maxParamsPerRequest+10)
+	for i := range maxParamsPerRequest + 10 {
+		params = append(params, AggTradeStreamsParam(fmt.Sprintf("sym%dusdt", i)))
+	}
+	if err := client.Subscribe(params...); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	// already subscribed streams are not requested again
+	if err := client.Subscribe(params[0], params[1]); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	requests := standIn.requestsReceived()
+	if len(requests) != 2 || len(requests[0].Params) != maxParamsPerRequest || len(requests[1].Params) != 10 {
+		t.Fatalf("requests = %d, want batches of %d and 10", len(requests), maxParamsPerRequest)
+	}
+	if requests[0].ID == requests[1].ID {
+		t.Error("requests share an id")
+	}
+
+	if err := client.Unsubscribe(params[1:]...); err != nil {
+		t.Fatalf("failed to unsubscribe: %v", err)
+	}
+	if got := client.Subscriptions(); !reflect.DeepEqual(got, params[:1]) {
+		t.Errorf("Subscriptions() = %v, want %v", got, params[:1])
+	}
+	requests = standIn.requestsReceived()
+	if last := requests[len(requests)-1]; last.Method != "UNSUBSCRIBE" {
+		t.Errorf("last request = %s, want UNSUBSCRIBE", last.Method)
+	}
+}
+
+func TestSubscribeAck(t *testing.T) {
+	standIn := newStandIn(t)
+	standIn.reply = func(request SubscribeParams) string {
+		switch {
+		case strings.HasPrefix(request.Params[0], "bad"):
+			return fmt.Sprintf(`{"error":{"code":2,"msg":"Invalid request"},"id":%d}`, request.ID)
+		case strings.HasPrefix(request.Params[0], "slow"):
+			return ""
+		}
+		return fmt.Sprintf(`{"result":null,"id":%d}`, request.ID)
+	}
+	client := standIn.client(t, WithAckTimeout(200*time.Millisecond))
+	defer standIn.close(client)
+
+	if err := client.Subscribe("bad@aggTrade"); err == nil || !strings.Contains(err.Error(), "Invalid request") {
+		t.Errorf("Subscribe(bad) = %v, want the error reply", err)
+	}
+	if err := client.Subscribe("slow@aggTrade"); err == nil || !strings.Contains(err.Error(), "not acknowledged") {
+		t.Errorf("Subscribe(slow) = %v, want a timeout", err)
+	}
+	if got := client.Subscriptions(); len(got) != 0 {
+		t.Errorf("failed subscriptions were kept: %v", got)
+	}
+}
+
+func TestIndependentClients(t *testing.T) {
+	first, second := newStandIn(t), newStandIn(t)
+	a, b := first.client(t), second.client(t)
+	defer first.close(a)
+	defer second.close(b)
+
+	if err := a.Subscribe(AggTradeStreamsParam("btcusdt")); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	if got := b.Subscriptions(); len(got) != 0 {
+		t.Errorf("second client shares subscriptions: %v", got)
+	}
+	a.orderbook.Reset("BTCUSDT", DepthSnapshot{Bids: [][]string{{"1", "1"}}})
+	if _, ok := b.GetOrderbook("BTCUSDT"); ok {
+		t.Error("second client shares orderbooks")
+	}
+}
+
+func TestSubscribeNotStarted(t *testing.T) {
+	client, err := NewClient(t.Context())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if err := client.Subscribe("btcusdt@aggTrade"); err != ErrNotStarted {
+		t.Errorf("Subscribe() = %v, want ErrNotStarted", err)
+	}
+}
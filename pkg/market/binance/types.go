@@ -38,15 +38,7 @@ func (r *BookDepthResponse) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &response); err != nil {
 		return err
 	}
-	r.Event = response.Data.Event
-	r.EventTime = time.UnixMilli(response.Data.EventTime)
-	r.TransactionTime = time.UnixMilli(response.Data.TransactionTime)
-	r.Symbol = response.Data.Symbol
-	r.FirstUpdateID = response.Data.FirstUpdateID
-	r.FinalUpdateID = response.Data.FinalUpdateID
-	r.FinalUpdateIDInLastStream = response.Data.FinalUpdateIDInLastStream
-	r.Bids = response.Data.Bids
-	r.Asks = response.Data.Asks
+	*r = response.Data.Response()
 	return nil
 }
 
@@ -60,4 +52,19 @@ type RawBookDepthResponse struct {
 	FinalUpdateIDInLastStream int64 `json:"pu"` // Final update Id in last stream(ie `u` in last stream)
 	Bids [][]string `json:"b"` // Bids to be updated, [price, quantity]
 	Asks [][]string `json:"a"` // Asks to be updated, [price, quantity]
-}
\ No newline at end of file
+}
+
+// Response converts the event as sent on the wire.
+func (r RawBookDepthResponse) Response() BookDepthResponse {
+	return BookDepthResponse{
+		Event:                     r.Event,
+		EventTime:                 time.UnixMilli(r.EventTime),
+		TransactionTime:           time.UnixMilli(r.TransactionTime),
+		Symbol:                    r.Symbol,
+		FirstUpdateID:             r.FirstUpdateID,
+		FinalUpdateID:             r.FinalUpdateID,
+		FinalUpdateIDInLastStream: r.FinalUpdateIDInLastStream,
+		Bids:                      r.Bids,
+		Asks:                      r.Asks,
+	}
+}
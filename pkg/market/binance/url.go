@@ -2,5 +2,5 @@ package binance
 
 const (
 	websocketURL = "wss://fstream.binance.com/swift/stream"
+	restURL      = "https://fapi.binance.com"
 )
-
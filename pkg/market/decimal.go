@@ -0,0 +1,121 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DecimalPlaces is the number of fractional digits a Decimal keeps, enough
+// for every price and quantity step of the supported exchanges.
+const DecimalPlaces = 8
+
+const decimalScale = 100_000_000
+
+// Decimal is a fixed-point number with DecimalPlaces fractional digits.
+// Unlike float64 it represents exchange prices exactly, so it can key an
+// orderbook and compare equal to the level it was parsed from.
+type Decimal int64
+
+// ParseDecimal parses a plain decimal string such as "3521.45" or "-0.001".
+func ParseDecimal(s string) (Decimal, error) {
+	raw := s
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	integer, fraction, _ := strings.Cut(s, ".")
+	fraction = strings.TrimRight(fraction, "0")
+	if integer == "" && fraction == "" && !strings.Contains(s, "0") {
+		return 0, fmt.Errorf("market: invalid decimal %q", raw)
+	}
+	if len(fraction) > DecimalPlaces {
+		return 0, fmt.Errorf("market: decimal %q has more than %d fractional digits", raw, DecimalPlaces)
+	}
+	var units uint64
+	if integer != "" {
+		v, err := strconv.ParseUint(integer, 10, 64)
+		if err != nil || v > math.MaxInt64/decimalScale {
+			return 0, fmt.Errorf("market: invalid decimal %q", raw)
+		}
+		units = v * decimalScale
+	}
+	if fraction != "" {
+		v, err := strconv.ParseUint(fraction, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("market: invalid decimal %q", raw)
+		}
+		for i := len(fraction); i < DecimalPlaces; i++ {
+			v *= 10
+		}
+		units += v
+	}
+	if units > math.MaxInt64 {
+		return 0, fmt.Errorf("market: decimal %q out of range", raw)
+	}
+	if negative {
+		return -Decimal(units), nil
+	}
+	return Decimal(units), nil
+}
+
+// MustDecimal is ParseDecimal for constants; it panics on invalid input.
+func MustDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) String() string {
+	sign := ""
+	units := uint64(d)
+	if d < 0 {
+		sign, units = "-", uint64(-d)
+	}
+	integer, fraction := units/decimalScale, units%decimalScale
+	if fraction == 0 {
+		return sign + strconv.FormatUint(integer, 10)
+	}
+	digits := strings.TrimRight(fmt.Sprintf("%0*d", DecimalPlaces, fraction), "0")
+	return sign + strconv.FormatUint(integer, 10) + "." + digits
+}
+
+func (d Decimal) Float64() float64 {
+	return float64(d) / decimalScale
+}
+
+func (d Decimal) IsZero() bool {
+	return d == 0
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	return d + o
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	return d - o
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
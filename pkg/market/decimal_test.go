@@ -0,0 +1,38 @@
+package market
+
+import "testing"
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Decimal
+		out  string
+	}{
+		{"0", 0, "0"},
+		{"100", 100 * decimalScale, "100"},
+		{"99.5", 9_950_000_000, "99.5"},
+		{"0.00000001", 1, "0.00000001"},
+		{"-3.25000", -325_000_000, "-3.25"},
+		{".5", 50_000_000, "0.5"},
+		{"2.000000000", 2 * decimalScale, "2"},
+	}
+	for _, tt := range tests {
+		got, err := ParseDecimal(tt.in)
+		if err != nil {
+			t.Errorf("ParseDecimal(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDecimal(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+		if got.String() != tt.out {
+			t.Errorf("ParseDecimal(%q).String() = %q, want %q", tt.in, got.String(), tt.out)
+		}
+	}
+
+	for _, in := range []string{"", ".", "-", "abc", "1e5", "1.000000001", "99999999999999"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Errorf("ParseDecimal(%q) succeeded, want error", in)
+		}
+	}
+}
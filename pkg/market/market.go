@@ -1 +1,56 @@
+// Package market defines the market data every venue adapter provides and
+// a registry to compare venues with each other and with Decibel.
 package market
+
+import "time"
+
+// Provider is the market data feed of one venue. Symbols are given in the
+// venue's own notation, e.g. BTCUSDT on Binance.
+type Provider interface {
+	// Name identifies the venue, e.g. "binance".
+	Name() string
+	Start() error
+	Stop() error
+	// SubscribeSymbols starts streaming the orderbook, prices and trades of
+	// symbols. Subscribing to a symbol twice is a no-op.
+	SubscribeSymbols(symbols ...string) error
+	// Orderbook returns a copy of the local book of symbol.
+	Orderbook(symbol string) (Book, bool)
+	// Price returns the latest mark and index price of symbol.
+	Price(symbol string) (Price, bool)
+	// OnTrade registers a handler called with every trade of a subscribed
+	// symbol. Handlers must not block.
+	OnTrade(handler func(Trade))
+	Health() Health
+}
+
+// Price is the reference price of a symbol on a venue.
+type Price struct {
+	Symbol string  `json:"symbol"`
+	Mark   float64 `json:"mark"`
+	Index  float64 `json:"index"`
+	// FundingRate is the rate of the current funding period, 0 on venues
+	// or symbols without funding.
+	FundingRate float64   `json:"funding_rate"`
+	Time        time.Time `json:"time"`
+}
+
+// Trade is one executed trade.
+type Trade struct {
+	Symbol   string  `json:"symbol"`
+	Price    Decimal `json:"price"`
+	Quantity Decimal `json:"quantity"`
+	// BuyerMaker is set when the buyer was the resting order, i.e. the
+	// trade was a sell.
+	BuyerMaker bool      `json:"buyer_maker"`
+	Time       time.Time `json:"time"`
+}
+
+// Health reports the state of a provider's connection.
+type Health struct {
+	Connected   bool      `json:"connected"`
+	LastMessage time.Time `json:"last_message"`
+	Symbols     int       `json:"symbols"`
+	// Synced is the number of symbols whose orderbook is in sync.
+	Synced int `json:"synced"`
+}
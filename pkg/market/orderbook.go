@@ -0,0 +1,259 @@
+package market
+
+import "math/rand/v2"
+
+const (
+	maxSkipLevel = 24
+	// skipP is the chance a node is promoted to the next level.
+	skipP = 0.25
+)
+
+// Level is one price level of an orderbook.
+type Level struct {
+	Price    Decimal `json:"price"`
+	Quantity Decimal `json:"quantity"`
+}
+
+// DepthLevel is a price level with the quantity available at it and every
+// better level.
+type DepthLevel struct {
+	Level
+	Total Decimal `json:"total"`
+}
+
+// Levels is one side of an orderbook kept sorted best price first in a skip
+// list, so updates and lookups take O(log n) and the best level O(1).
+type Levels struct {
+	descending bool
+	head       skipNode
+	level      int
+	len        int
+}
+
+type skipNode struct {
+	Level
+	next []*skipNode
+}
+
+// NewBids returns an empty bid side, ordered by descending price.
+func NewBids() *Levels {
+	return newLevels(true)
+}
+
+// NewAsks returns an empty ask side, ordered by ascending price.
+func NewAsks() *Levels {
+	return newLevels(false)
+}
+
+func newLevels(descending bool) *Levels {
+	return &Levels{
+		descending: descending,
+		head:       skipNode{next: make([]*skipNode, maxSkipLevel)},
+		level:      1,
+	}
+}
+
+// before reports whether price a sorts ahead of price b on this side.
+func (l *Levels) before(a, b Decimal) bool {
+	if l.descending {
+		return a > b
+	}
+	return a < b
+}
+
+// Len returns the number of price levels.
+func (l *Levels) Len() int {
+	return l.len
+}
+
+// Set sets the quantity at price, removing the level when it is zero.
+func (l *Levels) Set(price, quantity Decimal) {
+	var update [maxSkipLevel]*skipNode
+	node := &l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for node.next[i] != nil && l.before(node.next[i].Price, price) {
+			node = node.next[i]
+		}
+		update[i] = node
+	}
+	if next := node.next[0]; next != nil && next.Price == price {
+		if quantity.IsZero() {
+			for i := range next.next {
+				update[i].next[i] = next.next[i]
+			}
+			for l.level > 1 && l.head.next[l.level-1] == nil {
+				l.level--
+			}
+			l.len--
+			return
+		}
+		next.Quantity = quantity
+		return
+	}
+	if quantity.IsZero() {
+		return
+	}
+
+	height := randomHeight()
+	if height > l.level {
+		for i := l.level; i < height; i++ {
+			update[i] = &l.head
+		}
+		l.level = height
+	}
+	inserted := &skipNode{Level: Level{Price: price, Quantity: quantity}, next: make([]*skipNode, height)}
+	for i := range height {
+		inserted.next[i] = update[i].next[i]
+		update[i].next[i] = inserted
+	}
+	l.len++
+}
+
+func randomHeight() int {
+	height := 1
+	for height < maxSkipLevel && rand.Float64() < skipP {
+		height++
+	}
+	return height
+}
+
+// Get returns the quantity at price.
+func (l *Levels) Get(price Decimal) (Decimal, bool) {
+	node := &l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for node.next[i] != nil && l.before(node.next[i].Price, price) {
+			node = node.next[i]
+		}
+	}
+	if next := node.next[0]; next != nil && next.Price == price {
+		return next.Quantity, true
+	}
+	return 0, false
+}
+
+// Best returns the best level: the highest bid or the lowest ask.
+func (l *Levels) Best() (Level, bool) {
+	if first := l.head.next[0]; first != nil {
+		return first.Level, true
+	}
+	return Level{}, false
+}
+
+// Worst returns the level furthest from the top of the book.
+func (l *Levels) Worst() (Level, bool) {
+	node := &l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for node.next[i] != nil {
+			node = node.next[i]
+		}
+	}
+	if node == &l.head {
+		return Level{}, false
+	}
+	return node.Level, true
+}
+
+// Each calls fn with every level, best first, until it returns false.
+func (l *Levels) Each(fn func(Level) bool) {
+	for node := l.head.next[0]; node != nil; node = node.next[0] {
+		if !fn(node.Level) {
+			return
+		}
+	}
+}
+
+// Top returns up to n levels best first; n <= 0 returns all of them.
+func (l *Levels) Top(n int) []Level {
+	if n <= 0 || n > l.len {
+		n = l.len
+	}
+	levels := make([]Level, 0, n)
+	l.Each(func(level Level) bool {
+		levels = append(levels, level)
+		return len(levels) < n
+	})
+	return levels
+}
+
+// Cumulative returns up to n levels best first with the running total of
+// quantity; n <= 0 returns all of them.
+func (l *Levels) Cumulative(n int) []DepthLevel {
+	top := l.Top(n)
+	levels := make([]DepthLevel, len(top))
+	var total Decimal
+	for i, level := range top {
+		total = total.Add(level.Quantity)
+		levels[i] = DepthLevel{Level: level, Total: total}
+	}
+	return levels
+}
+
+// Clone returns an independent copy of the side.
+func (l *Levels) Clone() *Levels {
+	clone := newLevels(l.descending)
+	var tails [maxSkipLevel]*skipNode
+	for i := range tails {
+		tails[i] = &clone.head
+	}
+	for node := l.head.next[0]; node != nil; node = node.next[0] {
+		copied := &skipNode{Level: node.Level, next: make([]*skipNode, len(node.next))}
+		for i := range copied.next {
+			tails[i].next[i] = copied
+			tails[i] = copied
+		}
+	}
+	clone.level = l.level
+	clone.len = l.len
+	return clone
+}
+
+// Book is a two-sided orderbook.
+type Book struct {
+	Bids *Levels
+	Asks *Levels
+}
+
+func NewBook() Book {
+	return Book{Bids: NewBids(), Asks: NewAsks()}
+}
+
+// BestBid returns the highest bid.
+func (b Book) BestBid() (Level, bool) {
+	return b.Bids.Best()
+}
+
+// BestAsk returns the lowest ask.
+func (b Book) BestAsk() (Level, bool) {
+	return b.Asks.Best()
+}
+
+// Spread returns the best ask minus the best bid, if both sides have levels.
+func (b Book) Spread() (Decimal, bool) {
+	bid, okBid := b.Bids.Best()
+	ask, okAsk := b.Asks.Best()
+	if !okBid || !okAsk {
+		return 0, false
+	}
+	return ask.Price.Sub(bid.Price), true
+}
+
+// Mid returns the midpoint of the best bid and ask, if both sides have
+// levels.
+func (b Book) Mid() (Decimal, bool) {
+	bid, okBid := b.Bids.Best()
+	ask, okAsk := b.Asks.Best()
+	if !okBid || !okAsk {
+		return 0, false
+	}
+	return bid.Price + (ask.Price-bid.Price)/2, true
+}
+
+// Depth returns up to n levels of each side with cumulative quantities.
+func (b Book) Depth(n int) (bids, asks []DepthLevel) {
+	return b.Bids.Cumulative(n), b.Asks.Cumulative(n)
+}
+
+// Clone returns an independent copy of the book.
+func (b Book) Clone() Book {
+	return Book{Bids: b.Bids.Clone(), Asks: b.Asks.Clone()}
+}
@@ -0,0 +1,177 @@
+package market
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+func TestLevelsOrdering(t *testing.T) {
+	bids, asks := NewBids(), NewAsks()
+	for _, price := range []string{"99", "100", "9.5", "100.25"} {
+		bids.Set(MustDecimal(price), MustDecimal("1"))
+		asks.Set(MustDecimal(price), MustDecimal("1"))
+	}
+	prices := func(levels []Level) []string {
+		result := make([]string, len(levels))
+		for i, level := range levels {
+			result[i] = level.Price.String()
+		}
+		return result
+	}
+	if got, want := prices(bids.Top(0)), []string{"100.25", "100", "99", "9.5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("bids = %v, want %v", got, want)
+	}
+	if got, want := prices(asks.Top(2)), []string{"9.5", "99"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("asks = %v, want %v", got, want)
+	}
+	if worst, _ := bids.Worst(); worst.Price != MustDecimal("9.5") {
+		t.Errorf("worst bid = %s, want 9.5", worst.Price)
+	}
+}
+
+func TestLevelsSet(t *testing.T) {
+	levels := NewAsks()
+	levels.Set(MustDecimal("101"), MustDecimal("2"))
+	levels.Set(MustDecimal("101"), MustDecimal("3"))
+	levels.Set(MustDecimal("102"), MustDecimal("0"))
+	if levels.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", levels.Len())
+	}
+	if quantity, _ := levels.Get(MustDecimal("101")); quantity != MustDecimal("3") {
+		t.Errorf("quantity = %s, want 3", quantity)
+	}
+	levels.Set(MustDecimal("101"), 0)
+	if _, ok := levels.Best(); ok || levels.Len() != 0 {
+		t.Errorf("level not removed, Len() = %d", levels.Len())
+	}
+}
+
+// TestLevelsRandom checks the skip list against a map under random updates.
+func TestLevelsRandom(t *testing.T) {
+	levels := NewBids()
+	want := make(map[Decimal]Decimal)
+	for range 10_000 {
+		price, quantity := Decimal(rand.IntN(500)), Decimal(rand.IntN(3))
+		levels.Set(price, quantity)
+		if quantity == 0 {
+			delete(want, price)
+		} else {
+			want[price] = quantity
+		}
+	}
+	if levels.Len() != len(want) {
+		t.Fatalf("Len() = %d, want %d", levels.Len(), len(want))
+	}
+	previous := Decimal(1 << 62)
+	levels.Each(func(level Level) bool {
+		if level.Price >= previous {
+			t.Fatalf("%s after %s", level.Price, previous)
+		}
+		if want[level.Price] != level.Quantity {
+			t.Fatalf("quantity at %s = %s, want %s", level.Price, level.Quantity, want[level.Price])
+		}
+		previous = level.Price
+		return true
+	})
+
+	clone := levels.Clone()
+	levels.Set(previous, 0)
+	if clone.Len() != len(want) || !reflect.DeepEqual(clone.Top(0)[:clone.Len()-1], levels.Top(0)) {
+		t.Error("clone does not match the original")
+	}
+}
+
+func TestBook(t *testing.T) {
+	book := NewBook()
+	if _, ok := book.Mid(); ok {
+		t.Error("Mid() of an empty book")
+	}
+	book.Bids.Set(MustDecimal("100"), MustDecimal("1"))
+	book.Bids.Set(MustDecimal("99.5"), MustDecimal("2.5"))
+	book.Asks.Set(MustDecimal("100.5"), MustDecimal("3"))
+
+	if spread, _ := book.Spread(); spread != MustDecimal("0.5") {
+		t.Errorf("Spread() = %s, want 0.5", spread)
+	}
+	if mid, _ := book.Mid(); mid != MustDecimal("100.25") {
+		t.Errorf("Mid() = %s, want 100.25", mid)
+	}
+	bids, asks := book.Depth(5)
+	want := []DepthLevel{
+		{Level{MustDecimal("100"), MustDecimal("1")}, MustDecimal("1")},
+		{Level{MustDecimal("99.5"), MustDecimal("2.5")}, MustDecimal("3.5")},
+	}
+	if !reflect.DeepEqual(bids, want) {
+		t.Errorf("bid depth = %v, want %v", bids, want)
+	}
+	if len(asks) != 1 || asks[0].Total != MustDecimal("3") {
+		t.Errorf("ask depth = %v", asks)
+	}
+}
+
+const benchLevels = 1000
+
+// mapSide is the previous map based side, kept to compare against.
+type mapSide map[float64]float64
+
+func (m mapSide) best() float64 {
+	best := 0.0
+	for price := range m {
+		if price > best {
+			best = price
+		}
+	}
+	return best
+}
+
+func benchUpdates(n int) ([]Decimal, []Decimal) {
+	prices, quantities := make([]Decimal, n), make([]Decimal, n)
+	for i := range n {
+		prices[i] = Decimal(rand.IntN(benchLevels)) * decimalScale / 100
+		quantities[i] = Decimal(rand.IntN(4)) * decimalScale
+	}
+	return prices, quantities
+}
+
+func BenchmarkLevelsUpdateBest(b *testing.B) {
+	prices, quantities := benchUpdates(4096)
+	levels := NewBids()
+	for i := range benchLevels {
+		levels.Set(Decimal(i)*decimalScale/100, decimalScale)
+	}
+	b.ResetTimer()
+	for i := range b.N {
+		levels.Set(prices[i%4096], quantities[i%4096])
+		levels.Best()
+	}
+}
+
+func BenchmarkMapUpdateBest(b *testing.B) {
+	prices, quantities := benchUpdates(4096)
+	side := make(mapSide)
+	for i := range benchLevels {
+		side[float64(i)/100] = 1
+	}
+	b.ResetTimer()
+	for i := range b.N {
+		price, quantity := prices[i%4096].Float64(), quantities[i%4096].Float64()
+		if quantity == 0 {
+			delete(side, price)
+		} else {
+			side[price] = quantity
+		}
+		side.best()
+	}
+}
+
+func BenchmarkLevelsTop20(b *testing.B) {
+	levels := NewAsks()
+	for i := range benchLevels {
+		levels.Set(Decimal(i)*decimalScale/100, decimalScale)
+	}
+	b.ResetTimer()
+	for range b.N {
+		levels.Cumulative(20)
+	}
+}
@@ -0,0 +1,124 @@
+package market
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Registry holds the providers of every venue together with the symbol
+// each venue lists a Decibel market under.
+type Registry struct {
+	mu     sync.RWMutex
+	venues map[string]venue
+}
+
+type venue struct {
+	provider Provider
+	// symbols maps Decibel market names to the venue's symbols.
+	symbols map[string]string
+}
+
+// Quote is the price of a Decibel market on one venue.
+type Quote struct {
+	Venue string `json:"venue"`
+	Price
+}
+
+func NewRegistry() *Registry {
+	return &Registry{venues: make(map[string]venue)}
+}
+
+// Register adds provider with the symbols it lists Decibel markets under,
+// keyed by market name (e.g. BTC/USD).
+func (r *Registry) Register(provider Provider, symbols map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.venues[provider.Name()]; ok {
+		return fmt.Errorf("market: provider %q already registered", provider.Name())
+	}
+	r.venues[provider.Name()] = venue{provider: provider, symbols: symbols}
+	return nil
+}
+
+// Provider returns the provider registered as name.
+func (r *Registry) Provider(name string) (Provider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v, ok := r.venues[name]
+	return v.provider, ok
+}
+
+// Providers returns every registered provider ordered by name.
+func (r *Registry) Providers() []Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	providers := make([]Provider, 0, len(r.venues))
+	for _, v := range r.venues {
+		providers = append(providers, v.provider)
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name() < providers[j].Name()
+	})
+	return providers
+}
+
+// Symbol returns the symbol venue lists market under.
+func (r *Registry) Symbol(venue, market string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	symbol, ok := r.venues[venue].symbols[market]
+	return symbol, ok
+}
+
+// Quotes returns the price of market on every venue that lists it and has
+// a price for it, ordered by venue.
+func (r *Registry) Quotes(market string) []Quote {
+	quotes := make([]Quote, 0)
+	for _, provider := range r.Providers() {
+		symbol, ok := r.Symbol(provider.Name(), market)
+		if !ok {
+			continue
+		}
+		if price, ok := provider.Price(symbol); ok {
+			quotes = append(quotes, Quote{Venue: provider.Name(), Price: price})
+		}
+	}
+	return quotes
+}
+
+// Start starts every provider that lists at least one market and subscribes
+// it to those markets.
+func (r *Registry) Start() error {
+	for _, provider := range r.Providers() {
+		r.mu.RLock()
+		symbols := make([]string, 0, len(r.venues[provider.Name()].symbols))
+		for _, symbol := range r.venues[provider.Name()].symbols {
+			symbols = append(symbols, symbol)
+		}
+		r.mu.RUnlock()
+		if len(symbols) == 0 {
+			continue
+		}
+		sort.Strings(symbols)
+		if err := provider.Start(); err != nil {
+			return fmt.Errorf("market: start %s: %w", provider.Name(), err)
+		}
+		if err := provider.SubscribeSymbols(symbols...); err != nil {
+			return fmt.Errorf("market: subscribe %s: %w", provider.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Stop stops every provider and returns their errors joined.
+func (r *Registry) Stop() error {
+	var errs []error
+	for _, provider := range r.Providers() {
+		if err := provider.Stop(); err != nil {
+			errs = append(errs, fmt.Errorf("market: stop %s: %w", provider.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
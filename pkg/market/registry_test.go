@@ -0,0 +1,77 @@
+package market
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// stubProvider is a provider with fixed prices.
+type stubProvider struct {
+	name       string
+	prices     map[string]Price
+	started    bool
+	subscribed []string
+	stopErr    error
+}
+
+func (p *stubProvider) Name() string { return p.name }
+func (p *stubProvider) Start() error {
+	p.started = true
+	return nil
+}
+func (p *stubProvider) Stop() error { return p.stopErr }
+func (p *stubProvider) SubscribeSymbols(symbols ...string) error {
+	p.subscribed = append(p.subscribed, symbols...)
+	return nil
+}
+func (p *stubProvider) Orderbook(string) (Book, bool) { return Book{}, false }
+func (p *stubProvider) Price(symbol string) (Price, bool) {
+	price, ok := p.prices[symbol]
+	return price, ok
+}
+func (p *stubProvider) OnTrade(func(Trade)) {}
+func (p *stubProvider) Health() Health    { return Health{} }
+
+func TestRegistry(t *testing.T) {
+	binance := &stubProvider{name: "binance", prices: map[string]Price{"BTCUSDT": {Symbol: "BTCUSDT", Mark: 100}}}
+	bybit := &stubProvider{name: "bybit", prices: map[string]Price{"BTCUSDT": {Symbol: "BTCUSDT", Mark: 101}}}
+	idle := &stubProvider{name: "okx", stopErr: errors.New("not started")}
+
+	registry := NewRegistry()
+	for provider, symbols := range map[*stubProvider]map[string]string{
+		binance: {"BTC/USD": "BTCUSDT", "ETH/USD": "ETHUSDT"},
+		bybit:   {"BTC/USD": "BTCUSDT"},
+		idle:    nil,
+	} {
+		if err := registry.Register(provider, symbols); err != nil {
+			t.Fatalf("failed to register %s: %v", provider.name, err)
+		}
+	}
+	if err := registry.Register(&stubProvider{name: "binance"}, nil); err == nil {
+		t.Error("registered binance twice")
+	}
+
+	if err := registry.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	if !reflect.DeepEqual(binance.subscribed, []string{"BTCUSDT", "ETHUSDT"}) {
+		t.Errorf("binance subscribed to %v", binance.subscribed)
+	}
+	if idle.started {
+		t.Error("started a provider without markets")
+	}
+
+	quotes := registry.Quotes("BTC/USD")
+	if len(quotes) != 2 || quotes[0].Venue != "binance" || quotes[1].Venue != "bybit" || quotes[1].Mark != 101 {
+		t.Errorf("Quotes(BTC/USD) = %+v", quotes)
+	}
+	// ETH/USD is listed on binance but has no price yet
+	if quotes := registry.Quotes("ETH/USD"); len(quotes) != 0 {
+		t.Errorf("Quotes(ETH/USD) = %+v, want none", quotes)
+	}
+
+	if err := registry.Stop(); err == nil {
+		t.Error("Stop() hid the error of okx")
+	}
+}
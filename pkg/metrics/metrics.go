@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "decidash"
+
+var (
+	// HTTPRequestDuration is the latency of api requests by route pattern,
+	// e.g. /api/v1/traders/:address.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	FeePayerSubmissions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "fee_payer",
+		Name:      "submissions_total",
+		Help:      "Sponsored transactions by result.",
+	}, []string{"result"})
+
+	FeePayerGasUsed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "fee_payer",
+		Name:      "gas_used_total",
+		Help:      "Gas units used by sponsored transactions.",
+	})
+
+	FeePayerGasSpent = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "fee_payer",
+		Name:      "gas_spent_octas_total",
+		Help:      "Octas paid for the gas of sponsored transactions.",
+	})
+
+	IndexerBatches = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "batches_total",
+		Help:      "Transaction batches handed to the processors.",
+	})
+
+	IndexerTransactions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "transactions_processed_total",
+		Help:      "Transactions handled by each processor.",
+	}, []string{"processor"})
+
+	IndexerProcessDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "process_duration_seconds",
+		Help:      "Time a processor took to handle a batch.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"processor"})
+
+	IndexerLagVersions = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "lag_versions",
+		Help:      "Versions between the chain head and a processor's checkpoint.",
+	}, []string{"processor"})
+
+	IndexerLagSeconds = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "lag_seconds",
+		Help:      "Chain time between the chain head and a processor's checkpoint.",
+	}, []string{"processor"})
+
+	FetchErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "fetcher",
+		Name:      "errors_total",
+		Help:      "Failed requests for transactions by source.",
+	}, []string{"source"})
+
+	ConsistencyErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "fetcher",
+		Name:      "consistency_errors_total",
+		Help:      "Pages of transactions refetched because of gaps, duplicates or accumulator mismatches.",
+	}, []string{"kind"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Latency of database writes and queries by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	WebsocketReconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "reconnects_total",
+		Help:      "Reconnections of upstream websocket feeds.",
+	}, []string{"feed"})
+
+	WebsocketMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "messages_total",
+		Help:      "Messages received from upstream websocket feeds.",
+	}, []string{"feed"})
+
+	OrderbookResyncs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "orderbook_resyncs_total",
+		Help:      "Local orderbooks reloaded from a snapshot after a gap in the depth stream.",
+	}, []string{"feed"})
+)
+
+// Handler serves the registered metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
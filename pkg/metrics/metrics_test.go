@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type instrumented struct {
+	ID int `gorm:"primaryKey"`
+}
+
+func (instrumented) TableName() string {
+	return "INSTRUMENTED"
+}
+
+func TestInstrumentDB(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := InstrumentDB(db); err != nil {
+		t.Fatalf("InstrumentDB: %v", err)
+	}
+
+	if err := db.Create(&instrumented{ID: 1}).Error; err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := db.Where("id = ?", 1).Delete(&instrumented{}).Error; err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if got := testutil.CollectAndCount(DBQueryDuration, "decidash_db_query_duration_seconds"); got != 2 {
+		t.Errorf("observed %d series, want 2", got)
+	}
+}
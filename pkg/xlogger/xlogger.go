@@ -87,6 +87,6 @@ func Build(ctx context.Context, handlers []slog.Handler, opts ...Option) *slog.L
 		handlers = append(handlers, sentryHandler)
 	}
 
-	logger := slog.New(slogmulti.Fanout(handlers...)).With("release", o.Release).With("namespace", o.Namespace)
+	logger := slog.New(traceHandler{slogmulti.Fanout(handlers...)}).With("release", o.Release).With("namespace", o.Namespace)
 	return logger
 }
@@ -1,6 +1,7 @@
 package xredis
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"testing"
@@ -22,7 +23,7 @@ func TestRedisPubSub(t *testing.T) {
 
 	go func() {
 		time.Sleep(1 * time.Second)
-		err = Publish(pool, "test", "test")
+		err = Publish(context.Background(), pool, "test", "test")
 		assert.NoError(t, err)
 	}()
 
@@ -46,7 +47,7 @@ func TestRedisPubSub_Subscribe(t *testing.T) {
 func TestRedisPubSub_Publish(t *testing.T) {
 	pool, err := NewRedisPool("localhost:6379", 10, 0, "")
 	assert.NoError(t, err)
-	err = Publish(pool, "test", "test")
+	err = Publish(context.Background(), pool, "test", "test")
 	assert.NoError(t, err)
 	log.Println("publish")
 }
@@ -1,7 +1,26 @@
 package xredis
 
-import radix "github.com/mediocregopher/radix/v3"
+import (
+	"context"
 
-func Publish(pool *radix.Pool, channel string, message string) error {
-	return pool.Do(radix.Cmd(nil, "PUBLISH", channel, message))
+	"github.com/cresendoo/decidash-backend/pkg/xtrace"
+	radix "github.com/mediocregopher/radix/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Publish publishes message on channel in a span of ctx.
+func Publish(ctx context.Context, pool *radix.Pool, channel string, message string) error {
+	return Do(ctx, pool, "PUBLISH", radix.Cmd(nil, "PUBLISH", channel, message))
+}
+
+// Do runs action on pool in a span named after command.
+func Do(ctx context.Context, pool *radix.Pool, command string, action radix.Action) error {
+	_, span := xtrace.Tracer("redis").Start(ctx, "redis."+command,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "redis")),
+	)
+	err := pool.Do(action)
+	xtrace.End(span, err)
+	return err
 }
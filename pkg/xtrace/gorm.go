@@ -0,0 +1,65 @@
+package xtrace
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "xtrace:span"
+
+// InstrumentDB starts a span for every statement run through db, as a child
+// of the span in the statement's context; use db.WithContext to link them.
+func InstrumentDB(db *gorm.DB) error {
+	tracer := Tracer("gorm")
+	before := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			_, span := tracer.Start(tx.Statement.Context, "db."+operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attribute.String("db.system", "postgresql")),
+			)
+			tx.InstanceSet(gormSpanKey, span)
+		}
+	}
+	after := func(tx *gorm.DB) {
+		v, ok := tx.InstanceGet(gormSpanKey)
+		if !ok {
+			return
+		}
+		span, ok := v.(trace.Span)
+		if !ok {
+			return
+		}
+		span.SetAttributes(
+			attribute.String("db.sql.table", tx.Statement.Table),
+			attribute.String("db.statement", tx.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+		)
+		err := tx.Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		}
+		End(span, err)
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("gorm:create").Register("xtrace:before_create", before("create")),
+		callbacks.Create().After("gorm:create").Register("xtrace:after_create", after),
+		callbacks.Query().Before("gorm:query").Register("xtrace:before_query", before("query")),
+		callbacks.Query().After("gorm:query").Register("xtrace:after_query", after),
+		callbacks.Update().Before("gorm:update").Register("xtrace:before_update", before("update")),
+		callbacks.Update().After("gorm:update").Register("xtrace:after_update", after),
+		callbacks.Delete().Before("gorm:delete").Register("xtrace:before_delete", before("delete")),
+		callbacks.Delete().After("gorm:delete").Register("xtrace:after_delete", after),
+		callbacks.Raw().Before("gorm:raw").Register("xtrace:before_raw", before("raw")),
+		callbacks.Raw().After("gorm:raw").Register("xtrace:after_raw", after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
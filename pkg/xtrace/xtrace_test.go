@@ -0,0 +1,67 @@
+package xtrace
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type traced struct {
+	ID int `gorm:"primaryKey"`
+}
+
+func (traced) TableName() string {
+	return "TRACED"
+}
+
+func TestInstrumentDB(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := InstrumentDB(db); err != nil {
+		t.Fatalf("InstrumentDB: %v", err)
+	}
+
+	ctx, parent := Tracer("test").Start(context.Background(), "request")
+	var rows []traced
+	if err := db.WithContext(ctx).Where("id = ?", 1).Find(&rows).Error; err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	query := spans[0]
+	if query.Name() != "db.query" {
+		t.Errorf("span name = %s, want db.query", query.Name())
+	}
+	if query.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("expected the query span to be a child of the request span")
+	}
+	var table string
+	for _, attr := range query.Attributes() {
+		if attr.Key == "db.sql.table" {
+			table = attr.Value.AsString()
+		}
+	}
+	if table != "TRACED" {
+		t.Errorf("table = %q, want TRACED", table)
+	}
+}